@@ -4,6 +4,7 @@ import (
 	"hacko-app/cmd"
 	"hacko-app/internal/adapter"
 	"hacko-app/internal/infrastructure/config"
+	integSandbox "hacko-app/internal/integration/sandbox"
 	"os"
 	"strings"
 
@@ -13,10 +14,16 @@ import (
 )
 
 func main() {
+	// the first process of a sandbox run must not load the configuration of the server
+	if len(os.Args) > 1 && os.Args[1] == integSandbox.InitCommand {
+		integSandbox.Init(os.Args[2:])
+	}
+
 	os.Args = initialize()
 
 	serverCmd := flag.NewFlagSet("server", flag.ExitOnError)
 	seedCmd := flag.NewFlagSet("seed", flag.ExitOnError)
+	cleanupStorageCmd := flag.NewFlagSet("cleanup-storage", flag.ExitOnError)
 	// wsCmd := flag.NewFlagSet("ws", flag.ExitOnError)
 
 	if len(os.Args) < 2 {
@@ -28,6 +35,8 @@ func main() {
 	switch os.Args[1] {
 	case "seed":
 		cmd.RunSeed(seedCmd, os.Args[2:])
+	case "cleanup-storage":
+		cmd.RunCleanupStorage(cleanupStorageCmd, os.Args[2:])
 	case "server":
 		cmd.RunServer(serverCmd, os.Args[2:])
 	default:
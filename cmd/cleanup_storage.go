@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/infrastructure/config"
+	integStorage "hacko-app/internal/integration/storage"
+	"hacko-app/internal/module/storage/entity"
+	"hacko-app/internal/module/storage/repository"
+	"hacko-app/internal/module/storage/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func RunCleanupStorage(cmd *flag.FlagSet, args []string) {
+	var (
+		dryRun = cmd.Bool("dry-run", true, "only report orphaned objects, -dry-run=false deletes them")
+		minAge = cmd.Duration("min-age", 24*time.Hour, "skip objects younger than this, they may belong to an upload in progress")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	opts := []adapter.Option{
+		adapter.WithHackoPostgres(),
+	}
+	if config.Envs.HackoStorage.Driver == integStorage.DriverS3 {
+		opts = append(opts, adapter.WithDigihubStorage())
+	}
+
+	adapter.Adapters.Sync(opts...)
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Fatal().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	storageService := service.NewStorageService(
+		repository.NewStorageRepository(adapter.Adapters.HackoPostgres),
+		integStorage.NewStorageIntegration(),
+	)
+
+	res, err := storageService.CleanupStorage(context.Background(), &entity.CleanupStorageRequest{
+		DryRun: *dryRun,
+		MinAge: *minAge,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error while cleaning up storage")
+	}
+
+	for _, key := range res.Keys {
+		log.Info().Str("key", key).Bool("dry_run", *dryRun).Msg("Orphaned object")
+	}
+
+	log.Info().
+		Bool("dry_run", *dryRun).
+		Int("scanned", res.Scanned).
+		Int("orphaned", res.Orphaned).
+		Int("deleted", res.Deleted).
+		Int64("deleted_bytes", res.DeletedBytes).
+		Int("failed", len(res.Failed)).
+		Msg("Storage cleanup finished")
+}
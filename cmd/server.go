@@ -5,7 +5,10 @@ import (
 	"hacko-app/internal/adapter"
 	"hacko-app/internal/infrastructure"
 	"hacko-app/internal/infrastructure/config"
+	integStorage "hacko-app/internal/integration/storage"
+	"hacko-app/internal/middleware"
 	"hacko-app/internal/route"
+	"hacko-app/pkg/eventbus"
 	"hacko-app/pkg/validator"
 	"os"
 	"os/signal"
@@ -43,7 +46,12 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 		SERVER_PORT = *flagAppPort
 	}
 
-	app := fiber.New()
+	// request bodies are streamed so uploads never sit in memory, BodyLimit bounds them instead of the server
+	app := fiber.New(fiber.Config{
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
+	app.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, integStorage.MaxUploadSize))
 
 	// Application Middlewares
 	if envs.App.Environtment == "production" {
@@ -62,14 +70,31 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 	
 	// End Application Middlewares
 
-	adapter.Adapters.Sync(
+	adapters := []adapter.Option{
 		adapter.WithRestServer(app),
 		adapter.WithHackoPostgres(),
 		adapter.WithValidator(validator.NewValidator()),
-	)
+	}
+
+	// files are kept on the local disk unless the s3 driver is selected
+	if envs.HackoStorage.Driver == "s3" {
+		adapters = append(adapters, adapter.WithDigihubStorage())
+	}
+
+	adapter.Adapters.Sync(adapters...)
 
 	infrastructure.InitializeLogger(envs.App.Environtment, envs.App.LogFile, logLevel)
 	app.Get("/metrics", monitor.New(monitor.Config{Title: config.Envs.App.Name + config.Envs.App.Environtment + " Metrics"}))
+	if envs.HackoStorage.Driver != "s3" {
+		app.Static("/api/storage/public", envs.App.LocalStoragePublicPath)
+	}
+	route.SetupEvents()
+	// runs once the server stopped taking requests and before the database is closed, the handlers and the
+	// background work of the modules still need it
+	app.Hooks().OnShutdown(func() error {
+		eventbus.Default.Wait()
+		return nil
+	})
 	route.SetupRoutes(app)
 
 	// print all routes that are registered
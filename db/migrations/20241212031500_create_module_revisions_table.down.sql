@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS module_revisions;
+
+ALTER TABLE modules DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS module_status;
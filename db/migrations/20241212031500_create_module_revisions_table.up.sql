@@ -0,0 +1,36 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'module_status') THEN
+        CREATE TYPE module_status AS ENUM ('draft', 'published');
+    END IF;
+END
+$$;
+
+-- Existing modules were visible to students, so they start as published
+ALTER TABLE modules ADD COLUMN IF NOT EXISTS status module_status NOT NULL DEFAULT 'published';
+
+CREATE TABLE IF NOT EXISTS module_revisions (
+    id SERIAL PRIMARY KEY,
+    module_id INT NOT NULL,
+    author_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    attachments TEXT[],
+    videos TEXT[],
+    status module_status NOT NULL, -- draft revisions are not applied to the module until published
+    source_revision_id INT, -- revision this one was restored or published from
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (module_id) REFERENCES modules(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_revision_id) REFERENCES module_revisions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_revisions_module_id ON module_revisions (module_id, id DESC);
+
+-- Keep the current content of existing modules as their first revision
+INSERT INTO module_revisions (module_id, author_id, title, content, attachments, videos, status, created_at)
+SELECT m.id, m.creator_modules_id, m.title, m.content, m.attachments, m.videos, 'published', m.updated_at
+FROM modules m
+WHERE NOT EXISTS (
+    SELECT 1 FROM module_revisions r WHERE r.module_id = m.id
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS module_attachments (
+    id SERIAL PRIMARY KEY,
+    module_id INT NOT NULL,
+    uploader_id UUID NOT NULL,
+    filename VARCHAR(255) NOT NULL, -- original name of the uploaded file
+    size BIGINT NOT NULL, -- size in bytes
+    mime_type VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL, -- object key in the storage bucket
+    url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (module_id) REFERENCES modules(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploader_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (storage_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_attachments_module_id ON module_attachments (module_id);
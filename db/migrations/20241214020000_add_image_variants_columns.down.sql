@@ -0,0 +1,2 @@
+ALTER TABLE class DROP COLUMN IF EXISTS image_variants;
+ALTER TABLE users DROP COLUMN IF EXISTS image_variants;
@@ -0,0 +1,3 @@
+-- resized copies of the uploaded image, {"small": {"key": "...", "url": "...", "width": 64, "height": 64}, ...}
+ALTER TABLE users ADD COLUMN IF NOT EXISTS image_variants JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE class ADD COLUMN IF NOT EXISTS image_variants JSONB NOT NULL DEFAULT '{}';
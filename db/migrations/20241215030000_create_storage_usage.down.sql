@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS storage_usage(UUID);
+DROP VIEW IF EXISTS storage_references;
+ALTER TABLE users DROP COLUMN IF EXISTS storage_quota_bytes;
@@ -0,0 +1,28 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS storage_quota_bytes BIGINT; -- NULL falls back to the configured default quota
+
+-- every object key a row still points to, with the user it is billed to
+CREATE OR REPLACE VIEW storage_references AS
+    SELECT
+        ma.storage_key,
+        ma.uploader_id AS owner_id,
+        ma.size,
+        'module_attachment' AS kind
+    FROM module_attachments ma
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        u.id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'avatar'
+    FROM users u, jsonb_each(u.image_variants) v
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        c.creator_class_id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'class_cover'
+    FROM class c, jsonb_each(c.image_variants) v;
+
+CREATE OR REPLACE FUNCTION storage_usage(p_user_id UUID) RETURNS BIGINT AS $$
+    SELECT COALESCE(SUM(size), 0)::BIGINT FROM storage_references WHERE owner_id = p_user_id;
+$$ LANGUAGE sql STABLE;
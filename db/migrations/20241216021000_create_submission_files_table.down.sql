@@ -0,0 +1,25 @@
+CREATE OR REPLACE VIEW storage_references AS
+    SELECT
+        ma.storage_key,
+        ma.uploader_id AS owner_id,
+        ma.size,
+        'module_attachment' AS kind
+    FROM module_attachments ma
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        u.id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'avatar'
+    FROM users u, jsonb_each(u.image_variants) v
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        c.creator_class_id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'class_cover'
+    FROM class c, jsonb_each(c.image_variants) v;
+
+DROP TABLE IF EXISTS submission_files;
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_files;
+ALTER TABLE assignments DROP COLUMN IF EXISTS allowed_file_types;
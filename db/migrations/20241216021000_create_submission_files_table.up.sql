@@ -0,0 +1,46 @@
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS allowed_file_types TEXT[] NOT NULL DEFAULT '{}'; -- empty allows every configured submission type
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_files INT NOT NULL DEFAULT 1 CHECK (max_files >= 0);
+
+CREATE TABLE IF NOT EXISTS submission_files (
+    id SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL,
+    filename VARCHAR(255) NOT NULL, -- original name of the uploaded file
+    size BIGINT NOT NULL, -- size in bytes
+    mime_type VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL, -- object key in the storage bucket
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    UNIQUE (storage_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_files_submission_id ON submission_files (submission_id);
+
+CREATE OR REPLACE VIEW storage_references AS
+    SELECT
+        ma.storage_key,
+        ma.uploader_id AS owner_id,
+        ma.size,
+        'module_attachment' AS kind
+    FROM module_attachments ma
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        u.id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'avatar'
+    FROM users u, jsonb_each(u.image_variants) v
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        c.creator_class_id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'class_cover'
+    FROM class c, jsonb_each(c.image_variants) v
+    UNION ALL
+    SELECT
+        sf.storage_key,
+        s.student_id,
+        sf.size,
+        'submission_file'
+    FROM submission_files sf
+    JOIN submissions s ON s.id = sf.submission_id;
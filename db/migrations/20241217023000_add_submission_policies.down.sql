@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS idx_submissions_active;
+DROP INDEX IF EXISTS idx_submissions_attempt;
+
+UPDATE submissions SET graded_at = submitted_at WHERE graded_at IS NULL;
+ALTER TABLE submissions ALTER COLUMN graded_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE submissions ALTER COLUMN graded_at SET NOT NULL;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS raw_grade;
+ALTER TABLE submissions DROP COLUMN IF EXISTS penalty_percent;
+ALTER TABLE submissions DROP COLUMN IF EXISTS late_days;
+ALTER TABLE submissions DROP COLUMN IF EXISTS is_active;
+ALTER TABLE submissions DROP COLUMN IF EXISTS attempt;
+
+ALTER TABLE assignments DROP CONSTRAINT IF EXISTS assignments_late_cutoff_check;
+ALTER TABLE assignments DROP COLUMN IF EXISTS resubmission_policy;
+ALTER TABLE assignments DROP COLUMN IF EXISTS late_cutoff;
+ALTER TABLE assignments DROP COLUMN IF EXISTS late_penalty_percent;
+ALTER TABLE assignments DROP COLUMN IF EXISTS allow_late;
+
+DROP TYPE IF EXISTS resubmission_policy;
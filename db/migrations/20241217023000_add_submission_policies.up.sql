@@ -0,0 +1,38 @@
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'resubmission_policy') THEN
+        CREATE TYPE resubmission_policy AS ENUM ('none', 'until_graded', 'until_deadline');
+    END IF;
+END
+$$;
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS allow_late BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_penalty_percent NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (late_penalty_percent BETWEEN 0 AND 100); -- deducted per started day after due_date
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_cutoff TIMESTAMP WITH TIME ZONE; -- no submissions at all after this, NULL means no hard cutoff
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS resubmission_policy resubmission_policy NOT NULL DEFAULT 'none';
+ALTER TABLE assignments ADD CONSTRAINT assignments_late_cutoff_check CHECK (late_cutoff IS NULL OR late_cutoff >= due_date);
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS attempt INT NOT NULL DEFAULT 1;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE; -- the attempt that gets graded
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS late_days INT NOT NULL DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS penalty_percent NUMERIC(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS raw_grade INT; -- grade before the late penalty
+ALTER TABLE submissions ALTER COLUMN graded_at DROP NOT NULL;
+ALTER TABLE submissions ALTER COLUMN graded_at DROP DEFAULT;
+
+-- earlier rows were inserted once per submit, number them and keep only the latest one active
+UPDATE submissions s
+SET attempt = n.attempt, is_active = n.attempt = n.total
+FROM (
+    SELECT
+        id,
+        ROW_NUMBER() OVER (PARTITION BY assignment_id, student_id ORDER BY submitted_at, id) AS attempt,
+        COUNT(*) OVER (PARTITION BY assignment_id, student_id) AS total
+    FROM submissions
+) n
+WHERE s.id = n.id;
+
+UPDATE submissions SET graded_at = NULL WHERE status = 'submitted';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_attempt ON submissions (assignment_id, student_id, attempt);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_active ON submissions (assignment_id, student_id) WHERE is_active;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS submission_rubric_scores;
+DROP TABLE IF EXISTS rubric_levels;
+DROP TABLE IF EXISTS rubric_criteria;
+
+ALTER TABLE submissions ALTER COLUMN raw_grade TYPE INT USING ROUND(raw_grade);
+ALTER TABLE submissions ALTER COLUMN grade TYPE INT USING ROUND(grade);
+
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_score;
@@ -0,0 +1,40 @@
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_score NUMERIC(7, 2) NOT NULL DEFAULT 100 CHECK (max_score > 0);
+
+ALTER TABLE submissions ALTER COLUMN grade TYPE NUMERIC(7, 2);
+ALTER TABLE submissions ALTER COLUMN raw_grade TYPE NUMERIC(7, 2);
+
+CREATE TABLE IF NOT EXISTS rubric_criteria (
+    id SERIAL PRIMARY KEY,
+    assignment_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_criteria_assignment_id ON rubric_criteria (assignment_id);
+
+CREATE TABLE IF NOT EXISTS rubric_levels (
+    id SERIAL PRIMARY KEY,
+    criterion_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    points NUMERIC(7, 2) NOT NULL CHECK (points >= 0),
+    position INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubric_levels_criterion_id ON rubric_levels (criterion_id);
+
+CREATE TABLE IF NOT EXISTS submission_rubric_scores (
+    submission_id INT NOT NULL,
+    criterion_id INT NOT NULL,
+    level_id INT NOT NULL,
+    points NUMERIC(7, 2) NOT NULL, -- copied from the level when graded
+    comment TEXT,
+    PRIMARY KEY (submission_id, criterion_id),
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    FOREIGN KEY (level_id) REFERENCES rubric_levels(id) ON DELETE CASCADE
+);
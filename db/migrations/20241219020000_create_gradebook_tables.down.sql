@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS grade_letters;
+
+ALTER TABLE quiz DROP COLUMN IF EXISTS grade_category_id;
+ALTER TABLE assignments DROP COLUMN IF EXISTS grade_category_id;
+
+DROP TABLE IF EXISTS grade_categories;
+
+ALTER TABLE users_completed_quiz DROP COLUMN IF EXISTS max_score;
+ALTER TABLE users_completed_quiz DROP COLUMN IF EXISTS score;
+ALTER TABLE users_completed_quiz DROP COLUMN IF EXISTS answers;
+
+ALTER TABLE questions_quiz DROP COLUMN IF EXISTS points;
+ALTER TABLE questions_quiz DROP COLUMN IF EXISTS correct_answer;
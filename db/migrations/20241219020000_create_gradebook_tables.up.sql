@@ -0,0 +1,30 @@
+-- quiz scoring, questions without a correct answer are not graded
+ALTER TABLE questions_quiz ADD COLUMN IF NOT EXISTS correct_answer JSONB;
+ALTER TABLE questions_quiz ADD COLUMN IF NOT EXISTS points NUMERIC(7, 2) NOT NULL DEFAULT 1 CHECK (points >= 0);
+
+ALTER TABLE users_completed_quiz ADD COLUMN IF NOT EXISTS answers JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE users_completed_quiz ADD COLUMN IF NOT EXISTS score NUMERIC(7, 2);
+ALTER TABLE users_completed_quiz ADD COLUMN IF NOT EXISTS max_score NUMERIC(7, 2);
+
+CREATE TABLE IF NOT EXISTS grade_categories (
+    id SERIAL PRIMARY KEY,
+    class_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    weight NUMERIC(5, 2) NOT NULL CHECK (weight BETWEEN 0 AND 100), -- share of the final grade in percent
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_grade_categories_class_id ON grade_categories (class_id);
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS grade_category_id INT REFERENCES grade_categories(id) ON DELETE SET NULL;
+ALTER TABLE quiz ADD COLUMN IF NOT EXISTS grade_category_id INT REFERENCES grade_categories(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS grade_letters (
+    class_id INT NOT NULL,
+    letter VARCHAR(5) NOT NULL,
+    min_percent NUMERIC(5, 2) NOT NULL CHECK (min_percent BETWEEN 0 AND 100),
+    PRIMARY KEY (class_id, letter),
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE
+);
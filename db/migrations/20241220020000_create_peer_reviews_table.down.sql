@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS peer_review_scores;
+DROP TABLE IF EXISTS peer_reviews;
+DROP TYPE IF EXISTS peer_review_status;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS peer_score;
+
+ALTER TABLE assignments DROP COLUMN IF EXISTS peer_reviews_assigned_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS peer_review_weight;
+ALTER TABLE assignments DROP COLUMN IF EXISTS peer_review_count;
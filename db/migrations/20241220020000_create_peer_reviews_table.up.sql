@@ -0,0 +1,41 @@
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS peer_review_count INT NOT NULL DEFAULT 0 CHECK (peer_review_count >= 0); -- reviewers per submission, 0 disables peer review
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS peer_review_weight NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (peer_review_weight BETWEEN 0 AND 100); -- share of the grade in percent
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS peer_reviews_assigned_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS peer_score NUMERIC(7, 2); -- average of the completed peer reviews when graded
+
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'peer_review_status') THEN
+        CREATE TYPE peer_review_status AS ENUM ('pending', 'completed');
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS peer_reviews (
+    id SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL,
+    reviewer_id UUID NOT NULL,
+    status peer_review_status NOT NULL DEFAULT 'pending',
+    total NUMERIC(7, 2),
+    feedback TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (submission_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_peer_reviews_reviewer_id ON peer_reviews (reviewer_id);
+
+CREATE TABLE IF NOT EXISTS peer_review_scores (
+    peer_review_id INT NOT NULL,
+    criterion_id INT NOT NULL,
+    level_id INT NOT NULL,
+    points NUMERIC(7, 2) NOT NULL,
+    comment TEXT,
+    PRIMARY KEY (peer_review_id, criterion_id),
+    FOREIGN KEY (peer_review_id) REFERENCES peer_reviews(id) ON DELETE CASCADE,
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    FOREIGN KEY (level_id) REFERENCES rubric_levels(id) ON DELETE CASCADE
+);
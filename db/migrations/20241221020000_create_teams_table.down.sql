@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS submission_members;
+
+DROP INDEX IF EXISTS idx_submissions_team_active;
+DROP INDEX IF EXISTS idx_submissions_team_attempt;
+ALTER TABLE submissions DROP COLUMN IF EXISTS team_id;
+
+ALTER TABLE assignments DROP CONSTRAINT IF EXISTS assignments_group_peer_review_check;
+ALTER TABLE assignments DROP COLUMN IF EXISTS is_group;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS team_settings;
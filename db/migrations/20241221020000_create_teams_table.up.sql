@@ -0,0 +1,53 @@
+-- with self_select students create and join teams themselves, otherwise only the class creator forms them
+CREATE TABLE IF NOT EXISTS team_settings (
+    class_id INT PRIMARY KEY,
+    self_select BOOLEAN NOT NULL DEFAULT FALSE,
+    max_team_size INT CHECK (max_team_size > 0), -- NULL means no limit
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id SERIAL PRIMARY KEY,
+    class_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (class_id, name)
+);
+
+-- class_id is repeated here so a student can be in only one team per class
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INT NOT NULL,
+    class_id INT NOT NULL,
+    user_id UUID NOT NULL,
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (class_id, user_id)
+);
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS is_group BOOLEAN NOT NULL DEFAULT FALSE; -- one submission per team
+ALTER TABLE assignments ADD CONSTRAINT assignments_group_peer_review_check CHECK (NOT is_group OR peer_review_count = 0);
+
+-- student_id stays the member who submitted, the team members at submit time get the grade
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS team_id INT REFERENCES teams(id) ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_team_attempt ON submissions (assignment_id, team_id, attempt) WHERE team_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_team_active ON submissions (assignment_id, team_id) WHERE is_active AND team_id IS NOT NULL;
+
+-- grade and feedback override the team grade for a single member, NULL keeps the team grade
+CREATE TABLE IF NOT EXISTS submission_members (
+    submission_id INT NOT NULL,
+    student_id UUID NOT NULL,
+    grade NUMERIC(7, 2),
+    feedback TEXT,
+    PRIMARY KEY (submission_id, student_id),
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (student_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_members_student_id ON submission_members (student_id);
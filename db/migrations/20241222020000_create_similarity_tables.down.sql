@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS similarity_matches;
+DROP TABLE IF EXISTS submission_fingerprints;
+DROP TABLE IF EXISTS submission_documents;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS answer;
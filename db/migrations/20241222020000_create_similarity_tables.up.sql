@@ -0,0 +1,44 @@
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS answer TEXT; -- written answer submitted next to the link and files
+
+-- text of a submission as it was compared, the answer followed by its plain text files
+CREATE TABLE IF NOT EXISTS submission_documents (
+    submission_id INT PRIMARY KEY,
+    assignment_id INT NOT NULL,
+    content TEXT NOT NULL,
+    fingerprint_count INT NOT NULL DEFAULT 0, -- distinct hashes, the denominator of the similarity score
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE
+);
+
+-- winnowing fingerprints, start_pos and end_pos are byte offsets into the document content
+CREATE TABLE IF NOT EXISTS submission_fingerprints (
+    submission_id INT NOT NULL,
+    assignment_id INT NOT NULL,
+    hash BIGINT NOT NULL,
+    start_pos INT NOT NULL,
+    end_pos INT NOT NULL,
+    FOREIGN KEY (submission_id) REFERENCES submission_documents(submission_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_submission_id ON submission_fingerprints (submission_id);
+CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_assignment_hash ON submission_fingerprints (assignment_id, hash);
+
+-- one row per pair of submissions, submission_a is always the lower id
+CREATE TABLE IF NOT EXISTS similarity_matches (
+    submission_a INT NOT NULL,
+    submission_b INT NOT NULL,
+    assignment_id INT NOT NULL,
+    score NUMERIC(5, 4) NOT NULL, -- shared fingerprints over all fingerprints of both submissions
+    shared_fingerprints INT NOT NULL,
+    spans JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (submission_a, submission_b),
+    FOREIGN KEY (submission_a) REFERENCES submission_documents(submission_id) ON DELETE CASCADE,
+    FOREIGN KEY (submission_b) REFERENCES submission_documents(submission_id) ON DELETE CASCADE,
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE,
+    CHECK (submission_a < submission_b)
+);
+
+CREATE INDEX IF NOT EXISTS idx_similarity_matches_assignment_score ON similarity_matches (assignment_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_similarity_matches_submission_b ON similarity_matches (submission_b);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS submission_test_results;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS autograded_at;
+ALTER TABLE submissions DROP COLUMN IF EXISTS autograde_output;
+ALTER TABLE submissions DROP COLUMN IF EXISTS autograde_score;
+ALTER TABLE submissions DROP COLUMN IF EXISTS autograde_status;
+
+DROP TABLE IF EXISTS assignment_test_cases;
+
+ALTER TABLE assignments DROP COLUMN IF EXISTS autograde_memory_mb;
+ALTER TABLE assignments DROP COLUMN IF EXISTS autograde_time_limit_ms;
+ALTER TABLE assignments DROP COLUMN IF EXISTS autograde_language;
+
+DROP TYPE IF EXISTS autograde_status;
+DROP TYPE IF EXISTS autograde_language;
@@ -0,0 +1,48 @@
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'autograde_language') THEN
+        CREATE TYPE autograde_language AS ENUM ('go', 'python');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'autograde_status') THEN
+        CREATE TYPE autograde_status AS ENUM ('pending', 'completed', 'failed');
+    END IF;
+END
+$$;
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS autograde_language autograde_language; -- NULL disables automated grading
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS autograde_time_limit_ms INT NOT NULL DEFAULT 2000 CHECK (autograde_time_limit_ms BETWEEN 100 AND 30000); -- per test run
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS autograde_memory_mb INT NOT NULL DEFAULT 256 CHECK (autograde_memory_mb BETWEEN 16 AND 2048);
+
+CREATE TABLE IF NOT EXISTS assignment_test_cases (
+    id SERIAL PRIMARY KEY,
+    assignment_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    input TEXT NOT NULL DEFAULT '', -- given on stdin
+    expected_output TEXT NOT NULL, -- compared with stdout, trailing whitespace ignored
+    points NUMERIC(7, 2) NOT NULL CHECK (points > 0),
+    is_hidden BOOLEAN NOT NULL DEFAULT TRUE, -- students only see whether a hidden test passed
+    position INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_test_cases_assignment_id ON assignment_test_cases (assignment_id);
+
+-- failed means the code could not be graded at all, a compile error is a completed run with zero points
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS autograde_status autograde_status;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS autograde_score NUMERIC(7, 2);
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS autograde_output TEXT; -- compiler output or why grading failed
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS autograded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS submission_test_results (
+    submission_id INT NOT NULL,
+    test_case_id INT NOT NULL,
+    passed BOOLEAN NOT NULL,
+    points NUMERIC(7, 2) NOT NULL,
+    output TEXT NOT NULL DEFAULT '', -- stdout, then stderr
+    exit_code INT NOT NULL,
+    timed_out BOOLEAN NOT NULL DEFAULT FALSE,
+    duration_ms INT NOT NULL,
+    PRIMARY KEY (submission_id, test_case_id),
+    FOREIGN KEY (submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+    FOREIGN KEY (test_case_id) REFERENCES assignment_test_cases(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS assignment_deadline(INT, UUID);
+DROP TABLE IF EXISTS assignment_extension_audit;
+DROP TABLE IF EXISTS assignment_extensions;
@@ -0,0 +1,65 @@
+-- an extension moves the deadline of one student or one team, a student's own extension wins over their team's
+CREATE TABLE IF NOT EXISTS assignment_extensions (
+    id SERIAL PRIMARY KEY,
+    assignment_id INT NOT NULL,
+    student_id UUID,
+    team_id INT,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    late_cutoff TIMESTAMP WITH TIME ZONE, -- NULL shifts the assignment's cutoff by the extension
+    reason TEXT NOT NULL,
+    granted_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE,
+    FOREIGN KEY (student_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_by) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK ((student_id IS NULL) <> (team_id IS NULL)),
+    CHECK (late_cutoff IS NULL OR late_cutoff >= due_date)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_extensions_student ON assignment_extensions (assignment_id, student_id) WHERE student_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_extensions_team ON assignment_extensions (assignment_id, team_id) WHERE team_id IS NOT NULL;
+
+-- every grant, change and revocation, kept when the extension itself is gone. the audit outlives the assignment,
+-- the student, the team and the actor too: the assignment is not a foreign key, the others are cleared on delete
+-- and their names are copied into the row when it is written
+CREATE TABLE IF NOT EXISTS assignment_extension_audit (
+    id SERIAL PRIMARY KEY,
+    assignment_id INT NOT NULL,
+    student_id UUID,
+    student_name VARCHAR(255),
+    team_id INT,
+    team_name VARCHAR(255),
+    action VARCHAR(16) NOT NULL CHECK (action IN ('granted', 'updated', 'revoked')),
+    due_date TIMESTAMP WITH TIME ZONE, -- NULL once revoked
+    late_cutoff TIMESTAMP WITH TIME ZONE,
+    reason TEXT NOT NULL,
+    actor_id UUID,
+    actor_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (student_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_extension_audit_assignment_id ON assignment_extension_audit (assignment_id, created_at);
+
+-- the deadline that applies to a student, the assignment's own unless they or their team got an extension
+CREATE OR REPLACE FUNCTION assignment_deadline(p_assignment_id INT, p_student_id UUID)
+RETURNS TABLE (due_date TIMESTAMP WITH TIME ZONE, late_cutoff TIMESTAMP WITH TIME ZONE, extended BOOLEAN) AS $$
+    SELECT
+        COALESCE(e.due_date, a.due_date),
+        CASE WHEN e.id IS NULL THEN a.late_cutoff ELSE COALESCE(e.late_cutoff, a.late_cutoff + (e.due_date - a.due_date)) END,
+        e.id IS NOT NULL
+    FROM assignments a
+    LEFT JOIN LATERAL (
+        SELECT x.id, x.due_date, x.late_cutoff
+        FROM assignment_extensions x
+        WHERE x.assignment_id = a.id
+            AND (x.student_id = p_student_id OR x.team_id IN (SELECT tm.team_id FROM team_members tm WHERE tm.user_id = p_student_id))
+        ORDER BY x.student_id IS NULL
+        LIMIT 1
+    ) e ON TRUE
+    WHERE a.id = p_assignment_id;
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,31 @@
+DROP TRIGGER IF EXISTS materials_class_progress ON materials;
+DROP TRIGGER IF EXISTS modules_class_progress ON modules;
+DROP FUNCTION IF EXISTS materials_refresh_class_progress();
+DROP FUNCTION IF EXISTS modules_refresh_class_progress();
+DROP FUNCTION IF EXISTS refresh_class_progress(INT, UUID);
+DROP TABLE IF EXISTS class_progress;
+
+DROP INDEX IF EXISTS idx_users_progress_class_id;
+ALTER TABLE users_progress DROP CONSTRAINT IF EXISTS users_progress_user_module_key;
+ALTER TABLE users_progress DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE users_progress DROP COLUMN IF EXISTS started_at;
+ALTER TABLE users_progress ADD COLUMN IF NOT EXISTS progress DECIMAL;
+ALTER TABLE users_progress ADD COLUMN IF NOT EXISTS quiz_id INT REFERENCES quiz(id) ON DELETE CASCADE;
+ALTER TABLE users_progress ADD COLUMN IF NOT EXISTS users_classes_id INT REFERENCES users_classes(id) ON DELETE CASCADE; -- the merged rows have none
+
+-- the merged rows stay in users_progress
+CREATE TABLE IF NOT EXISTS user_progress (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    class_id INT NOT NULL,
+    materials_id INT NOT NULL,
+    module_id INT NOT NULL,
+    progress DECIMAL,
+    status progress_status[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE,
+    FOREIGN KEY (materials_id) REFERENCES materials(id) ON DELETE CASCADE,
+    FOREIGN KEY (module_id) REFERENCES modules(id) ON DELETE CASCADE
+);
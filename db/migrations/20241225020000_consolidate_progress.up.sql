@@ -0,0 +1,118 @@
+-- users_progress becomes the only per-module progress table, one row per user and module
+ALTER TABLE users_progress DROP COLUMN IF EXISTS users_classes_id;
+ALTER TABLE users_progress DROP COLUMN IF EXISTS quiz_id;
+ALTER TABLE users_progress DROP COLUMN IF EXISTS progress; -- class progress lives in class_progress
+ALTER TABLE users_progress ADD COLUMN IF NOT EXISTS started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL;
+ALTER TABLE users_progress ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- the older user_progress table kept a status array, its furthest status is the one that counts
+INSERT INTO users_progress (user_id, class_id, material_id, module_id, status, created_at, updated_at)
+SELECT
+    user_id, class_id, materials_id, module_id,
+    CASE
+        WHEN 'done' = ANY(status) THEN 'done'
+        WHEN 'on_progress' = ANY(status) THEN 'on_progress'
+        ELSE 'not_started'
+    END::progress_status,
+    created_at, updated_at
+FROM user_progress;
+
+DROP TABLE IF EXISTS user_progress;
+
+-- tracking inserted a row per call, keep the furthest one with the first start and completion times
+UPDATE users_progress up
+SET
+    started_at = f.started_at,
+    completed_at = CASE WHEN up.status = 'done' THEN f.completed_at END
+FROM (
+    SELECT user_id, module_id, MIN(created_at) AS started_at, MIN(updated_at) FILTER (WHERE status = 'done') AS completed_at
+    FROM users_progress
+    GROUP BY user_id, module_id
+) f
+WHERE f.user_id = up.user_id AND f.module_id = up.module_id;
+
+DELETE FROM users_progress up
+USING users_progress keep
+WHERE keep.user_id = up.user_id AND keep.module_id = up.module_id
+    AND (keep.status > up.status OR (keep.status = up.status AND keep.id < up.id));
+
+ALTER TABLE users_progress ADD CONSTRAINT users_progress_user_module_key UNIQUE (user_id, module_id);
+
+CREATE INDEX IF NOT EXISTS idx_users_progress_class_id ON users_progress (class_id, user_id);
+
+-- cached share of the published modules of a class a user has done
+CREATE TABLE IF NOT EXISTS class_progress (
+    user_id UUID NOT NULL,
+    class_id INT NOT NULL,
+    completed_modules INT NOT NULL DEFAULT 0,
+    total_modules INT NOT NULL DEFAULT 0,
+    progress NUMERIC(5, 2) NOT NULL DEFAULT 0, -- percent
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, class_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE
+);
+
+-- recomputes the cache of one user, or of every user with progress in the class when p_user_id is NULL. The rows
+-- are locked by the first statement, so concurrent refreshes of a user wait and then count each other's modules.
+CREATE OR REPLACE FUNCTION refresh_class_progress(p_class_id INT, p_user_id UUID) RETURNS VOID AS $$
+    INSERT INTO class_progress (user_id, class_id)
+    SELECT DISTINCT up.user_id, up.class_id
+    FROM users_progress up
+    JOIN class c ON c.id = up.class_id -- gone while a deleted class cascades
+    WHERE up.class_id = p_class_id AND (p_user_id IS NULL OR up.user_id = p_user_id)
+    ORDER BY up.user_id
+    ON CONFLICT (user_id, class_id) DO UPDATE SET updated_at = class_progress.updated_at;
+
+    UPDATE class_progress cp
+    SET
+        completed_modules = d.completed,
+        total_modules = t.total,
+        progress = CASE WHEN t.total > 0 THEN ROUND(d.completed * 100.0 / t.total, 2) ELSE 0 END,
+        updated_at = NOW()
+    FROM (
+        SELECT COUNT(*)::INT AS total
+        FROM modules m
+        JOIN materials mat ON mat.id = m.materials_id
+        WHERE mat.class_id = p_class_id AND m.status = 'published'
+    ) t, (
+        SELECT c.user_id, COUNT(m.id)::INT AS completed
+        FROM class_progress c
+        LEFT JOIN users_progress up ON up.user_id = c.user_id AND up.class_id = c.class_id AND up.status = 'done'
+        LEFT JOIN modules m ON m.id = up.module_id AND m.status = 'published'
+        WHERE c.class_id = p_class_id AND (p_user_id IS NULL OR c.user_id = p_user_id)
+        GROUP BY c.user_id
+    ) d
+    WHERE cp.class_id = p_class_id AND cp.user_id = d.user_id;
+$$ LANGUAGE sql VOLATILE;
+
+-- modules that are added, removed or (un)published change the total of every user in the class
+CREATE OR REPLACE FUNCTION modules_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_class_progress(mat.class_id, NULL) FROM materials mat WHERE mat.id = OLD.materials_id;
+    END IF;
+    IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.materials_id <> OLD.materials_id) THEN
+        PERFORM refresh_class_progress(mat.class_id, NULL) FROM materials mat WHERE mat.id = NEW.materials_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER modules_class_progress
+AFTER INSERT OR DELETE OR UPDATE OF status, materials_id ON modules
+FOR EACH ROW EXECUTE FUNCTION modules_refresh_class_progress();
+
+-- the modules of a deleted material are gone by the time this runs, its class is only known from the material
+CREATE OR REPLACE FUNCTION materials_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_class_progress(OLD.class_id, NULL);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER materials_class_progress
+AFTER DELETE ON materials
+FOR EACH ROW EXECUTE FUNCTION materials_refresh_class_progress();
+
+SELECT refresh_class_progress(c.id, NULL) FROM class c;
@@ -0,0 +1,49 @@
+DROP TRIGGER IF EXISTS submission_members_class_progress ON submission_members;
+DROP TRIGGER IF EXISTS submissions_class_progress ON submissions;
+DROP TRIGGER IF EXISTS assignments_class_progress ON assignments;
+DROP TRIGGER IF EXISTS users_completed_quiz_class_progress ON users_completed_quiz;
+DROP TRIGGER IF EXISTS quiz_class_progress ON quiz;
+DROP FUNCTION IF EXISTS submission_members_refresh_class_progress();
+DROP FUNCTION IF EXISTS submissions_refresh_class_progress();
+DROP FUNCTION IF EXISTS assignments_refresh_class_progress();
+DROP FUNCTION IF EXISTS users_completed_quiz_refresh_class_progress();
+DROP FUNCTION IF EXISTS quiz_refresh_class_progress();
+
+ALTER TABLE class_progress RENAME COLUMN completed_items TO completed_modules;
+ALTER TABLE class_progress RENAME COLUMN total_items TO total_modules;
+
+CREATE OR REPLACE FUNCTION refresh_class_progress(p_class_id INT, p_user_id UUID) RETURNS VOID AS $$
+    INSERT INTO class_progress (user_id, class_id)
+    SELECT DISTINCT up.user_id, up.class_id
+    FROM users_progress up
+    JOIN class c ON c.id = up.class_id
+    WHERE up.class_id = p_class_id AND (p_user_id IS NULL OR up.user_id = p_user_id)
+    ORDER BY up.user_id
+    ON CONFLICT (user_id, class_id) DO UPDATE SET updated_at = class_progress.updated_at;
+
+    UPDATE class_progress cp
+    SET
+        completed_modules = d.completed,
+        total_modules = t.total,
+        progress = CASE WHEN t.total > 0 THEN ROUND(d.completed * 100.0 / t.total, 2) ELSE 0 END,
+        updated_at = NOW()
+    FROM (
+        SELECT COUNT(*)::INT AS total
+        FROM modules m
+        JOIN materials mat ON mat.id = m.materials_id
+        WHERE mat.class_id = p_class_id AND m.status = 'published'
+    ) t, (
+        SELECT c.user_id, COUNT(m.id)::INT AS completed
+        FROM class_progress c
+        LEFT JOIN users_progress up ON up.user_id = c.user_id AND up.class_id = c.class_id AND up.status = 'done'
+        LEFT JOIN modules m ON m.id = up.module_id AND m.status = 'published'
+        WHERE c.class_id = p_class_id AND (p_user_id IS NULL OR c.user_id = p_user_id)
+        GROUP BY c.user_id
+    ) d
+    WHERE cp.class_id = p_class_id AND cp.user_id = d.user_id;
+$$ LANGUAGE sql VOLATILE;
+
+DROP FUNCTION IF EXISTS class_completion_items(INT, UUID);
+DROP TABLE IF EXISTS class_completion_rules;
+
+SELECT refresh_class_progress(c.id, NULL) FROM class c;
@@ -0,0 +1,194 @@
+-- what completes a class, a class without rules counts its published modules only
+CREATE TABLE IF NOT EXISTS class_completion_rules (
+    class_id INT PRIMARY KEY,
+    count_modules BOOLEAN NOT NULL DEFAULT TRUE,
+    count_quizzes BOOLEAN NOT NULL DEFAULT FALSE,
+    count_assignments BOOLEAN NOT NULL DEFAULT FALSE,
+    min_quiz_score NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (min_quiz_score BETWEEN 0 AND 100), -- percent of the quiz max score
+    require_graded_assignments BOOLEAN NOT NULL DEFAULT FALSE, -- otherwise submitting is enough
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CHECK (count_modules OR count_quizzes OR count_assignments),
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE,
+    FOREIGN KEY (updated_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+ALTER TABLE class_progress RENAME COLUMN completed_modules TO completed_items;
+ALTER TABLE class_progress RENAME COLUMN total_modules TO total_items;
+
+-- every item that counts towards the class under its rules and whether the user completed it: published modules
+-- that are done, public quizzes whose best score reaches the minimum and assignments with an active submission of
+-- the user or their team, graded when the rules require it
+CREATE OR REPLACE FUNCTION class_completion_items(p_class_id INT, p_user_id UUID)
+RETURNS TABLE (item_type TEXT, item_id INT, title TEXT, completed BOOLEAN, completed_at TIMESTAMP WITH TIME ZONE, score NUMERIC, max_score NUMERIC) AS $$
+    WITH rules AS (
+        SELECT
+            COALESCE(r.count_modules, TRUE) AS count_modules,
+            COALESCE(r.count_quizzes, FALSE) AS count_quizzes,
+            COALESCE(r.count_assignments, FALSE) AS count_assignments,
+            COALESCE(r.min_quiz_score, 0) AS min_quiz_score,
+            COALESCE(r.require_graded_assignments, FALSE) AS require_graded_assignments
+        FROM (SELECT p_class_id AS class_id) c
+        LEFT JOIN class_completion_rules r ON r.class_id = c.class_id
+    )
+    SELECT 'module', m.id, m.title, COALESCE(up.status = 'done', FALSE), up.completed_at, NULL::NUMERIC, NULL::NUMERIC
+    FROM rules, modules m
+    JOIN materials mat ON mat.id = m.materials_id
+    LEFT JOIN users_progress up ON up.module_id = m.id AND up.user_id = p_user_id
+    WHERE rules.count_modules AND mat.class_id = p_class_id AND m.status = 'published'
+
+    UNION ALL
+
+    SELECT
+        'quiz', q.id, q.title,
+        uq.id IS NOT NULL AND (COALESCE(uq.max_score, 0) = 0 OR uq.score * 100 / uq.max_score >= rules.min_quiz_score),
+        uq.created_at, uq.score, uq.max_score
+    FROM rules, quiz q
+    LEFT JOIN LATERAL (
+        SELECT ucq.id, ucq.score, ucq.max_score, ucq.created_at
+        FROM users_completed_quiz ucq
+        WHERE ucq.quiz_id = q.id AND ucq.user_id = p_user_id
+        ORDER BY COALESCE(ucq.score / NULLIF(ucq.max_score, 0), 1) DESC, ucq.created_at
+        LIMIT 1
+    ) uq ON TRUE
+    WHERE rules.count_quizzes AND q.class_id = p_class_id AND q.status = 'public'
+
+    UNION ALL
+
+    SELECT
+        'assignment', a.id, a.title,
+        s.id IS NOT NULL AND (NOT rules.require_graded_assignments OR s.status = 'rated'),
+        CASE WHEN rules.require_graded_assignments THEN s.graded_at ELSE s.submitted_at END,
+        s.grade, a.max_score
+    FROM rules, assignments a
+    LEFT JOIN LATERAL (
+        SELECT sub.id, sub.status, COALESCE(sm.grade, sub.grade) AS grade, sub.submitted_at, sub.graded_at
+        FROM submissions sub
+        LEFT JOIN submission_members sm ON sm.submission_id = sub.id AND sm.student_id = p_user_id
+        WHERE sub.assignment_id = a.id AND sub.is_active AND (sub.student_id = p_user_id OR sm.student_id IS NOT NULL)
+        LIMIT 1
+    ) s ON TRUE
+    WHERE rules.count_assignments AND a.class_id = p_class_id;
+$$ LANGUAGE sql STABLE;
+
+-- same locking as before, the cache now covers every enrolled user since quizzes and assignments count without
+-- any module progress
+CREATE OR REPLACE FUNCTION refresh_class_progress(p_class_id INT, p_user_id UUID) RETURNS VOID AS $$
+    INSERT INTO class_progress (user_id, class_id)
+    SELECT u.user_id, p_class_id
+    FROM (
+        SELECT uc.user_id FROM users_classes uc WHERE uc.class_id = p_class_id
+        UNION
+        SELECT up.user_id FROM users_progress up WHERE up.class_id = p_class_id
+    ) u
+    JOIN class c ON c.id = p_class_id -- gone while a deleted class cascades
+    WHERE p_user_id IS NULL OR u.user_id = p_user_id
+    ORDER BY u.user_id
+    ON CONFLICT (user_id, class_id) DO UPDATE SET updated_at = class_progress.updated_at;
+
+    UPDATE class_progress cp
+    SET
+        completed_items = d.completed,
+        total_items = d.total,
+        progress = CASE WHEN d.total > 0 THEN ROUND(d.completed * 100.0 / d.total, 2) ELSE 0 END,
+        updated_at = NOW()
+    FROM (
+        SELECT c.user_id, (COUNT(i.item_id) FILTER (WHERE i.completed))::INT AS completed, COUNT(i.item_id)::INT AS total
+        FROM class_progress c
+        LEFT JOIN LATERAL class_completion_items(c.class_id, c.user_id) i ON TRUE
+        WHERE c.class_id = p_class_id AND (p_user_id IS NULL OR c.user_id = p_user_id)
+        GROUP BY c.user_id
+    ) d
+    WHERE cp.class_id = p_class_id AND cp.user_id = d.user_id;
+$$ LANGUAGE sql VOLATILE;
+
+-- quizzes that are added, removed or (un)published change the total of every user in the class
+CREATE OR REPLACE FUNCTION quiz_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_class_progress(OLD.class_id, NULL);
+    END IF;
+    IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.class_id <> OLD.class_id) THEN
+        PERFORM refresh_class_progress(NEW.class_id, NULL);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER quiz_class_progress
+AFTER INSERT OR DELETE OR UPDATE OF status, class_id ON quiz
+FOR EACH ROW EXECUTE FUNCTION quiz_refresh_class_progress();
+
+CREATE OR REPLACE FUNCTION users_completed_quiz_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_class_progress(q.class_id, OLD.user_id) FROM quiz q WHERE q.id = OLD.quiz_id;
+    ELSE
+        PERFORM refresh_class_progress(q.class_id, NEW.user_id) FROM quiz q WHERE q.id = NEW.quiz_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_completed_quiz_class_progress
+AFTER INSERT OR DELETE OR UPDATE OF score, max_score ON users_completed_quiz
+FOR EACH ROW EXECUTE FUNCTION users_completed_quiz_refresh_class_progress();
+
+CREATE OR REPLACE FUNCTION assignments_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_class_progress(OLD.class_id, NULL);
+    ELSE
+        PERFORM refresh_class_progress(NEW.class_id, NULL);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER assignments_class_progress
+AFTER INSERT OR DELETE ON assignments
+FOR EACH ROW EXECUTE FUNCTION assignments_refresh_class_progress();
+
+-- a submission completes the assignment for its student and every team member, grading it may complete it too
+CREATE OR REPLACE FUNCTION submissions_refresh_class_progress() RETURNS TRIGGER AS $$
+DECLARE
+    s submissions%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        s := OLD;
+    ELSE
+        s := NEW;
+    END IF;
+
+    PERFORM refresh_class_progress(a.class_id, u.user_id)
+    FROM assignments a, (
+        SELECT s.student_id AS user_id
+        UNION
+        SELECT sm.student_id FROM submission_members sm WHERE sm.submission_id = s.id
+    ) u
+    WHERE a.id = s.assignment_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER submissions_class_progress
+AFTER INSERT OR DELETE OR UPDATE OF status, is_active ON submissions
+FOR EACH ROW EXECUTE FUNCTION submissions_refresh_class_progress();
+
+-- members are added after their submission is inserted
+CREATE OR REPLACE FUNCTION submission_members_refresh_class_progress() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_class_progress(a.class_id, NEW.student_id)
+    FROM submissions s
+    JOIN assignments a ON a.id = s.assignment_id
+    WHERE s.id = NEW.submission_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER submission_members_class_progress
+AFTER INSERT ON submission_members
+FOR EACH ROW EXECUTE FUNCTION submission_members_refresh_class_progress();
+
+SELECT refresh_class_progress(c.id, NULL) FROM class c;
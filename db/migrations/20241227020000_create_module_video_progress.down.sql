@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS module_video_progress;
+
+ALTER TABLE class_completion_rules DROP COLUMN IF EXISTS video_watch_percent;
@@ -0,0 +1,19 @@
+-- share of every video of a module a user has to watch before the module is done
+ALTER TABLE class_completion_rules ADD COLUMN IF NOT EXISTS video_watch_percent NUMERIC(5, 2) NOT NULL DEFAULT 90 CHECK (video_watch_percent > 0 AND video_watch_percent <= 100);
+
+-- one row per user and module video, a video is identified by its url in modules.videos
+CREATE TABLE IF NOT EXISTS module_video_progress (
+    user_id UUID NOT NULL,
+    module_id INT NOT NULL,
+    video TEXT NOT NULL,
+    position_seconds NUMERIC(8, 2) NOT NULL DEFAULT 0, -- where to resume
+    duration_seconds NUMERIC(8, 2) NOT NULL,
+    watched BYTEA NOT NULL DEFAULT '', -- bitmap of the watched seconds, bit i is second i
+    watched_seconds INT NOT NULL DEFAULT 0,
+    last_heartbeat_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, module_id, video),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (module_id) REFERENCES modules(id) ON DELETE CASCADE
+);
@@ -0,0 +1,29 @@
+-- enrollments already completed stay completed
+CREATE OR REPLACE FUNCTION refresh_class_progress(p_class_id INT, p_user_id UUID) RETURNS VOID AS $$
+    INSERT INTO class_progress (user_id, class_id)
+    SELECT u.user_id, p_class_id
+    FROM (
+        SELECT uc.user_id FROM users_classes uc WHERE uc.class_id = p_class_id
+        UNION
+        SELECT up.user_id FROM users_progress up WHERE up.class_id = p_class_id
+    ) u
+    JOIN class c ON c.id = p_class_id -- gone while a deleted class cascades
+    WHERE p_user_id IS NULL OR u.user_id = p_user_id
+    ORDER BY u.user_id
+    ON CONFLICT (user_id, class_id) DO UPDATE SET updated_at = class_progress.updated_at;
+
+    UPDATE class_progress cp
+    SET
+        completed_items = d.completed,
+        total_items = d.total,
+        progress = CASE WHEN d.total > 0 THEN ROUND(d.completed * 100.0 / d.total, 2) ELSE 0 END,
+        updated_at = NOW()
+    FROM (
+        SELECT c.user_id, (COUNT(i.item_id) FILTER (WHERE i.completed))::INT AS completed, COUNT(i.item_id)::INT AS total
+        FROM class_progress c
+        LEFT JOIN LATERAL class_completion_items(c.class_id, c.user_id) i ON TRUE
+        WHERE c.class_id = p_class_id AND (p_user_id IS NULL OR c.user_id = p_user_id)
+        GROUP BY c.user_id
+    ) d
+    WHERE cp.class_id = p_class_id AND cp.user_id = d.user_id;
+$$ LANGUAGE sql VOLATILE;
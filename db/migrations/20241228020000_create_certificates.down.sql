@@ -0,0 +1,31 @@
+CREATE OR REPLACE VIEW storage_references AS
+    SELECT
+        ma.storage_key,
+        ma.uploader_id AS owner_id,
+        ma.size,
+        'module_attachment' AS kind
+    FROM module_attachments ma
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        u.id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'avatar'
+    FROM users u, jsonb_each(u.image_variants) v
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        c.creator_class_id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'class_cover'
+    FROM class c, jsonb_each(c.image_variants) v
+    UNION ALL
+    SELECT
+        sf.storage_key,
+        s.student_id,
+        sf.size,
+        'submission_file'
+    FROM submission_files sf
+    JOIN submissions s ON s.id = sf.submission_id;
+
+DROP TABLE IF EXISTS certificates;
@@ -0,0 +1,54 @@
+-- one certificate per user and class, the name and title are kept as printed
+CREATE TABLE IF NOT EXISTS certificates (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE, -- verification code printed on the certificate
+    user_id UUID NOT NULL,
+    class_id INT NOT NULL,
+    student_name VARCHAR(255) NOT NULL,
+    class_title VARCHAR(255) NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    storage_key TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, class_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (class_id) REFERENCES class(id) ON DELETE CASCADE
+);
+
+-- certificates count towards the storage of their student and keep their file from being cleaned up
+CREATE OR REPLACE VIEW storage_references AS
+    SELECT
+        ma.storage_key,
+        ma.uploader_id AS owner_id,
+        ma.size,
+        'module_attachment' AS kind
+    FROM module_attachments ma
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        u.id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'avatar'
+    FROM users u, jsonb_each(u.image_variants) v
+    UNION ALL
+    SELECT
+        v.value->>'key',
+        c.creator_class_id,
+        COALESCE((v.value->>'size')::BIGINT, 0),
+        'class_cover'
+    FROM class c, jsonb_each(c.image_variants) v
+    UNION ALL
+    SELECT
+        sf.storage_key,
+        s.student_id,
+        sf.size,
+        'submission_file'
+    FROM submission_files sf
+    JOIN submissions s ON s.id = sf.submission_id
+    UNION ALL
+    SELECT
+        storage_key,
+        user_id,
+        size,
+        'certificate'
+    FROM certificates;
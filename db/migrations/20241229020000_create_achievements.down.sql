@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_activity_days;
+DROP TABLE IF EXISTS user_achievements;
+DROP TABLE IF EXISTS achievements;
+DROP TYPE IF EXISTS achievement_rule;
@@ -0,0 +1,48 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'achievement_rule') THEN
+        CREATE TYPE achievement_rule AS ENUM ('modules_completed', 'classes_completed', 'quiz_perfect_scores', 'assignments_on_time', 'learning_streak');
+    END IF;
+END
+$$;
+
+-- an achievement is awarded once the value of its rule for a student reaches the threshold
+CREATE TABLE IF NOT EXISTS achievements (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon VARCHAR(255) NOT NULL DEFAULT '',
+    rule achievement_rule NOT NULL,
+    threshold INT NOT NULL CHECK (threshold > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE, -- inactive ones are no longer awarded, awarded ones are kept
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id UUID NOT NULL,
+    achievement_id INT NOT NULL,
+    awarded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, achievement_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (achievement_id) REFERENCES achievements(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_achievements_achievement_id ON user_achievements (achievement_id);
+
+-- days a student tracked a module, watched a video or took a quiz on, for the streak rule
+CREATE TABLE IF NOT EXISTS user_activity_days (
+    user_id UUID NOT NULL,
+    day DATE NOT NULL,
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO achievements (code, title, description, icon, rule, threshold) VALUES
+    ('first-module', 'First Steps', 'Finish your first module', 'footprints', 'modules_completed', 1),
+    ('perfect-quiz', 'Perfectionist', 'Score 100% on a quiz', 'star', 'quiz_perfect_scores', 1),
+    ('on-time-5', 'Punctual', 'Submit 5 assignments on time', 'clock', 'assignments_on_time', 5),
+    ('streak-7', 'On Fire', 'Learn 7 days in a row', 'flame', 'learning_streak', 7),
+    ('first-class', 'Graduate', 'Complete your first class', 'graduation-cap', 'classes_completed', 1)
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS class_student_engagement;
+DROP VIEW IF EXISTS class_completion_times;
+DROP VIEW IF EXISTS class_module_daily_stats;
+DROP VIEW IF EXISTS class_daily_activity;
@@ -0,0 +1,123 @@
+-- learning activity of the enrolled students per class and day. users_progress and module_video_progress keep
+-- their latest timestamps only, so earlier days are known from quiz attempts and submissions alone.
+CREATE OR REPLACE VIEW class_daily_activity AS
+SELECT
+    e.class_id,
+    e.user_id,
+    e.active_at::DATE AS day,
+    COUNT(*) FILTER (WHERE e.kind = 'module_completed') AS modules_completed,
+    COUNT(*) FILTER (WHERE e.kind = 'quiz') AS quiz_attempts,
+    COUNT(*) FILTER (WHERE e.kind = 'submission') AS submissions,
+    MAX(e.active_at) AS last_active_at
+FROM (
+    SELECT up.class_id, up.user_id, up.started_at AS active_at, 'module' AS kind
+    FROM users_progress up
+
+    UNION ALL
+
+    SELECT up.class_id, up.user_id, up.updated_at, 'module'
+    FROM users_progress up
+
+    UNION ALL
+
+    SELECT up.class_id, up.user_id, up.completed_at, 'module_completed'
+    FROM users_progress up
+    WHERE up.status = 'done' AND up.completed_at IS NOT NULL
+
+    UNION ALL
+
+    SELECT mat.class_id, vp.user_id, vp.updated_at, 'video'
+    FROM module_video_progress vp
+    JOIN modules m ON m.id = vp.module_id
+    JOIN materials mat ON mat.id = m.materials_id
+
+    UNION ALL
+
+    SELECT q.class_id, ucq.user_id, ucq.created_at, 'quiz'
+    FROM users_completed_quiz ucq
+    JOIN quiz q ON q.id = ucq.quiz_id
+
+    UNION ALL
+
+    SELECT a.class_id, s.student_id, s.submitted_at, 'submission'
+    FROM submissions s
+    JOIN assignments a ON a.id = s.assignment_id
+) e
+JOIN users_classes uc ON uc.class_id = e.class_id AND uc.user_id = e.user_id
+GROUP BY e.class_id, e.user_id, e.active_at::DATE;
+
+-- modules started and completed per day, completion_seconds adds up the time from start to completion of the
+-- modules completed that day
+CREATE OR REPLACE VIEW class_module_daily_stats AS
+SELECT
+    mat.class_id,
+    mat.id AS material_id,
+    m.id AS module_id,
+    e.day,
+    COUNT(*) FILTER (WHERE e.kind = 'started') AS started,
+    COUNT(*) FILTER (WHERE e.kind = 'completed') AS completed,
+    COALESCE(SUM(e.seconds) FILTER (WHERE e.kind = 'completed'), 0) AS completion_seconds
+FROM (
+    SELECT up.user_id, up.module_id, up.started_at::DATE AS day, 'started' AS kind, 0::NUMERIC AS seconds
+    FROM users_progress up
+
+    UNION ALL
+
+    SELECT up.user_id, up.module_id, up.completed_at::DATE, 'completed', GREATEST(EXTRACT(EPOCH FROM up.completed_at - up.started_at), 0)
+    FROM users_progress up
+    WHERE up.status = 'done' AND up.completed_at IS NOT NULL
+) e
+JOIN modules m ON m.id = e.module_id
+JOIN materials mat ON mat.id = m.materials_id
+JOIN users_classes uc ON uc.class_id = mat.class_id AND uc.user_id = e.user_id
+GROUP BY mat.class_id, mat.id, m.id, e.day;
+
+-- completed enrollments with the time from enrolling to the last completed item
+CREATE OR REPLACE VIEW class_completion_times AS
+SELECT
+    uc.class_id,
+    uc.user_id,
+    uc.created_at AS enrolled_at,
+    f.completed_at,
+    GREATEST(EXTRACT(EPOCH FROM f.completed_at - uc.created_at), 0) AS seconds
+FROM users_classes uc
+CROSS JOIN LATERAL (
+    SELECT MAX(i.completed_at) AS completed_at
+    FROM class_completion_items(uc.class_id, uc.user_id) i
+    WHERE i.completed
+) f
+WHERE uc.enrollment_status = 'completed' AND f.completed_at IS NOT NULL;
+
+-- current engagement of the active enrollments, missing assignments are past the deadline of the student without
+-- an active submission of theirs or their team
+CREATE OR REPLACE VIEW class_student_engagement AS
+SELECT
+    uc.class_id,
+    uc.user_id,
+    u.name,
+    u.email,
+    uc.created_at AS enrolled_at,
+    act.last_active_at,
+    COALESCE(cp.progress, 0) AS progress,
+    ma.missing_assignments
+FROM users_classes uc
+JOIN users u ON u.id = uc.user_id
+LEFT JOIN class_progress cp ON cp.class_id = uc.class_id AND cp.user_id = uc.user_id
+LEFT JOIN LATERAL (
+    SELECT MAX(d.last_active_at) AS last_active_at
+    FROM class_daily_activity d
+    WHERE d.class_id = uc.class_id AND d.user_id = uc.user_id
+) act ON TRUE
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS missing_assignments
+    FROM assignments a
+    CROSS JOIN LATERAL assignment_deadline(a.id, uc.user_id) dl
+    WHERE a.class_id = uc.class_id AND dl.due_date < NOW()
+        AND NOT EXISTS (
+            SELECT 1
+            FROM submissions s
+            LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = uc.user_id
+            WHERE s.assignment_id = a.id AND s.is_active AND (s.student_id = uc.user_id OR sm.student_id IS NOT NULL)
+        )
+) ma
+WHERE uc.enrollment_status = 'active';
@@ -51,11 +51,33 @@ type Config struct {
 		SslMode  string `env:"HACKO_POSTGRES_SSL_MODE_PROD" env-default:"disable"`
 	}
 	HackoStorage struct {
-		Key      string `env:"HACKO_STORAGE_KEY"`
-		Secret   string `env:"HACKO_STORAGE_SECRET"`
-		Endpoint string `env:"HACKO_STORAGE_ENDPOINT"`
-		Region   string `env:"HACKO_STORAGE_REGION"`
-		Bucket   string `env:"HACKO_STORAGE_BUCKET"`
+		Driver    string `env:"HACKO_STORAGE_DRIVER" env-default:"local" env-description:"storage backend, local or s3"`
+		Key       string `env:"HACKO_STORAGE_KEY"`
+		Secret    string `env:"HACKO_STORAGE_SECRET"`
+		Endpoint  string `env:"HACKO_STORAGE_ENDPOINT"`
+		Region    string `env:"HACKO_STORAGE_REGION"`
+		Bucket    string `env:"HACKO_STORAGE_BUCKET"`
+		PublicURL string `env:"HACKO_STORAGE_PUBLIC_URL" env-description:"optional CDN url for public objects"`
+		UrlExpiry int    `env:"HACKO_STORAGE_URL_EXPIRY" env-default:"900" env-description:"private file url lifetime in seconds"`
+		Quota     int64  `env:"HACKO_STORAGE_QUOTA" env-default:"1073741824" env-description:"default storage quota per user in bytes, 0 is unlimited"`
+	}
+	Upload struct {
+		AvatarTypes       []string `env:"UPLOAD_AVATAR_TYPES" env-default:"image/jpeg,image/png"`
+		AvatarMaxSize     int64    `env:"UPLOAD_AVATAR_MAX_SIZE" env-default:"2097152" env-description:"max avatar size in bytes"`
+		CoverTypes        []string `env:"UPLOAD_COVER_TYPES" env-default:"image/jpeg,image/png"`
+		CoverMaxSize      int64    `env:"UPLOAD_COVER_MAX_SIZE" env-default:"5242880" env-description:"max class cover size in bytes"`
+		AttachmentTypes   []string `env:"UPLOAD_ATTACHMENT_TYPES" env-default:"image/jpeg,image/png,application/pdf,application/zip,video/mp4,application/msword,application/vnd.ms-excel,application/vnd.ms-powerpoint,application/vnd.openxmlformats-officedocument.wordprocessingml.document,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.openxmlformats-officedocument.presentationml.presentation"`
+		AttachmentMaxSize int64    `env:"UPLOAD_ATTACHMENT_MAX_SIZE" env-default:"209715200" env-description:"max module attachment size in bytes"`
+		SubmissionTypes   []string `env:"UPLOAD_SUBMISSION_TYPES" env-default:"image/jpeg,image/png,text/plain,application/pdf,application/zip,application/msword,application/vnd.openxmlformats-officedocument.wordprocessingml.document,application/vnd.openxmlformats-officedocument.presentationml.presentation"`
+		SubmissionMaxSize int64    `env:"UPLOAD_SUBMISSION_MAX_SIZE" env-default:"52428800" env-description:"max submission file size in bytes"`
+	}
+	Sandbox struct {
+		GoBinary       string `env:"SANDBOX_GO_BINARY" env-default:"go"`
+		PythonBinary   string `env:"SANDBOX_PYTHON_BINARY" env-default:"python3"`
+		WorkDir        string `env:"SANDBOX_WORK_DIR" env-default:"./storage/sandbox" env-description:"run directories, must not be shared with other services"`
+		MaxRuns        int    `env:"SANDBOX_MAX_RUNS" env-default:"2" env-description:"submissions graded at the same time"`
+		CompileTimeout int    `env:"SANDBOX_COMPILE_TIMEOUT" env-default:"60" env-description:"go build timeout in seconds"`
+		OutputLimit    int    `env:"SANDBOX_OUTPUT_LIMIT" env-default:"65536" env-description:"stdout and stderr kept per run in bytes"`
 	}
 	Oauth struct {
 		Google struct {
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type RunRequest struct {
+	Language    string
+	Source      string
+	Inputs      []string // stdin of every run, the program is built once and run for each input
+	TimeLimit   time.Duration
+	MemoryLimit int64 // in bytes
+}
+
+type RunResponse struct {
+	Compiled      bool
+	CompileOutput string
+	Results       []RunResult
+}
+
+type RunResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+}
@@ -0,0 +1,216 @@
+//go:build linux
+
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// InitCommand is the argument the server binary is executed with to become the first process of a run.
+const InitCommand = "sandbox-init"
+
+// rlimitNproc is RLIMIT_NPROC, which the syscall package does not name, nor the prctl options below.
+const rlimitNproc = 0x6
+
+const (
+	prSetNoNewPrivs      = 38
+	prCapAmbient         = 47
+	prCapAmbientClearAll = 4
+)
+
+// lockedFlags are the flags a mount of the host keeps in a user namespace, a remount of its bind must repeat them.
+const lockedFlags = syscall.MS_RDONLY | syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC
+
+// initExitCode is the exit code of a run whose sandbox could not be set up.
+const initExitCode = 126
+
+// initSpec is what the first process of a run needs to set it up.
+type initSpec struct {
+	Root       string   // empty directory the root file system of the run is built on
+	Dir        string   // run directory, the only writable path at /work
+	Binds      []string // host paths mounted read-only at the same place
+	CPUSeconds uint64
+	Memory     uint64
+	FileSize   uint64
+	Files      uint64
+	Processes  uint64
+	Args       []string
+	Env        []string
+}
+
+// Init runs in the fresh user, mount, pid, network, ipc and uts namespaces of a run. It builds a read-only root out
+// of Binds, the run directory, a few devices and its own /proc, pivots into it, sets the resource limits, drops the
+// capability it mounted with and replaces itself with the program. It never returns.
+func Init(args []string) {
+	var spec initSpec
+
+	if len(args) != 1 {
+		initFail(errors.New("missing run spec"))
+	}
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		initFail(err)
+	}
+
+	if err := spec.setup(); err != nil {
+		initFail(err)
+	}
+
+	initFail(syscall.Exec(spec.Args[0], spec.Args, spec.Env))
+}
+
+func initFail(err error) {
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(initExitCode)
+}
+
+func (s *initSpec) setup() error {
+	// nothing mounted from here on may show up on the host
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+
+	if err := syscall.Mount("tmpfs", s.Root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size=1m,mode=0755"); err != nil {
+		return fmt.Errorf("mount root: %w", err)
+	}
+
+	for _, path := range s.Binds {
+		if err := bindHost(s.Root, path); err != nil {
+			return err
+		}
+	}
+
+	work := filepath.Join(s.Root, "work")
+	if err := os.Mkdir(work, 0o755); err != nil {
+		return err
+	}
+	if err := bind(s.Dir, work, syscall.MS_NOSUID|syscall.MS_NODEV); err != nil {
+		return err
+	}
+
+	// device files need the mount without nodev
+	if err := os.Mkdir(filepath.Join(s.Root, "dev"), 0o755); err != nil {
+		return err
+	}
+	for _, device := range []string{"null", "zero", "random", "urandom"} {
+		target := filepath.Join(s.Root, "dev", device)
+		if err := os.WriteFile(target, nil, 0o644); err != nil {
+			return err
+		}
+		if err := bind(filepath.Join("/dev", device), target, syscall.MS_NOSUID|syscall.MS_NOEXEC); err != nil {
+			return err
+		}
+	}
+
+	// the proc of the new pid namespace only shows the processes of the run
+	proc := filepath.Join(s.Root, "proc")
+	if err := os.Mkdir(proc, 0o755); err != nil {
+		return err
+	}
+	if err := syscall.Mount("proc", proc, "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount proc: %w", err)
+	}
+
+	// pivoting onto the same directory stacks the old root below the new one, so it can be detached right away
+	if err := syscall.Chdir(s.Root); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot root: %w", err)
+	}
+	if err := syscall.Unmount(".", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach old root: %w", err)
+	}
+	if err := syscall.Mount("", "/", "", syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("remount root read-only: %w", err)
+	}
+	if err := syscall.Chdir("/work"); err != nil {
+		return err
+	}
+
+	rlimits := []struct {
+		resource int
+		value    uint64
+	}{
+		{syscall.RLIMIT_CPU, s.CPUSeconds},
+		{syscall.RLIMIT_DATA, s.Memory},
+		{syscall.RLIMIT_FSIZE, s.FileSize},
+		{syscall.RLIMIT_NOFILE, s.Files},
+		{syscall.RLIMIT_CORE, 0},
+		{rlimitNproc, s.Processes},
+	}
+	for _, l := range rlimits {
+		if err := syscall.Setrlimit(l.resource, &syscall.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("set resource limit %d: %w", l.resource, err)
+		}
+	}
+
+	// the program runs as a user other than root of the namespace, so it starts without the ambient capability,
+	// and neither set-user-id binaries nor file capabilities can give it one back
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientClearAll, 0); errno != 0 {
+		return fmt.Errorf("drop capabilities: %w", errno)
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("set no new privileges: %w", errno)
+	}
+
+	return nil
+}
+
+// bindHost mounts a host path read-only at the same place below root, symlinks are copied and missing paths
+// skipped.
+func bindHost(root, path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case info.IsDir():
+		if err := os.Mkdir(target, 0o755); err != nil {
+			return err
+		}
+	default:
+		if err := os.WriteFile(target, nil, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return bind(path, target, syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV)
+}
+
+// bind mounts source on target, the flags only apply to a remount of the bind mount and add to the flags the
+// source is locked with.
+func bind(source, target string, flags uintptr) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(source, &stat); err != nil {
+		return fmt.Errorf("stat %s: %w", source, err)
+	}
+
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("bind %s: %w", source, err)
+	}
+	if err := syscall.Mount("", target, "", syscall.MS_BIND|syscall.MS_REMOUNT|flags|uintptr(stat.Flags)&lockedFlags, ""); err != nil {
+		return fmt.Errorf("remount %s: %w", source, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/integration/sandbox/entity"
+	"sync"
+	"time"
+)
+
+const (
+	LanguageGo     = "go"
+	LanguagePython = "python"
+)
+
+var (
+	ErrUnsupportedLanguage = errors.New("sandbox: unsupported language")
+	ErrNotAvailable        = errors.New("sandbox: not available on this platform")
+	ErrNotConfigured       = errors.New("sandbox: not configured")
+)
+
+// Sandbox runs untrusted programs in a separate process with cpu, memory and time limits and without network.
+type Sandbox interface {
+	Run(ctx context.Context, req *entity.RunRequest) (*entity.RunResponse, error)
+}
+
+var (
+	slots     chan struct{}
+	slotsOnce sync.Once
+)
+
+// NewSandboxIntegration returns the sandbox of the platform, every instance shares the limit of concurrent runs.
+func NewSandboxIntegration() Sandbox {
+	env := config.Envs.Sandbox
+
+	slotsOnce.Do(func() {
+		slots = make(chan struct{}, max(env.MaxRuns, 1))
+	})
+
+	return newSandbox(options{
+		goBinary:       env.GoBinary,
+		pythonBinary:   env.PythonBinary,
+		workDir:        env.WorkDir,
+		compileTimeout: time.Duration(env.CompileTimeout) * time.Second,
+		outputLimit:    env.OutputLimit,
+	})
+}
+
+type options struct {
+	goBinary       string
+	pythonBinary   string
+	workDir        string
+	compileTimeout time.Duration
+	outputLimit    int
+}
+
+// acquire waits for a free run slot, the returned func gives it back.
+func acquire(ctx context.Context) (func(), error) {
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the rest.
+type limitedBuffer struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if room := b.limit - len(b.buf); n > room {
+		b.truncated = true
+		p = p[:max(room, 0)]
+	}
+	b.buf = append(b.buf, p...)
+
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return string(b.buf) + "\n[output truncated]"
+	}
+
+	return string(b.buf)
+}
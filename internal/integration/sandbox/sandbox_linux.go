@@ -0,0 +1,309 @@
+//go:build linux
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hacko-app/internal/integration/sandbox/entity"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// compileMemoryLimit is the data segment allowed to the go toolchain, programs get the limit of the assignment.
+	compileMemoryLimit = 2 << 30
+	compileFileLimit   = 1 << 30
+	// runFileLimit bounds every file a program writes.
+	runFileLimit = 16 << 20
+	// processLimit is counted per user namespace, so per run.
+	processLimit  = 256
+	openFileLimit = 256
+	// sandboxId is the user and group of programs inside their user namespace, mapped to the user of the server.
+	sandboxId = 1000
+	// capSysAdmin is CAP_SYS_ADMIN, which Init needs to mount the root of a run and the syscall package does not
+	// name.
+	capSysAdmin = 21
+)
+
+// systemPaths are mounted read-only into every run, next to the directories of the toolchains.
+var systemPaths = []string{"/usr", "/bin", "/lib", "/lib64"}
+
+type sandbox struct {
+	opts options
+	err  error // set when runs are refused
+}
+
+// newSandbox refuses every run when the server runs as root, since programs run as the user of the server, or
+// when the kernel does not allow unprivileged user namespaces.
+func newSandbox(opts options) Sandbox {
+	s := &sandbox{opts: opts}
+
+	// the run directories are mounted from inside the namespace of the run, which starts in the run directory
+	if workDir, err := filepath.Abs(opts.workDir); err == nil {
+		s.opts.workDir = workDir
+	}
+
+	switch {
+	case os.Getuid() == 0:
+		s.err = fmt.Errorf("%w: the server must not run as root, programs run as its user", ErrNotConfigured)
+	case !userNamespaces():
+		s.err = fmt.Errorf("%w: the kernel does not allow user namespaces", ErrNotConfigured)
+	}
+
+	if s.err != nil {
+		log.Error().Err(s.err).Msg("sandbox: automated grading is disabled")
+	}
+
+	return s
+}
+
+type limits struct {
+	wall   time.Duration
+	memory int64
+	files  int64
+}
+
+// Run builds the program once and runs it for every input. A program that does not compile is not an error,
+// the compiler output is returned instead.
+func (s *sandbox) Run(ctx context.Context, req *entity.RunRequest) (*entity.RunResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	release, err := acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	dir, err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		program []string
+		binds   []string
+	)
+
+	switch req.Language {
+	case LanguageGo:
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(req.Source), 0o644); err != nil {
+			log.Error().Err(err).Msg("sandbox: failed to write source")
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+
+		goBinary, err := exec.LookPath(s.opts.goBinary)
+		if err != nil {
+			log.Error().Err(err).Str("binary", s.opts.goBinary).Msg("sandbox: go toolchain not found")
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+
+		binds = readonlyPaths(goBinary)
+
+		res := s.exec(ctx, dir, binds, limits{wall: s.opts.compileTimeout, memory: compileMemoryLimit, files: compileFileLimit}, "", goBinary, "build", "-o", "main", "main.go")
+		if res.ExitCode != 0 || res.TimedOut {
+			output := strings.TrimSpace(res.Stdout + res.Stderr)
+			if res.TimedOut {
+				output = "compilation timed out"
+			}
+			return &entity.RunResponse{CompileOutput: output, Results: []entity.RunResult{}}, nil
+		}
+
+		program = []string{"/work/main"}
+	case LanguagePython:
+		if err := os.WriteFile(filepath.Join(dir, "main.py"), []byte(req.Source), 0o644); err != nil {
+			log.Error().Err(err).Msg("sandbox: failed to write source")
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+
+		pythonBinary, err := exec.LookPath(s.opts.pythonBinary)
+		if err != nil {
+			log.Error().Err(err).Str("binary", s.opts.pythonBinary).Msg("sandbox: python interpreter not found")
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+
+		// -I keeps the working directory and PYTHON* variables out of the import path
+		program = []string{pythonBinary, "-I", "main.py"}
+		binds = readonlyPaths(pythonBinary)
+	default:
+		return nil, ErrUnsupportedLanguage
+	}
+
+	response := &entity.RunResponse{Compiled: true, Results: make([]entity.RunResult, 0, len(req.Inputs))}
+	for _, input := range req.Inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		response.Results = append(response.Results, s.exec(ctx, dir, binds, limits{wall: req.TimeLimit, memory: req.MemoryLimit, files: runFileLimit}, input, program[0], program[1:]...))
+	}
+
+	return response, nil
+}
+
+// userNamespaces reports whether the user of the server may create user namespaces.
+func userNamespaces() bool {
+	limit, err := os.ReadFile("/proc/sys/user/max_user_namespaces")
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(string(limit)) != "0"
+}
+
+// prepare creates the directory of a run, and the directory the root of runs is mounted on.
+func (s *sandbox) prepare() (string, error) {
+	if err := os.MkdirAll(filepath.Join(s.opts.workDir, "root"), 0o755); err != nil {
+		log.Error().Err(err).Msg("sandbox: failed to create work directory")
+		return "", fmt.Errorf("sandbox: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(s.opts.workDir, "run-*")
+	if err != nil {
+		log.Error().Err(err).Msg("sandbox: failed to create run directory")
+		return "", fmt.Errorf("sandbox: %w", err)
+	}
+
+	return dir, nil
+}
+
+// readonlyPaths returns the system paths and the installation the binary belongs to, without paths inside
+// another one.
+func readonlyPaths(binary string) []string {
+	paths := append([]string{}, systemPaths...)
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		// <root>/bin/<binary>
+		paths = append(paths, filepath.Dir(filepath.Dir(resolved)))
+	}
+
+	sort.Strings(paths)
+
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if n := len(result); n > 0 && (path == result[n-1] || strings.HasPrefix(path, result[n-1]+"/")) {
+			continue
+		}
+		result = append(result, path)
+	}
+
+	return result
+}
+
+// exec runs one process through Init in new user, mount, pid, network, ipc and uts namespaces: it sees a read-only
+// root with the run directory at /work, no network, only its own processes, and runs as the user of the server
+// without any capability. Init only holds the capability to mount inside the user namespace of the run. The cpu
+// time limit is a backstop of the wall clock, the data segment is limited instead of the address space since the
+// go runtime reserves far more address space than it uses. Every run builds with its own go cache so no run can
+// tamper with the build of another. The whole process group is killed once the wall clock runs out.
+func (s *sandbox) exec(ctx context.Context, dir string, binds []string, l limits, stdin string, name string, args ...string) entity.RunResult {
+	ctx, cancel := context.WithTimeout(ctx, l.wall)
+	defer cancel()
+
+	self, err := os.Executable()
+	if err != nil {
+		log.Error().Err(err).Msg("sandbox: failed to find the server binary")
+		return entity.RunResult{ExitCode: -1, Stderr: "sandbox: failed to start process"}
+	}
+
+	spec, err := json.Marshal(&initSpec{
+		Root:       filepath.Join(s.opts.workDir, "root"),
+		Dir:        dir,
+		Binds:      binds,
+		CPUSeconds: uint64(l.wall.Seconds()) + 1,
+		Memory:     uint64(l.memory),
+		FileSize:   uint64(l.files),
+		Files:      openFileLimit,
+		Processes:  processLimit,
+		Args:       append([]string{name}, args...),
+		Env: []string{
+			"PATH=/usr/local/bin:/usr/bin:/bin",
+			"HOME=/work",
+			"TMPDIR=/work",
+			"GOCACHE=/work/.cache/go-build",
+			"GOPATH=/work/gopath",
+			"GOTOOLCHAIN=local",
+			"GOPROXY=off",
+			"CGO_ENABLED=0",
+		},
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("sandbox: failed to encode run spec")
+		return entity.RunResult{ExitCode: -1, Stderr: "sandbox: failed to start process"}
+	}
+
+	var (
+		stdout = &limitedBuffer{limit: s.opts.outputLimit}
+		stderr = &limitedBuffer{limit: s.opts.outputLimit}
+		cmd    = exec.CommandContext(ctx, self, InitCommand, string(spec))
+	)
+
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = []string{} // the environment of the server holds its secrets
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:     true,
+		Pdeathsig:   syscall.SIGKILL,
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: sandboxId, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: sandboxId, HostID: os.Getgid(), Size: 1}},
+		Credential:  &syscall.Credential{Uid: sandboxId, Gid: sandboxId, NoSetGroups: true},
+		AmbientCaps: []uintptr{capSysAdmin},
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+
+	result := entity.RunResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+		TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		if result.ExitCode == -1 {
+			result.ExitCode = 137 // killed by a signal
+		}
+	default:
+		log.Error().Err(err).Msg("sandbox: failed to start process")
+		result.ExitCode = -1
+		result.Stderr = "sandbox: failed to start process"
+	}
+
+	if cmd.ProcessState == nil {
+		return result
+	}
+
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() && status.Signal() == syscall.SIGXCPU {
+		result.TimedOut = true
+	}
+
+	if bytes.Contains(stderr.buf, []byte("out of memory")) || bytes.Contains(stderr.buf, []byte("MemoryError")) {
+		result.Stderr = strings.TrimSpace(result.Stderr) + "\nmemory limit exceeded"
+	}
+
+	return result
+}
@@ -0,0 +1,30 @@
+//go:build !linux
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"hacko-app/internal/integration/sandbox/entity"
+	"os"
+)
+
+// InitCommand is the argument the server binary is executed with to become the first process of a run.
+const InitCommand = "sandbox-init"
+
+// sandbox relies on linux namespaces and resource limits, elsewhere every run fails.
+type sandbox struct{}
+
+func newSandbox(options) Sandbox {
+	return &sandbox{}
+}
+
+func (s *sandbox) Run(context.Context, *entity.RunRequest) (*entity.RunResponse, error) {
+	return nil, ErrNotAvailable
+}
+
+// Init is only reached on linux, where runs are set up.
+func Init([]string) {
+	fmt.Fprintln(os.Stderr, ErrNotAvailable)
+	os.Exit(126)
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"io"
+	"time"
+)
+
+type PutObjectRequest struct {
+	Key         string
+	Body        io.Reader
+	Size        int64 // -1 when unknown
+	ContentType string
+}
+
+type Object struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+	Url          string    `json:"url,omitempty"`
+}
+
+type PutUploadRequest struct {
+	Category string
+	Key      string
+	Filename string    // name sent by the client, it tells apart the types the content does not
+	Body     io.Reader // read once, as it arrives
+	Types    []string  // narrows the types allowed by the category when set
+	Quota    int64     // bytes the uploader may still store, negative when unlimited
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hacko-app/internal/integration/storage/entity"
+	"hacko-app/pkg"
+	"hacko-app/pkg/types"
+	"path"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// PutImageVariants stores every processed image under dir and returns them keyed by variant name.
+// Variants that were already stored are removed again when one of them fails.
+func PutImageVariants(ctx context.Context, s Storage, dir string, images []pkg.ProcessedImage) (types.ImageVariants, error) {
+	var (
+		id       = ulid.Make().String()
+		variants = make(types.ImageVariants, len(images))
+	)
+
+	for _, img := range images {
+		object, err := s.Put(ctx, &entity.PutObjectRequest{
+			Key:         path.Join(dir, fmt.Sprintf("%s-%s.jpg", id, img.Name)),
+			Body:        bytes.NewReader(img.Data),
+			Size:        int64(len(img.Data)),
+			ContentType: img.ContentType,
+		})
+		if err != nil {
+			DeleteImageVariants(ctx, s, variants)
+			return nil, err
+		}
+
+		variants[img.Name] = types.ImageVariant{
+			Key:    object.Key,
+			Url:    object.Url,
+			Width:  img.Width,
+			Height: img.Height,
+			Size:   int64(len(img.Data)),
+		}
+	}
+
+	return variants, nil
+}
+
+// DeleteImageVariants removes the stored variants, failures are only logged.
+func DeleteImageVariants(ctx context.Context, s Storage, variants types.ImageVariants) {
+	for _, key := range variants.Keys() {
+		if err := s.Delete(ctx, key); err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("integration::DeleteImageVariants - Failed to delete image variant")
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/integration/storage/entity"
+	storagemanager "hacko-app/pkg/storage-manager"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ Storage = &localStorage{}
+
+// localStorage keeps objects on disk, public keys under LocalStoragePublicPath
+// and private keys under LocalStoragePrivatePath.
+type localStorage struct {
+	roots map[string]string
+}
+
+func newLocalStorage() *localStorage {
+	return &localStorage{
+		roots: map[string]string{
+			VisibilityPublic:  config.Envs.App.LocalStoragePublicPath,
+			VisibilityPrivate: config.Envs.App.LocalStoragePrivatePath,
+		},
+	}
+}
+
+func (l *localStorage) Put(ctx context.Context, req *entity.PutObjectRequest) (*entity.Object, error) {
+	fullpath, err := l.fullpath(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullpath), os.ModePerm); err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::localStorage-Put Failed to create directory")
+		return nil, err
+	}
+
+	// write to a temporary file first so readers never see a partially written object
+	tmp, err := os.CreateTemp(filepath.Dir(fullpath), ".upload-*")
+	if err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::localStorage-Put Failed to create file")
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, req.Body); err != nil {
+		tmp.Close()
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::localStorage-Put Failed to write file")
+		return nil, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::localStorage-Put Failed to close file")
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), fullpath); err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::localStorage-Put Failed to move file")
+		return nil, err
+	}
+
+	return l.Stat(ctx, req.Key)
+}
+
+func (l *localStorage) Get(ctx context.Context, key string) (io.ReadCloser, *entity.Object, error) {
+	object, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fullpath, _ := l.fullpath(key)
+	f, err := os.Open(fullpath)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::localStorage-Get Failed to open file")
+		return nil, nil, err
+	}
+
+	return f, object, nil
+}
+
+func (l *localStorage) Delete(ctx context.Context, key string) error {
+	fullpath, err := l.fullpath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullpath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Error().Err(err).Str("key", key).Msg("integration::localStorage-Delete Failed to delete file")
+		return err
+	}
+
+	return nil
+}
+
+func (l *localStorage) List(ctx context.Context, prefix string) ([]entity.Object, error) {
+	objects := []entity.Object{}
+
+	for _, visibility := range []string{VisibilityPublic, VisibilityPrivate} {
+		root := l.roots[visibility]
+
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+
+			if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+
+			key := Key(visibility, filepath.ToSlash(rel))
+			if !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			objects = append(objects, l.object(key, p, info))
+			return nil
+		})
+		if err != nil {
+			log.Error().Err(err).Str("prefix", prefix).Msg("integration::localStorage-List Failed to walk storage directory")
+			return objects, err
+		}
+	}
+
+	return objects, nil
+}
+
+func (l *localStorage) Stat(ctx context.Context, key string) (*entity.Object, error) {
+	fullpath, err := l.fullpath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullpath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		log.Error().Err(err).Str("key", key).Msg("integration::localStorage-Stat Failed to stat file")
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+
+	object := l.object(key, fullpath, info)
+	return &object, nil
+}
+
+func (l *localStorage) Presign(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	visibility, name, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if visibility == VisibilityPublic {
+		return publicLocalURL(name), nil
+	}
+
+	return storagemanager.GenerateSignedURL(name, expiry), nil
+}
+
+func (l *localStorage) fullpath(key string) (string, error) {
+	visibility, name, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(l.roots[visibility], filepath.FromSlash(name)), nil
+}
+
+func (l *localStorage) object(key, fullpath string, info fs.FileInfo) entity.Object {
+	object := entity.Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  detectFileContentType(fullpath),
+		LastModified: info.ModTime().UTC(),
+	}
+
+	if visibility, name, err := splitKey(key); err == nil && visibility == VisibilityPublic {
+		object.Url = publicLocalURL(name)
+	}
+
+	return object
+}
+
+func publicLocalURL(name string) string {
+	return strings.TrimRight(config.Envs.App.BaseURL, "/") + "/api/storage/public/" + name
+}
+
+// detectFileContentType uses the extension and falls back to sniffing the first bytes of the file.
+func detectFileContentType(fullpath string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(fullpath)); contentType != "" {
+		return contentType
+	}
+
+	f, err := os.Open(fullpath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+
+	return http.DetectContentType(head[:n])
+}
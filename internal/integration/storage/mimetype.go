@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MimeJPEG = "image/jpeg"
+	MimePNG  = "image/png"
+	MimeGIF  = "image/gif"
+	MimeWEBP = "image/webp"
+	MimePDF  = "application/pdf"
+	MimeZIP  = "application/zip"
+	MimeMP4  = "video/mp4"
+	MimeText = "text/plain"
+	MimeDOC  = "application/msword"
+	MimeXLS  = "application/vnd.ms-excel"
+	MimePPT  = "application/vnd.ms-powerpoint"
+	MimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MimePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+
+	mimeOLE     = "application/x-ole-storage"
+	mimeUnknown = "application/octet-stream"
+)
+
+var extensions = map[string]string{
+	MimeJPEG: "jpg",
+	MimePNG:  "png",
+	MimeGIF:  "gif",
+	MimeWEBP: "webp",
+	MimePDF:  "pdf",
+	MimeZIP:  "zip",
+	MimeMP4:  "mp4",
+	MimeText: "txt",
+	MimeDOC:  "doc",
+	MimeXLS:  "xls",
+	MimePPT:  "ppt",
+	MimeDOCX: "docx",
+	MimeXLSX: "xlsx",
+	MimePPTX: "pptx",
+}
+
+// oleMagic is the header of the compound file format used by doc, xls and ppt.
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// detectMimeType sniffs the first bytes of a file, uploads are checked as they stream in so nothing past them is
+// read. Office documents can't be told apart by their first bytes: OOXML files are zip archives and the legacy
+// formats share the compound file header, so for both the filename extension picks the format.
+func detectMimeType(head []byte, filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	if bytes.HasPrefix(head, oleMagic) {
+		switch ext {
+		case ".doc":
+			return MimeDOC
+		case ".xls":
+			return MimeXLS
+		case ".ppt":
+			return MimePPT
+		default:
+			return mimeOLE
+		}
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return mimeUnknown
+	}
+
+	if mimeType == MimeZIP {
+		switch ext {
+		case ".docx":
+			return MimeDOCX
+		case ".xlsx":
+			return MimeXLSX
+		case ".pptx":
+			return MimePPTX
+		}
+	}
+
+	return mimeType
+}
+
+func extensionFromMimeType(mimeType string) string {
+	return extensions[mimeType]
+}
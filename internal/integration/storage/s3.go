@@ -0,0 +1,220 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/integration/storage/entity"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/rs/zerolog/log"
+)
+
+var _ Storage = &s3Storage{}
+
+// s3Storage stores objects in any S3 compatible bucket (DigitalOcean Spaces, MinIO, AWS).
+type s3Storage struct {
+	client *s3.Client
+	bucket string
+}
+
+func newS3Storage() *s3Storage {
+	return &s3Storage{
+		client: adapter.Adapters.HackoStorage,
+		bucket: config.Envs.HackoStorage.Bucket,
+	}
+}
+
+func (s *s3Storage) Put(ctx context.Context, req *entity.PutObjectRequest) (*entity.Object, error) {
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-Put Storage is not configured")
+		return nil, ErrStorageNotAvailable
+	}
+
+	if _, _, err := splitKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(req.Key),
+		Body:   req.Body,
+	}
+
+	if req.ContentType != "" {
+		input.ContentType = aws.String(req.ContentType)
+	}
+
+	if IsPublicKey(req.Key) {
+		input.ACL = types.ObjectCannedACLPublicRead
+	}
+
+	if _, err := manager.NewUploader(s.client).Upload(ctx, input); err != nil {
+		log.Error().Err(err).Str("key", req.Key).Msg("integration::s3Storage-Put Error while uploading file")
+		return nil, err
+	}
+
+	return s.Stat(ctx, req.Key)
+}
+
+func (s *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *entity.Object, error) {
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-Get Storage is not configured")
+		return nil, nil, ErrStorageNotAvailable
+	}
+
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil, ErrObjectNotFound
+		}
+		log.Error().Err(err).Str("key", key).Msg("integration::s3Storage-Get Error while getting file")
+		return nil, nil, err
+	}
+
+	object := s.object(key, aws.ToInt64(out.ContentLength), aws.ToString(out.ContentType), aws.ToTime(out.LastModified))
+	return out.Body, &object, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-Delete Storage is not configured")
+		return ErrStorageNotAvailable
+	}
+
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::s3Storage-Delete Error while deleting file")
+		return err
+	}
+
+	return nil
+}
+
+func (s *s3Storage) List(ctx context.Context, prefix string) ([]entity.Object, error) {
+	objects := []entity.Object{}
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-List Storage is not configured")
+		return objects, ErrStorageNotAvailable
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+	}
+
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	// a page holds at most 1000 objects
+	paginator := s3.NewListObjectsV2Paginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error().Err(err).Str("prefix", prefix).Msg("integration::s3Storage-List Error while listing files")
+			return objects, err
+		}
+
+		for _, file := range page.Contents {
+			objects = append(objects, s.object(aws.ToString(file.Key), aws.ToInt64(file.Size), "", aws.ToTime(file.LastModified)))
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *s3Storage) Stat(ctx context.Context, key string) (*entity.Object, error) {
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-Stat Storage is not configured")
+		return nil, ErrStorageNotAvailable
+	}
+
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrObjectNotFound
+		}
+		log.Error().Err(err).Str("key", key).Msg("integration::s3Storage-Stat Error while reading file metadata")
+		return nil, err
+	}
+
+	object := s.object(key, aws.ToInt64(out.ContentLength), aws.ToString(out.ContentType), aws.ToTime(out.LastModified))
+	return &object, nil
+}
+
+func (s *s3Storage) Presign(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if s.client == nil {
+		log.Error().Msg("integration::s3Storage-Presign Storage is not configured")
+		return "", ErrStorageNotAvailable
+	}
+
+	if IsPublicKey(key) {
+		return s.publicURL(key), nil
+	}
+
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::s3Storage-Presign Error while presigning file")
+		return "", err
+	}
+
+	return req.URL, nil
+}
+
+func (s *s3Storage) object(key string, size int64, contentType string, lastModified time.Time) entity.Object {
+	object := entity.Object{
+		Key:          key,
+		Size:         size,
+		ContentType:  contentType,
+		LastModified: lastModified.UTC(),
+	}
+
+	if IsPublicKey(key) {
+		object.Url = s.publicURL(key)
+	}
+
+	return object
+}
+
+// publicURL prefers the configured CDN url and falls back to a path style bucket url.
+func (s *s3Storage) publicURL(key string) string {
+	if base := config.Envs.HackoStorage.PublicURL; base != "" {
+		return strings.TrimRight(base, "/") + "/" + key
+	}
+
+	return strings.TrimRight(config.Envs.HackoStorage.Endpoint, "/") + "/" + s.bucket + "/" + key
+}
+
+func isNotFound(err error) bool {
+	var (
+		noSuchKey *types.NoSuchKey
+		notFound  *types.NotFound
+		apiErr    smithy.APIError
+	)
+
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return true
+	}
+
+	return errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NotFound" || apiErr.ErrorCode() == "NoSuchKey")
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"context"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/integration/storage/entity"
+	"hacko-app/pkg/errmsg"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DriverS3    = "s3"
+	DriverLocal = "local"
+
+	// Every key starts with its visibility, public objects can be read by anyone
+	// while private objects are only reachable through a presigned url.
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+var (
+	ErrObjectNotFound      = errmsg.NewCustomErrors(404, errmsg.WithMessage("File not found"))
+	ErrInvalidKey          = errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid file key"))
+	ErrStorageNotAvailable = errmsg.NewCustomErrors(503, errmsg.WithMessage("File storage is not available"))
+	ErrQuotaExceeded       = errmsg.NewCustomErrors(413, errmsg.WithMessage("Storage quota exceeded"))
+)
+
+type Storage interface {
+	Put(ctx context.Context, req *entity.PutObjectRequest) (*entity.Object, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, *entity.Object, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]entity.Object, error)
+	Stat(ctx context.Context, key string) (*entity.Object, error)
+	Presign(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// NewStorageIntegration returns the storage backend selected by HACKO_STORAGE_DRIVER.
+func NewStorageIntegration() Storage {
+	if config.Envs.HackoStorage.Driver == DriverS3 {
+		return newS3Storage()
+	}
+
+	return newLocalStorage()
+}
+
+// Key joins the parts into an object key under the given visibility.
+func Key(visibility string, parts ...string) string {
+	return path.Join(append([]string{visibility}, parts...)...)
+}
+
+// IsPublicKey reports whether the key is stored under the public visibility.
+func IsPublicKey(key string) bool {
+	return strings.HasPrefix(key, VisibilityPublic+"/")
+}
+
+// splitKey separates the visibility from the rest of the key and rejects keys that could escape it.
+func splitKey(key string) (visibility, name string, err error) {
+	visibility, name, ok := strings.Cut(key, "/")
+	if !ok || (visibility != VisibilityPublic && visibility != VisibilityPrivate) {
+		return "", "", ErrInvalidKey
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", "", ErrInvalidKey
+		}
+	}
+
+	return visibility, name, nil
+}
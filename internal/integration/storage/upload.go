@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/integration/storage/entity"
+	"hacko-app/pkg/errmsg"
+	"io"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Uploads are checked against the types and the size allowed for their category before they are stored.
+const (
+	CategoryAvatar     = "avatar"
+	CategoryCover      = "cover"
+	CategoryAttachment = "attachment"
+	CategorySubmission = "submission"
+)
+
+var (
+	ErrFileTypeNotSupported = errmsg.NewCustomErrors(415, errmsg.WithMessage("file type not supported"))
+	ErrFileTooLarge         = errmsg.NewCustomErrors(413, errmsg.WithMessage("file is too large"))
+	ErrUnknownCategory      = errors.New("storage: unknown upload category")
+)
+
+type uploadRule struct {
+	types   []string
+	maxSize int64
+}
+
+func uploadRules(category string) (uploadRule, bool) {
+	env := config.Envs.Upload
+
+	switch category {
+	case CategoryAvatar:
+		return uploadRule{types: env.AvatarTypes, maxSize: env.AvatarMaxSize}, true
+	case CategoryCover:
+		return uploadRule{types: env.CoverTypes, maxSize: env.CoverMaxSize}, true
+	case CategoryAttachment:
+		return uploadRule{types: env.AttachmentTypes, maxSize: env.AttachmentMaxSize}, true
+	case CategorySubmission:
+		return uploadRule{types: env.SubmissionTypes, maxSize: env.SubmissionMaxSize}, true
+	default:
+		return uploadRule{}, false
+	}
+}
+
+// sniffLen is the start of a file its type is sniffed from, see http.DetectContentType.
+const sniffLen = 512
+
+// MaxUploadSize is the largest file allowed in the category, used as the request body limit of its upload route.
+func MaxUploadSize(category string) int64 {
+	rule, _ := uploadRules(category)
+	return rule.maxSize
+}
+
+// PutUpload stores an uploaded file as it is read from req.Body, nothing but the start of it is held in memory.
+// The type is sniffed before anything is stored, and the upload fails without leaving an object behind once the
+// file grows past the largest file of the category or the quota left.
+func PutUpload(ctx context.Context, s Storage, req *entity.PutUploadRequest) (*entity.Object, error) {
+	rule, ok := uploadRules(req.Category)
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+
+	body := &limitedUpload{r: req.Body, limit: rule.maxSize, err: ErrFileTooLarge}
+	if req.Quota >= 0 && req.Quota < rule.maxSize {
+		body.limit, body.err = req.Quota, ErrQuotaExceeded
+	}
+
+	buffered := bufio.NewReaderSize(body, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, uploadError(err)
+	}
+
+	mimeType, err := checkType(rule, req.Types, head, req.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.Put(ctx, &entity.PutObjectRequest{
+		Key:         req.Key,
+		Body:        buffered,
+		Size:        -1,
+		ContentType: mimeType,
+	})
+	if err != nil {
+		if body.exceeded() {
+			log.Warn().Str("category", req.Category).Int64("limit", body.limit).Msg("integration::storage-PutUpload Upload is too large")
+			return nil, body.err
+		}
+		return nil, uploadError(err)
+	}
+
+	object.ContentType = mimeType
+	return object, nil
+}
+
+// ReadUpload reads an uploaded file that is processed before it is stored, checked the same way as PutUpload.
+func ReadUpload(category, filename string, r io.Reader) ([]byte, string, error) {
+	rule, ok := uploadRules(category)
+	if !ok {
+		return nil, "", ErrUnknownCategory
+	}
+
+	body := &limitedUpload{r: r, limit: rule.maxSize, err: ErrFileTooLarge}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		if body.exceeded() {
+			log.Warn().Str("category", category).Int64("limit", body.limit).Msg("integration::storage-ReadUpload Upload is too large")
+			return nil, "", body.err
+		}
+		return nil, "", uploadError(err)
+	}
+
+	mimeType, err := checkType(rule, nil, data[:min(len(data), sniffLen)], filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, mimeType, nil
+}
+
+// checkType sniffs the type of a file from its first bytes, it must be allowed by the category and by types when
+// they narrow it down.
+func checkType(rule uploadRule, types []string, head []byte, filename string) (string, error) {
+	mimeType := detectMimeType(head, filename)
+	if !slices.Contains(rule.types, mimeType) || extensionFromMimeType(mimeType) == "" || (len(types) > 0 && !slices.Contains(types, mimeType)) {
+		log.Warn().Str("mimeType", mimeType).Msg("integration::storage-checkType File type not supported")
+		return "", ErrFileTypeNotSupported
+	}
+
+	return mimeType, nil
+}
+
+// uploadError keeps the http error of the request body, such as a body over the limit of its route, the storage
+// backend may have wrapped it.
+func uploadError(err error) error {
+	var custom *errmsg.CustomError
+	if errors.As(err, &custom) {
+		return custom
+	}
+
+	return err
+}
+
+// limitedUpload fails with err once more than limit bytes are read.
+type limitedUpload struct {
+	r     io.Reader
+	limit int64
+	read  int64
+	err   error
+}
+
+func (u *limitedUpload) Read(p []byte) (int, error) {
+	if u.exceeded() {
+		return 0, u.err
+	}
+
+	if room := u.limit - u.read + 1; int64(len(p)) > room {
+		p = p[:room]
+	}
+
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+	if u.exceeded() {
+		return 0, u.err
+	}
+
+	return n, err
+}
+
+func (u *limitedUpload) exceeded() bool {
+	return u.read > u.limit
+}
@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"io"
+	"mime/multipart"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// uploadRoutePrefix starts the name of every route accepting uploads, followed by the upload category.
+const uploadRoutePrefix = "upload:"
+
+// multipartEnvelope is the room left for the form fields and boundaries around the uploaded file.
+const multipartEnvelope = 1 << 20
+
+// drainLimit is the most of an unread body read after the handler to keep the connection open, a longer rest
+// closes the connection instead.
+const drainLimit = 64 << 10
+
+// uploadLimitKey holds the body limit of the upload route a request is for.
+const uploadLimitKey = "upload_limit"
+
+var ErrBodyTooLarge = errmsg.NewCustomErrors(413, errmsg.WithMessage("Request body is too large"))
+
+// UploadRoute names a route accepting files of the category, see BodyLimit.
+func UploadRoute(category string) string {
+	return uploadRoutePrefix + category
+}
+
+type uploadRoute struct {
+	method string
+	path   string
+	limit  int
+}
+
+// BodyLimit bounds the body of every request. The server streams request bodies and only buffers the start of
+// them, so the limit is checked here: a route named with UploadRoute accepts the largest file of its category and
+// reads it with MultipartReader as it arrives, every other route accepts limit bytes and reads its body in memory.
+// The routes are looked up on the first request, once they are all registered.
+func BodyLimit(limit int, maxSize func(category string) int64) fiber.Handler {
+	var (
+		once    sync.Once
+		uploads []uploadRoute
+	)
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			uploads = uploadRoutes(c.App().GetRoutes(true), maxSize)
+		})
+
+		// any other body sent to an upload route, such as a submission without files, is read like any other route
+		upload, ok := matchUpload(c, uploads)
+		if ok && len(c.Request().Header.MultipartFormBoundary()) > 0 {
+			if c.Request().Header.ContentLength() > upload.limit {
+				log.Warn().Str("path", c.Path()).Int("limit", upload.limit).Msg("middleware::BodyLimit - Upload is too large")
+				c.Context().SetConnectionClose()
+				return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.Error(ErrBodyTooLarge.Msg))
+			}
+
+			c.Locals(uploadLimitKey, upload.limit)
+			defer drainBody(c)
+			return c.Next()
+		}
+
+		if c.Request().Header.ContentLength() > limit {
+			log.Warn().Str("path", c.Path()).Int("limit", limit).Msg("middleware::BodyLimit - Request body is too large")
+			c.Context().SetConnectionClose()
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.Error(ErrBodyTooLarge.Msg))
+		}
+
+		// a chunked body has no length up front, it is read here so the handler gets it in memory as usual
+		if stream := c.Request().BodyStream(); stream != nil && c.Request().Header.ContentLength() < 0 {
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				log.Warn().Err(err).Str("path", c.Path()).Msg("middleware::BodyLimit - Failed to read request body")
+				c.Context().SetConnectionClose()
+				return c.Status(fiber.StatusBadRequest).JSON(response.Error("Failed to read request body"))
+			}
+
+			if len(body) > limit {
+				log.Warn().Str("path", c.Path()).Int("limit", limit).Msg("middleware::BodyLimit - Request body is too large")
+				c.Context().SetConnectionClose()
+				return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.Error(ErrBodyTooLarge.Msg))
+			}
+
+			c.Request().SetBody(body)
+		}
+
+		return c.Next()
+	}
+}
+
+// drainBody reads what the handler left of a streamed body, the server would read it as the next request of the
+// connection otherwise. A rest too long to be worth reading, such as an upload refused halfway, closes the
+// connection after the response.
+func drainBody(c *fiber.Ctx) {
+	stream := c.Request().BodyStream()
+	if stream == nil {
+		return
+	}
+
+	if _, err := io.CopyN(io.Discard, stream, drainLimit); !errors.Is(err, io.EOF) {
+		c.Context().SetConnectionClose()
+	}
+}
+
+func uploadRoutes(routes []fiber.Route, maxSize func(category string) int64) []uploadRoute {
+	var uploads []uploadRoute
+	for _, route := range routes {
+		category, ok := strings.CutPrefix(route.Name, uploadRoutePrefix)
+		if !ok {
+			continue
+		}
+
+		uploads = append(uploads, uploadRoute{
+			method: route.Method,
+			path:   route.Path,
+			limit:  int(maxSize(category)) + multipartEnvelope,
+		})
+	}
+
+	return uploads
+}
+
+// matchUpload finds the upload route of the request with the route matcher of fiber, the request is not routed yet.
+func matchUpload(c *fiber.Ctx, uploads []uploadRoute) (uploadRoute, bool) {
+	for _, upload := range uploads {
+		if upload.method == c.Method() && fiber.RoutePatternMatch(c.Path(), upload.path, c.App().Config()) {
+			return upload, true
+		}
+	}
+
+	return uploadRoute{}, false
+}
+
+// MultipartReader reads the multipart body of an upload route part by part as it arrives. Reading fails with
+// ErrBodyTooLarge past the limit of the route.
+func MultipartReader(c *fiber.Ctx) (*multipart.Reader, error) {
+	limit, ok := c.Locals(uploadLimitKey).(int)
+	if !ok {
+		return nil, errors.New("middleware: not an upload route")
+	}
+
+	boundary := string(c.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Request body must be multipart/form-data"))
+	}
+
+	body := c.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	return multipart.NewReader(&limitedBody{r: body, left: int64(limit)}, boundary), nil
+}
+
+// FormFile returns the first file sent in the field of a multipart upload, without reading the rest of the body.
+// The file is read from the request as it arrives, it is only valid until the handler returns. The errors are
+// ready to be sent as they are.
+func FormFile(c *fiber.Ctx, field string) (*multipart.Part, error) {
+	form, err := MultipartReader(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := form.NextPart()
+		if errors.Is(err, ErrBodyTooLarge) {
+			return nil, ErrBodyTooLarge
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("field", field).Msg("middleware::FormFile - File not found in form")
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field, field+" is required."))
+		}
+
+		if part.FormName() == field && part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
+
+// MultipartForm is a multipart upload read as it arrives: the fields sent before the first file are read up front,
+// the files are left in the body to be read one at a time.
+type MultipartForm struct {
+	Value map[string]string
+
+	reader *multipart.Reader
+	field  string
+	next   *multipart.Part
+}
+
+// ReadMultipartForm reads the fields of a multipart upload up to its first file in field. The fields must be sent
+// before the files, the way browsers send the fields of a form in order. The errors are ready to be sent as they
+// are.
+func ReadMultipartForm(c *fiber.Ctx, field string) (*MultipartForm, error) {
+	reader, err := MultipartReader(c)
+	if err != nil {
+		return nil, err
+	}
+
+	form := &MultipartForm{Value: make(map[string]string), reader: reader, field: field}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return form, nil
+		}
+		if err != nil {
+			return nil, formError(err)
+		}
+
+		if part.FileName() != "" {
+			form.next = part
+			return form, nil
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, multipartEnvelope+1))
+		if err != nil {
+			return nil, formError(err)
+		}
+
+		if len(value) > multipartEnvelope {
+			log.Warn().Str("field", part.FormName()).Msg("middleware::ReadMultipartForm - Field is too large")
+			return nil, ErrBodyTooLarge
+		}
+
+		form.Value[part.FormName()] = string(value)
+	}
+}
+
+// NextFile returns the next file in the field of the form and io.EOF after the last one, files in other fields are
+// skipped. A file is only valid until the next one is read.
+func (f *MultipartForm) NextFile() (*multipart.Part, error) {
+	for {
+		part := f.next
+		f.next = nil
+
+		if part == nil {
+			var err error
+			if part, err = f.reader.NextPart(); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, io.EOF
+				}
+				return nil, formError(err)
+			}
+		}
+
+		if part.FileName() == "" {
+			log.Warn().Str("field", part.FormName()).Msg("middleware::MultipartForm-NextFile - Field sent after a file")
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(part.FormName(), part.FormName()+" must be sent before the files."))
+		}
+
+		if part.FormName() == f.field {
+			return part, nil
+		}
+	}
+}
+
+func formError(err error) error {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return ErrBodyTooLarge
+	}
+
+	log.Warn().Err(err).Msg("middleware::MultipartForm - Failed to read multipart form")
+	return errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to read multipart form"))
+}
+
+// limitedBody fails with ErrBodyTooLarge once more than left bytes are read.
+type limitedBody struct {
+	r    io.Reader
+	left int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+
+	n, err := b.r.Read(p)
+	b.left -= int64(n)
+	if b.left < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"hacko-app/pkg/errmsg"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBodyLimit = 1 << 10
+
+func newBodyLimitApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	app := fiber.New(fiber.Config{
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		BodyLimit:                    testBodyLimit,
+	})
+	app.Use(BodyLimit(testBodyLimit, func(category string) int64 {
+		if category == "cover" {
+			return 2 << 20
+		}
+		return 0
+	}))
+
+	app.Put("/class/:id/cover", func(c *fiber.Ctx) error {
+		file, err := FormFile(c, "image")
+		if err != nil {
+			code, _ := errmsg.Errors[error](err)
+			return c.SendStatus(code)
+		}
+
+		n, err := io.Copy(io.Discard, file)
+		if err != nil {
+			code, _ := errmsg.Errors[error](err)
+			return c.SendStatus(code)
+		}
+
+		return c.SendString(file.FileName() + ":" + strconv.FormatInt(n, 10))
+	}).Name(UploadRoute("cover"))
+
+	app.Post("/class/:id/submission", func(c *fiber.Ctx) error {
+		form, err := ReadMultipartForm(c, "files")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
+
+		names := []string{form.Value["answer"]}
+		for {
+			file, err := form.NextFile()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+			}
+			names = append(names, file.FileName())
+		}
+
+		return c.SendString(strings.Join(names, ","))
+	}).Name(UploadRoute("cover"))
+
+	app.Post("/echo", func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+
+	return app
+}
+
+type formPart struct {
+	field, filename string
+	content         []byte
+}
+
+func multipartBody(t *testing.T, parts ...formPart) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var (
+		body   = new(bytes.Buffer)
+		writer = multipart.NewWriter(body)
+	)
+
+	for _, part := range parts {
+		var (
+			w   io.Writer
+			err error
+		)
+
+		if part.filename != "" {
+			w, err = writer.CreateFormFile(part.field, part.filename)
+		} else {
+			w, err = writer.CreateFormField(part.field)
+		}
+		require.NoError(t, err)
+
+		_, err = w.Write(part.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return body, writer.FormDataContentType()
+}
+
+func send(t *testing.T, app *fiber.App, req *http.Request) (int, string) {
+	t.Helper()
+
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(body)
+}
+
+func chunked(req *http.Request) *http.Request {
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	return req
+}
+
+func TestBodyLimitUploadStreamsFile(t *testing.T) {
+	app := newBodyLimitApp(t)
+
+	// far larger than the default limit, the file is read by the handler as it arrives
+	body, contentType := multipartBody(t, formPart{field: "image", filename: "cover.png", content: bytes.Repeat([]byte("a"), 1<<20)})
+	req := httptest.NewRequest(http.MethodPut, "/class/12/cover", body)
+	req.Header.Set("Content-Type", contentType)
+
+	code, res := send(t, app, req)
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "cover.png:1048576", res)
+}
+
+func TestBodyLimitUploadTooLarge(t *testing.T) {
+	app := newBodyLimitApp(t)
+
+	content := bytes.Repeat([]byte("a"), 3<<20+1)
+
+	body, contentType := multipartBody(t, formPart{field: "image", filename: "cover.png", content: content})
+	req := httptest.NewRequest(http.MethodPut, "/class/12/cover", body)
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, res.StatusCode, "refused by its length")
+	assert.True(t, res.Close, "the unread body closes the connection")
+
+	body, contentType = multipartBody(t, formPart{field: "image", filename: "cover.png", content: content})
+	req = chunked(httptest.NewRequest(http.MethodPut, "/class/12/cover", body))
+	req.Header.Set("Content-Type", contentType)
+
+	code, _ := send(t, app, req)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, code, "refused while it is read")
+}
+
+func TestBodyLimitMatchesRoutePattern(t *testing.T) {
+	app := newBodyLimitApp(t)
+
+	body, contentType := multipartBody(t, formPart{field: "image", filename: "cover.png", content: bytes.Repeat([]byte("a"), 2*testBodyLimit)})
+	req := httptest.NewRequest(http.MethodPost, "/class/12/cover", body)
+	req.Header.Set("Content-Type", contentType)
+
+	// the method is part of the route, so the default limit applies
+	code, _ := send(t, app, req)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, code)
+}
+
+func TestBodyLimitOtherRoutes(t *testing.T) {
+	app := newBodyLimitApp(t)
+
+	code, res := send(t, app, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("hello")))
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "hello", res)
+
+	code, res = send(t, app, chunked(httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("hello"))))
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "hello", res)
+
+	large := strings.Repeat("a", testBodyLimit+1)
+
+	code, _ = send(t, app, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(large)))
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, code)
+
+	code, _ = send(t, app, chunked(httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(large))))
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, code)
+}
+
+func TestReadMultipartForm(t *testing.T) {
+	app := newBodyLimitApp(t)
+
+	body, contentType := multipartBody(t,
+		formPart{field: "answer", content: []byte("42")},
+		formPart{field: "files", filename: "a.txt", content: []byte("a")},
+		formPart{field: "other", filename: "skipped.txt", content: []byte("b")},
+		formPart{field: "files", filename: "b.txt", content: []byte("c")},
+	)
+	req := httptest.NewRequest(http.MethodPost, "/class/12/submission", body)
+	req.Header.Set("Content-Type", contentType)
+
+	code, res := send(t, app, req)
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "42,a.txt,b.txt", res)
+
+	body, contentType = multipartBody(t,
+		formPart{field: "files", filename: "a.txt", content: []byte("a")},
+		formPart{field: "answer", content: []byte("42")},
+	)
+	req = httptest.NewRequest(http.MethodPost, "/class/12/submission", body)
+	req.Header.Set("Content-Type", contentType)
+
+	code, _ = send(t, app, req)
+	assert.Equal(t, fiber.StatusBadRequest, code, "fields are sent before the files")
+}
@@ -1,11 +1,8 @@
 package middleware
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-	"hacko-app/internal/infrastructure/config"
+	storagemanager "hacko-app/pkg/storage-manager"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -29,14 +26,9 @@ func ValidateSignedURL(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrUrlNotValid)
 	}
 
-	// Recreate the original data and signature
-	data := fmt.Sprintf("%s%d", c.BaseURL()+c.Path(), expires)
-	h := hmac.New(sha256.New, []byte(config.Envs.Guard.JwtPrivateKey))
-	h.Write([]byte(data))
-	expectedSignature := hex.EncodeToString(h.Sum(nil))
-
-	// Compare the signatures
-	if !hmac.Equal([]byte(expectedSignature), []byte(signature)) {
+	// The signature covers the unescaped filename, the same way the handler reads it
+	filename, err := url.PathUnescape(c.Params("*"))
+	if err != nil || !storagemanager.VerifySignedURL(filename, expires, signature) {
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrUrlNotValid)
 	}
 
@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+// rules an achievement is awarded by, the value of each one is counted per student
+const (
+	RuleModulesCompleted  = "modules_completed"   // modules done
+	RuleClassesCompleted  = "classes_completed"   // enrollments completed
+	RuleQuizPerfectScores = "quiz_perfect_scores" // quizzes taken with every point
+	RuleAssignmentsOnTime = "assignments_on_time" // graded assignments submitted without late days
+	RuleLearningStreak    = "learning_streak"     // longest run of days in a row with learning activity
+)
+
+type Achievement struct {
+	Id          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	Icon        string    `json:"icon" db:"icon"`
+	Rule        string    `json:"rule" db:"rule"`
+	Threshold   int       `json:"threshold" db:"threshold"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateAchievementRequest struct {
+	Code        string `json:"code" validate:"required,max=64"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Icon        string `json:"icon" validate:"max=255"`
+	Rule        string `json:"rule" validate:"required,oneof=modules_completed classes_completed quiz_perfect_scores assignments_on_time learning_streak"`
+	Threshold   int    `json:"threshold" validate:"required,gt=0"`
+}
+
+// UpdateAchievementRequest changes an achievement for the students who have not earned it yet, awarded ones are
+// kept.
+type UpdateAchievementRequest struct {
+	Id          int    `json:"id" validate:"required"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Icon        string `json:"icon" validate:"max=255"`
+	Rule        string `json:"rule" validate:"required,oneof=modules_completed classes_completed quiz_perfect_scores assignments_on_time learning_streak"`
+	Threshold   int    `json:"threshold" validate:"required,gt=0"`
+	IsActive    bool   `json:"is_active"`
+}
+
+// AdminAchievement is an achievement with the number of students who earned it.
+type AdminAchievement struct {
+	Achievement
+	Awarded int `json:"awarded" db:"awarded"`
+}
+
+type GetAchievementsRequest struct {
+	UserId string `validate:"required"`
+}
+
+// UserAchievement is an achievement as seen by a student, Progress is the value of its rule for them and stops at
+// the threshold.
+type UserAchievement struct {
+	Id          int        `json:"id" db:"id"`
+	Code        string     `json:"code" db:"code"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description" db:"description"`
+	Icon        string     `json:"icon" db:"icon"`
+	Rule        string     `json:"rule" db:"rule"`
+	Threshold   int        `json:"threshold" db:"threshold"`
+	Earned      bool       `json:"earned" db:"earned"`
+	AwardedAt   *time.Time `json:"awarded_at" db:"awarded_at"`
+	Progress    int        `json:"progress" db:"-"`
+}
+
+// EvaluateRequest checks the achievements of Rules for a student, or for the student and team members of a graded
+// submission. Activity records today as a learning day of the student first, which also checks the streak rule.
+type EvaluateRequest struct {
+	UserId       string
+	SubmissionId int
+	Rules        []string
+	Activity     bool
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/module/achievement/entity"
+	"hacko-app/internal/module/achievement/ports"
+	"hacko-app/internal/module/achievement/repository"
+	"hacko-app/internal/module/achievement/service"
+	"hacko-app/pkg/eventbus"
+)
+
+type achievementHandler struct {
+	service ports.AchievementService
+}
+
+func NewAchievementHandler() *achievementHandler {
+	var handler = new(achievementHandler)
+
+	repo := repository.NewAchievementRepository(adapter.Adapters.HackoPostgres)
+	achievementService := service.NewAchievementService(repo)
+
+	handler.service = achievementService
+	return handler
+}
+
+func (h *achievementHandler) Subscribe(bus *eventbus.Bus) {
+	bus.Subscribe(eventbus.ModuleProgressed, h.ModuleProgressed)
+	bus.Subscribe(eventbus.QuizSubmitted, h.QuizSubmitted)
+	bus.Subscribe(eventbus.SubmissionGraded, h.SubmissionGraded)
+}
+
+func (h *achievementHandler) ModuleProgressed(ctx context.Context, event eventbus.Event) error {
+	e, ok := event.(eventbus.ModuleProgressedEvent)
+	if !ok {
+		return fmt.Errorf("handler::ModuleProgressed - unexpected event %T", event)
+	}
+
+	req := &entity.EvaluateRequest{UserId: e.UserId, Activity: true}
+
+	// watching without finishing the module only counts for the streak
+	if e.Status == "done" {
+		req.Rules = []string{entity.RuleModulesCompleted, entity.RuleClassesCompleted}
+	}
+
+	return h.service.Evaluate(ctx, req)
+}
+
+func (h *achievementHandler) QuizSubmitted(ctx context.Context, event eventbus.Event) error {
+	e, ok := event.(eventbus.QuizSubmittedEvent)
+	if !ok {
+		return fmt.Errorf("handler::QuizSubmitted - unexpected event %T", event)
+	}
+
+	return h.service.Evaluate(ctx, &entity.EvaluateRequest{
+		UserId:   e.UserId,
+		Rules:    []string{entity.RuleQuizPerfectScores, entity.RuleClassesCompleted},
+		Activity: true,
+	})
+}
+
+// SubmissionGraded evaluates the students of the submission, grading is done by the teacher so it is no learning
+// activity of theirs.
+func (h *achievementHandler) SubmissionGraded(ctx context.Context, event eventbus.Event) error {
+	e, ok := event.(eventbus.SubmissionGradedEvent)
+	if !ok {
+		return fmt.Errorf("handler::SubmissionGraded - unexpected event %T", event)
+	}
+
+	return h.service.Evaluate(ctx, &entity.EvaluateRequest{
+		SubmissionId: e.SubmissionId,
+		Rules:        []string{entity.RuleAssignmentsOnTime, entity.RuleClassesCompleted},
+	})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/achievement/entity"
+	"hacko-app/internal/module/achievement/ports"
+	"hacko-app/internal/module/achievement/repository"
+	"hacko-app/internal/module/achievement/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type achievementHandler struct {
+	service ports.AchievementService
+}
+
+func NewAchievementHandler() *achievementHandler {
+	var handler = new(achievementHandler)
+
+	repo := repository.NewAchievementRepository(adapter.Adapters.HackoPostgres)
+	achievementService := service.NewAchievementService(repo)
+
+	handler.service = achievementService
+	return handler
+}
+
+func (h *achievementHandler) Register(router fiber.Router) {
+	// user routes
+	router.Get("/achievements", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetAchievements)
+
+	// admin routes
+	router.Get("/admin/achievements", middleware.AuthMiddleware, middleware.AuthRole([]string{"admin"}), h.GetAllAchievements)
+	router.Post("/achievements", middleware.AuthMiddleware, middleware.AuthRole([]string{"admin"}), h.CreateAchievement)
+	router.Put("/achievements/:id", middleware.AuthMiddleware, middleware.AuthRole([]string{"admin"}), h.UpdateAchievement)
+}
+
+func (h *achievementHandler) GetAchievements(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetAchievementsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetAchievements - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetAchievements(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *achievementHandler) GetAllAchievements(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	res, err := h.service.GetAllAchievements(ctx)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *achievementHandler) CreateAchievement(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateAchievementRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateAchievement - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateAchievement - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateAchievement(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *achievementHandler) UpdateAchievement(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateAchievementRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAchievement - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAchievement - Failed to parse id achievement")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id achievement"))))
+	}
+
+	req.Id = id
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAchievement - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateAchievement(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/achievement/entity"
+)
+
+type AchievementRepository interface {
+	// admin contract
+	CreateAchievement(ctx context.Context, req *entity.CreateAchievementRequest) (*entity.Achievement, error)
+	UpdateAchievement(ctx context.Context, req *entity.UpdateAchievementRequest) (*entity.Achievement, error)
+	GetAllAchievements(ctx context.Context) ([]entity.AdminAchievement, error)
+
+	// users contract
+	GetUserAchievements(ctx context.Context, userId string) ([]entity.UserAchievement, error)
+
+	// events contract
+	GetSubmissionStudents(ctx context.Context, submissionId int) ([]string, error)
+	RecordActivity(ctx context.Context, userId string) (bool, error)
+	GetPendingAchievements(ctx context.Context, userId string, rules []string) ([]entity.Achievement, error)
+	GetRuleValue(ctx context.Context, rule string, userId string) (int, error)
+	AwardAchievement(ctx context.Context, userId string, achievementId int) (bool, error)
+}
+
+type AchievementService interface {
+	// admin contract
+	CreateAchievement(ctx context.Context, req *entity.CreateAchievementRequest) (*entity.Achievement, error)
+	UpdateAchievement(ctx context.Context, req *entity.UpdateAchievementRequest) (*entity.Achievement, error)
+	GetAllAchievements(ctx context.Context) ([]entity.AdminAchievement, error)
+
+	// users contract
+	GetAchievements(ctx context.Context, req *entity.GetAchievementsRequest) ([]entity.UserAchievement, error)
+
+	// events contract
+	Evaluate(ctx context.Context, req *entity.EvaluateRequest) error
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hacko-app/internal/module/achievement/entity"
+	"hacko-app/internal/module/achievement/ports"
+	"hacko-app/pkg/errmsg"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.AchievementRepository = &achievementRepository{}
+
+type achievementRepository struct {
+	db *sqlx.DB
+}
+
+func NewAchievementRepository(db *sqlx.DB) *achievementRepository {
+	return &achievementRepository{
+		db: db,
+	}
+}
+
+// ruleQueries count the value of a rule for the user given as $1.
+var ruleQueries = map[string]string{
+	entity.RuleModulesCompleted: `
+		SELECT COUNT(*) FROM users_progress WHERE user_id = $1 AND status = 'done'
+	`,
+	entity.RuleClassesCompleted: `
+		SELECT COUNT(*) FROM users_classes WHERE user_id = $1 AND enrollment_status = 'completed'
+	`,
+	entity.RuleQuizPerfectScores: `
+		SELECT COUNT(DISTINCT quiz_id) FROM users_completed_quiz WHERE user_id = $1 AND max_score > 0 AND score >= max_score
+	`,
+	entity.RuleAssignmentsOnTime: `
+		SELECT COUNT(DISTINCT s.assignment_id)
+		FROM submissions s
+		LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = $1
+		WHERE s.is_active AND s.status = 'rated' AND s.late_days = 0
+			AND (s.student_id = $1 OR sm.student_id IS NOT NULL)
+	`,
+	// days in a row share the same difference between the day and its rank
+	entity.RuleLearningStreak: `
+		SELECT COALESCE(MAX(days), 0)
+		FROM (
+			SELECT COUNT(*) AS days
+			FROM (
+				SELECT day - (ROW_NUMBER() OVER (ORDER BY day))::INT AS run
+				FROM user_activity_days
+				WHERE user_id = $1
+			) d
+			GROUP BY run
+		) r
+	`,
+}
+
+const achievementColumns = `a.id, a.code, a.title, a.description, a.icon, a.rule, a.threshold, a.is_active, a.created_at, a.updated_at`
+
+func (r *achievementRepository) CreateAchievement(ctx context.Context, req *entity.CreateAchievementRequest) (*entity.Achievement, error) {
+	var res entity.Achievement
+
+	query := `
+		INSERT INTO achievements AS a (code, title, description, icon, rule, threshold)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + achievementColumns
+
+	if err := r.db.GetContext(ctx, &res, query, req.Code, req.Title, req.Description, req.Icon, req.Rule, req.Threshold); err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code.Name() == "unique_violation" {
+			log.Warn().Any("payload", req).Msg("repo::CreateAchievement - Achievement code already exists")
+			return nil, errmsg.NewCustomErrors(409, errmsg.WithErrors("code", "code is already used by another achievement."))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateAchievement - Failed to create achievement")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *achievementRepository) UpdateAchievement(ctx context.Context, req *entity.UpdateAchievementRequest) (*entity.Achievement, error) {
+	var res entity.Achievement
+
+	query := `
+		UPDATE achievements AS a
+		SET title = $2, description = $3, icon = $4, rule = $5, threshold = $6, is_active = $7, updated_at = NOW()
+		WHERE a.id = $1
+		RETURNING ` + achievementColumns
+
+	if err := r.db.GetContext(ctx, &res, query, req.Id, req.Title, req.Description, req.Icon, req.Rule, req.Threshold, req.IsActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateAchievement - Achievement not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Achievement not found"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateAchievement - Failed to update achievement")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *achievementRepository) GetAllAchievements(ctx context.Context) ([]entity.AdminAchievement, error) {
+	var res = make([]entity.AdminAchievement, 0)
+
+	query := `
+		SELECT ` + achievementColumns + `, COUNT(ua.user_id) AS awarded
+		FROM achievements a
+		LEFT JOIN user_achievements ua ON ua.achievement_id = a.id
+		GROUP BY a.id
+		ORDER BY a.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetAllAchievements - Failed to get achievements")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetUserAchievements returns the active achievements and the inactive ones the user earned before, earned ones
+// first.
+func (r *achievementRepository) GetUserAchievements(ctx context.Context, userId string) ([]entity.UserAchievement, error) {
+	var res = make([]entity.UserAchievement, 0)
+
+	query := `
+		SELECT
+			a.id, a.code, a.title, a.description, a.icon, a.rule, a.threshold,
+			ua.user_id IS NOT NULL AS earned, ua.awarded_at
+		FROM achievements a
+		LEFT JOIN user_achievements ua ON ua.achievement_id = a.id AND ua.user_id = $1
+		WHERE a.is_active OR ua.user_id IS NOT NULL
+		ORDER BY ua.awarded_at DESC NULLS LAST, a.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetUserAchievements - Failed to get achievements")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetSubmissionStudents returns the student of a submission and its team members.
+func (r *achievementRepository) GetSubmissionStudents(ctx context.Context, submissionId int) ([]string, error) {
+	var res = make([]string, 0)
+
+	query := `
+		SELECT s.student_id FROM submissions s WHERE s.id = $1
+		UNION
+		SELECT sm.student_id FROM submission_members sm WHERE sm.submission_id = $1
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSubmissionStudents - Failed to get students")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RecordActivity marks today as a learning day of the user, it reports whether it was the first activity today.
+func (r *achievementRepository) RecordActivity(ctx context.Context, userId string) (bool, error) {
+	query := `
+		INSERT INTO user_activity_days (user_id, day)
+		VALUES ($1, CURRENT_DATE)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userId)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::RecordActivity - Failed to record activity")
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// GetPendingAchievements returns the active achievements of the rules the user has not earned yet.
+func (r *achievementRepository) GetPendingAchievements(ctx context.Context, userId string, rules []string) ([]entity.Achievement, error) {
+	var res = make([]entity.Achievement, 0)
+
+	query := `
+		SELECT ` + achievementColumns + `
+		FROM achievements a
+		WHERE a.is_active AND a.rule::TEXT = ANY($2)
+			AND NOT EXISTS (SELECT 1 FROM user_achievements ua WHERE ua.achievement_id = a.id AND ua.user_id = $1)
+		ORDER BY a.threshold, a.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId, pq.Array(rules)); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Strs("rules", rules).Msg("repo::GetPendingAchievements - Failed to get achievements")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *achievementRepository) GetRuleValue(ctx context.Context, rule string, userId string) (int, error) {
+	var res int
+
+	query, ok := ruleQueries[rule]
+	if !ok {
+		return 0, fmt.Errorf("unknown achievement rule %q", rule)
+	}
+
+	if err := r.db.GetContext(ctx, &res, query, userId); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Str("rule", rule).Msg("repo::GetRuleValue - Failed to count rule value")
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// AwardAchievement gives the achievement to the user, it reports false when they already had it.
+func (r *achievementRepository) AwardAchievement(ctx context.Context, userId string, achievementId int) (bool, error) {
+	query := `
+		INSERT INTO user_achievements (user_id, achievement_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userId, achievementId)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userId).Int("achievement_id", achievementId).Msg("repo::AwardAchievement - Failed to award achievement")
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"hacko-app/internal/module/achievement/entity"
+	"hacko-app/internal/module/achievement/ports"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.AchievementService = &achievementService{}
+
+type achievementService struct {
+	repo ports.AchievementRepository
+}
+
+func NewAchievementService(repo ports.AchievementRepository) *achievementService {
+	return &achievementService{
+		repo: repo,
+	}
+}
+
+func (s *achievementService) CreateAchievement(ctx context.Context, req *entity.CreateAchievementRequest) (*entity.Achievement, error) {
+	return s.repo.CreateAchievement(ctx, req)
+}
+
+func (s *achievementService) UpdateAchievement(ctx context.Context, req *entity.UpdateAchievementRequest) (*entity.Achievement, error) {
+	return s.repo.UpdateAchievement(ctx, req)
+}
+
+func (s *achievementService) GetAllAchievements(ctx context.Context) ([]entity.AdminAchievement, error) {
+	return s.repo.GetAllAchievements(ctx)
+}
+
+// GetAchievements returns the achievements with the progress of the user towards the ones not earned yet.
+func (s *achievementService) GetAchievements(ctx context.Context, req *entity.GetAchievementsRequest) ([]entity.UserAchievement, error) {
+	achievements, err := s.repo.GetUserAchievements(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int)
+	for i := range achievements {
+		achievement := &achievements[i]
+		if achievement.Earned {
+			achievement.Progress = achievement.Threshold
+			continue
+		}
+
+		value, ok := values[achievement.Rule]
+		if !ok {
+			value, err = s.repo.GetRuleValue(ctx, achievement.Rule, req.UserId)
+			if err != nil {
+				return nil, err
+			}
+			values[achievement.Rule] = value
+		}
+
+		achievement.Progress = min(value, achievement.Threshold)
+	}
+
+	return achievements, nil
+}
+
+// Evaluate awards the achievements of the rules the users reached the threshold of. Rule values are counted from
+// the stored data rather than from the event, so a missed event is made up for by the next one, and an achievement
+// created later is awarded on the next event of its rule.
+func (s *achievementService) Evaluate(ctx context.Context, req *entity.EvaluateRequest) error {
+	users := []string{req.UserId}
+	if req.UserId == "" {
+		students, err := s.repo.GetSubmissionStudents(ctx, req.SubmissionId)
+		if err != nil {
+			return err
+		}
+		users = students
+	}
+
+	for _, userId := range users {
+		if err := s.evaluateUser(ctx, userId, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *achievementService) evaluateUser(ctx context.Context, userId string, req *entity.EvaluateRequest) error {
+	rules := slices.Clone(req.Rules)
+
+	// the streak only grows with the first activity of a day
+	if req.Activity {
+		firstToday, err := s.repo.RecordActivity(ctx, userId)
+		if err != nil {
+			return err
+		}
+		if firstToday {
+			rules = append(rules, entity.RuleLearningStreak)
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	pending, err := s.repo.GetPendingAchievements(ctx, userId, rules)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]int)
+	for _, achievement := range pending {
+		value, ok := values[achievement.Rule]
+		if !ok {
+			value, err = s.repo.GetRuleValue(ctx, achievement.Rule, userId)
+			if err != nil {
+				return err
+			}
+			values[achievement.Rule] = value
+		}
+
+		if value < achievement.Threshold {
+			continue
+		}
+
+		// awarding is idempotent, a concurrent event for the same user awards it once
+		awarded, err := s.repo.AwardAchievement(ctx, userId, achievement.Id)
+		if err != nil {
+			return err
+		}
+		if awarded {
+			log.Info().Str("user_id", userId).Str("achievement", achievement.Code).Msg("service::Evaluate - Achievement awarded")
+		}
+	}
+
+	return nil
+}
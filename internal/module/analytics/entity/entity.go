@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// GetClassAnalyticsRequest covers the days From to To, both included, the last 30 days when left out. Students
+// without activity for InactiveDays are at risk.
+type GetClassAnalyticsRequest struct {
+	UserId       string `validate:"required"`
+	ClassId      int    `json:"class_id" validate:"required"`
+	From         string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To           string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	InactiveDays int    `query:"inactive_days" validate:"omitempty,min=1,max=365"`
+}
+
+// AnalyticsSummary totals the period, EnrolledStudents counts the active and completed enrollments of today.
+type AnalyticsSummary struct {
+	EnrolledStudents     int      `json:"enrolled_students" db:"enrolled_students"`
+	ActiveStudents       int      `json:"active_students" db:"active_students"`
+	ModulesCompleted     int      `json:"modules_completed" db:"modules_completed"`
+	QuizAttempts         int      `json:"quiz_attempts" db:"quiz_attempts"`
+	Submissions          int      `json:"submissions" db:"submissions"`
+	Completions          int      `json:"completions" db:"completions"`
+	AvgCompletionSeconds *float64 `json:"avg_completion_seconds" db:"avg_completion_seconds"`
+}
+
+type DailyActivity struct {
+	Day              string `json:"day" db:"day"`
+	ActiveStudents   int    `json:"active_students" db:"active_students"`
+	ModulesCompleted int    `json:"modules_completed" db:"modules_completed"`
+	QuizAttempts     int    `json:"quiz_attempts" db:"quiz_attempts"`
+	Submissions      int    `json:"submissions" db:"submissions"`
+}
+
+// FunnelStep is a published module in syllabus order. DropOff counts the students who completed the step before,
+// or started this one for the first step, without completing this one in the period.
+type FunnelStep struct {
+	MaterialId           int      `json:"material_id" db:"material_id"`
+	MaterialTitle        string   `json:"material_title" db:"material_title"`
+	ModuleId             int      `json:"module_id" db:"module_id"`
+	ModuleTitle          string   `json:"module_title" db:"module_title"`
+	Started              int      `json:"started" db:"started"`
+	Completed            int      `json:"completed" db:"completed"`
+	AvgCompletionSeconds *float64 `json:"avg_completion_seconds" db:"avg_completion_seconds"`
+	DropOff              int      `json:"drop_off" db:"-"`
+	DropOffRate          float64  `json:"drop_off_rate" db:"-"` // percent
+}
+
+// AtRiskStudent is an active enrollment without activity for the inactive days or with missing assignments,
+// LastActiveAt is empty for students who never did anything in the class.
+type AtRiskStudent struct {
+	UserId             string     `json:"user_id" db:"user_id"`
+	Name               string     `json:"name" db:"name"`
+	Email              string     `json:"email" db:"email"`
+	EnrolledAt         time.Time  `json:"enrolled_at" db:"enrolled_at"`
+	LastActiveAt       *time.Time `json:"last_active_at" db:"last_active_at"`
+	InactiveDays       int        `json:"inactive_days" db:"inactive_days"`
+	Progress           float64    `json:"progress" db:"progress"`
+	MissingAssignments int        `json:"missing_assignments" db:"missing_assignments"`
+	Inactive           bool       `json:"inactive" db:"inactive"`
+}
+
+type GetClassAnalyticsResponse struct {
+	ClassId       int              `json:"class_id"`
+	From          string           `json:"from"`
+	To            string           `json:"to"`
+	InactiveDays  int              `json:"inactive_days"`
+	Summary       AnalyticsSummary `json:"summary"`
+	DailyActivity []DailyActivity  `json:"daily_activity"`
+	Funnel        []FunnelStep     `json:"funnel"`
+	DropOffs      []FunnelStep     `json:"drop_offs"`
+	AtRisk        []AtRiskStudent  `json:"at_risk_students"`
+}
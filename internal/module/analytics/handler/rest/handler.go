@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/analytics/entity"
+	"hacko-app/internal/module/analytics/ports"
+	"hacko-app/internal/module/analytics/repository"
+	"hacko-app/internal/module/analytics/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type analyticsHandler struct {
+	service ports.AnalyticsService
+}
+
+func NewAnalyticsHandler() *analyticsHandler {
+	var handler = new(analyticsHandler)
+
+	repo := repository.NewAnalyticsRepository(adapter.Adapters.HackoPostgres)
+	analyticsService := service.NewAnalyticsService(repo)
+
+	handler.service = analyticsService
+	return handler
+}
+
+func (h *analyticsHandler) Register(router fiber.Router) {
+	// teacher routes
+	router.Get("/teacher/class/:classId/analytics", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetClassAnalytics)
+}
+
+func (h *analyticsHandler) GetClassAnalytics(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetClassAnalyticsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetClassAnalytics - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid query"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetClassAnalytics - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetClassAnalytics - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetClassAnalytics(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
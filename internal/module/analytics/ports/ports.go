@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/analytics/entity"
+	"time"
+)
+
+type AnalyticsRepository interface {
+	// teacher contract
+	FindClassByCreator(ctx context.Context, classId int, userId string) error
+	GetSummary(ctx context.Context, classId int, from, to time.Time) (*entity.AnalyticsSummary, error)
+	GetDailyActivity(ctx context.Context, classId int, from, to time.Time) ([]entity.DailyActivity, error)
+	GetFunnel(ctx context.Context, classId int, from, to time.Time) ([]entity.FunnelStep, error)
+	GetAtRiskStudents(ctx context.Context, classId int, inactiveDays int) ([]entity.AtRiskStudent, error)
+}
+
+type AnalyticsService interface {
+	// teacher contract
+	GetClassAnalytics(ctx context.Context, req *entity.GetClassAnalyticsRequest) (*entity.GetClassAnalyticsResponse, error)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"hacko-app/internal/module/analytics/entity"
+	"hacko-app/internal/module/analytics/ports"
+	classRepo "hacko-app/internal/module/class/repository"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.AnalyticsRepository = &analyticsRepository{}
+
+type analyticsRepository struct {
+	db *sqlx.DB
+}
+
+func NewAnalyticsRepository(db *sqlx.DB) *analyticsRepository {
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+// dateLayout is how days are passed to the queries, so the database time zone cannot shift them.
+const dateLayout = "2006-01-02"
+
+// FindClassByCreator is the ownership check of the class module.
+func (r *analyticsRepository) FindClassByCreator(ctx context.Context, classId int, userId string) error {
+	return classRepo.FindClassByCreator(ctx, r.db, classId, userId)
+}
+
+func (r *analyticsRepository) GetSummary(ctx context.Context, classId int, from, to time.Time) (*entity.AnalyticsSummary, error) {
+	var res entity.AnalyticsSummary
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users_classes uc WHERE uc.class_id = $1 AND uc.enrollment_status IN ('active', 'completed')) AS enrolled_students,
+			a.active_students, a.modules_completed, a.quiz_attempts, a.submissions,
+			ct.completions, ct.avg_completion_seconds
+		FROM (
+			SELECT
+				COUNT(DISTINCT d.user_id) AS active_students,
+				COALESCE(SUM(d.modules_completed), 0) AS modules_completed,
+				COALESCE(SUM(d.quiz_attempts), 0) AS quiz_attempts,
+				COALESCE(SUM(d.submissions), 0) AS submissions
+			FROM class_daily_activity d
+			WHERE d.class_id = $1 AND d.day BETWEEN $2::DATE AND $3::DATE
+		) a
+		CROSS JOIN (
+			SELECT COUNT(*) AS completions, ROUND(AVG(x.seconds))::FLOAT8 AS avg_completion_seconds
+			FROM class_completion_times x
+			WHERE x.class_id = $1 AND x.completed_at::DATE BETWEEN $2::DATE AND $3::DATE
+		) ct
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, classId, from.Format(dateLayout), to.Format(dateLayout)); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetSummary - Failed to get summary")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetDailyActivity returns every day of the period, days without activity included.
+func (r *analyticsRepository) GetDailyActivity(ctx context.Context, classId int, from, to time.Time) ([]entity.DailyActivity, error) {
+	var res = make([]entity.DailyActivity, 0)
+
+	query := `
+		SELECT
+			TO_CHAR(g.day, 'YYYY-MM-DD') AS day,
+			COUNT(d.user_id) AS active_students,
+			COALESCE(SUM(d.modules_completed), 0) AS modules_completed,
+			COALESCE(SUM(d.quiz_attempts), 0) AS quiz_attempts,
+			COALESCE(SUM(d.submissions), 0) AS submissions
+		FROM generate_series($2::DATE, $3::DATE, INTERVAL '1 day') AS g(day)
+		LEFT JOIN class_daily_activity d ON d.class_id = $1 AND d.day = g.day::DATE
+		GROUP BY g.day
+		ORDER BY g.day
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId, from.Format(dateLayout), to.Format(dateLayout)); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetDailyActivity - Failed to get daily activity")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetFunnel returns the published modules of the class in syllabus order with the students who started and
+// completed them in the period.
+func (r *analyticsRepository) GetFunnel(ctx context.Context, classId int, from, to time.Time) ([]entity.FunnelStep, error) {
+	var res = make([]entity.FunnelStep, 0)
+
+	query := `
+		SELECT
+			mat.id AS material_id, mat.title AS material_title, m.id AS module_id, m.title AS module_title,
+			COALESCE(SUM(s.started), 0) AS started,
+			COALESCE(SUM(s.completed), 0) AS completed,
+			ROUND(SUM(s.completion_seconds) / NULLIF(SUM(s.completed), 0))::FLOAT8 AS avg_completion_seconds
+		FROM materials mat
+		JOIN modules m ON m.materials_id = mat.id AND m.status = 'published'
+		LEFT JOIN class_module_daily_stats s ON s.module_id = m.id AND s.day BETWEEN $2::DATE AND $3::DATE
+		WHERE mat.class_id = $1
+		GROUP BY mat.id, m.id
+		ORDER BY mat.id, m.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId, from.Format(dateLayout), to.Format(dateLayout)); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetFunnel - Failed to get module funnel")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetAtRiskStudents returns the students with missing assignments first, then the ones inactive the longest.
+func (r *analyticsRepository) GetAtRiskStudents(ctx context.Context, classId int, inactiveDays int) ([]entity.AtRiskStudent, error) {
+	var res = make([]entity.AtRiskStudent, 0)
+
+	query := `
+		SELECT
+			e.user_id, e.name, e.email, e.enrolled_at, e.last_active_at, e.progress, e.missing_assignments,
+			EXTRACT(DAY FROM NOW() - COALESCE(e.last_active_at, e.enrolled_at))::INT AS inactive_days,
+			COALESCE(e.last_active_at, e.enrolled_at) < NOW() - MAKE_INTERVAL(days => $2) AS inactive
+		FROM class_student_engagement e
+		WHERE e.class_id = $1
+			AND (COALESCE(e.last_active_at, e.enrolled_at) < NOW() - MAKE_INTERVAL(days => $2) OR e.missing_assignments > 0)
+		ORDER BY e.missing_assignments DESC, COALESCE(e.last_active_at, e.enrolled_at), e.name
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId, inactiveDays); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Int("inactive_days", inactiveDays).Msg("repo::GetAtRiskStudents - Failed to get at-risk students")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"hacko-app/internal/module/analytics/entity"
+	"hacko-app/internal/module/analytics/ports"
+	"hacko-app/pkg/errmsg"
+	"math"
+	"sort"
+	"time"
+)
+
+var _ ports.AnalyticsService = &analyticsService{}
+
+type analyticsService struct {
+	repo ports.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo ports.AnalyticsRepository) *analyticsService {
+	return &analyticsService{
+		repo: repo,
+	}
+}
+
+const (
+	dateLayout          = "2006-01-02"
+	defaultPeriodDays   = 30
+	maxPeriodDays       = 366
+	defaultInactiveDays = 7
+	// dropOffPoints is the number of funnel steps losing the most students that are pointed out.
+	dropOffPoints = 3
+)
+
+func (s *analyticsService) GetClassAnalytics(ctx context.Context, req *entity.GetClassAnalyticsRequest) (*entity.GetClassAnalyticsResponse, error) {
+	if err := s.repo.FindClassByCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	from, to, err := analyticsPeriod(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	inactiveDays := req.InactiveDays
+	if inactiveDays == 0 {
+		inactiveDays = defaultInactiveDays
+	}
+
+	summary, err := s.repo.GetSummary(ctx, req.ClassId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.repo.GetDailyActivity(ctx, req.ClassId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	funnel, err := s.repo.GetFunnel(ctx, req.ClassId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	atRisk, err := s.repo.GetAtRiskStudents(ctx, req.ClassId, inactiveDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetClassAnalyticsResponse{
+		ClassId:       req.ClassId,
+		From:          from.Format(dateLayout),
+		To:            to.Format(dateLayout),
+		InactiveDays:  inactiveDays,
+		Summary:       *summary,
+		DailyActivity: daily,
+		Funnel:        funnel,
+		DropOffs:      markDropOffs(funnel),
+		AtRisk:        atRisk,
+	}, nil
+}
+
+// analyticsPeriod parses the requested days, a missing end is today and a missing start makes the period 30 days.
+func analyticsPeriod(fromParam, toParam string) (from, to time.Time, err error) {
+	to, err = time.Parse(dateLayout, time.Now().Format(dateLayout))
+	if toParam != "" {
+		to, err = time.Parse(dateLayout, toParam)
+	}
+	if err != nil {
+		return from, to, errmsg.NewCustomErrors(400, errmsg.WithErrors("to", "to is not a valid date (Ex: 2006-01-02)."))
+	}
+
+	from = to.AddDate(0, 0, 1-defaultPeriodDays)
+	if fromParam != "" {
+		from, err = time.Parse(dateLayout, fromParam)
+		if err != nil {
+			return from, to, errmsg.NewCustomErrors(400, errmsg.WithErrors("from", "from is not a valid date (Ex: 2006-01-02)."))
+		}
+	}
+
+	if from.After(to) {
+		return from, to, errmsg.NewCustomErrors(400, errmsg.WithErrors("from", "from must not be after to."))
+	}
+	if to.Sub(from) >= maxPeriodDays*24*time.Hour {
+		return from, to, errmsg.NewCustomErrors(400, errmsg.WithErrors("from", "the period must not be longer than 366 days."))
+	}
+
+	return from, to, nil
+}
+
+// markDropOffs fills in the drop-off of every step and returns the steps losing the most students.
+func markDropOffs(funnel []entity.FunnelStep) []entity.FunnelStep {
+	points := make([]entity.FunnelStep, 0, dropOffPoints)
+
+	for i := range funnel {
+		step := &funnel[i]
+
+		reached := step.Started
+		if i > 0 {
+			reached = funnel[i-1].Completed
+		}
+
+		if reached == 0 || step.Completed >= reached {
+			continue
+		}
+
+		step.DropOff = reached - step.Completed
+		step.DropOffRate = math.Round(float64(step.DropOff)*10000/float64(reached)) / 100
+		points = append(points, *step)
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].DropOff > points[j].DropOff
+	})
+
+	if len(points) > dropOffPoints {
+		points = points[:dropOffPoints]
+	}
+
+	return points
+}
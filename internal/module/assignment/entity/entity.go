@@ -3,22 +3,86 @@ package entity
 import "time"
 
 type CreateAssignmentRequest struct {
-	UserId      string `validate:"required"`
-	ClassId     int    `json:"class_id" validate:"required"`
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
-	DueDate     string `json:"due_date"`
+	UserId             string   `validate:"required"`
+	ClassId            int      `json:"class_id" validate:"required"`
+	Title              string   `json:"title" validate:"required"`
+	Description        string   `json:"description" validate:"required"`
+	DueDate            string   `json:"due_date"`
+	AllowedFileTypes   []string `json:"allowed_file_types"`
+	MaxFiles           *int     `json:"max_files" validate:"omitempty,min=0,max=20"`
+	AllowLate          bool     `json:"allow_late"`
+	LatePenaltyPercent float64  `json:"late_penalty_percent" validate:"min=0,max=100"`
+	LateCutoff         *string  `json:"late_cutoff"`
+	ResubmissionPolicy string   `json:"resubmission_policy" validate:"omitempty,oneof=none until_graded until_deadline"`
+	MaxScore           *float64 `json:"max_score" validate:"omitempty,gt=0"`
+	PeerReviewCount    int      `json:"peer_review_count" validate:"min=0,max=10"`
+	PeerReviewWeight   float64  `json:"peer_review_weight" validate:"min=0,max=100"`
+	IsGroup            bool     `json:"is_group"`
 }
 
 type CreateAssignmentResponse struct {
-	Id          int       `json:"id" db:"id"`
-	UserId      string    `json:"creator_assignment_id" db:"creator_assignment_id"`
-	ClassId     int       `json:"class_id" db:"class_id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	DueDate     time.Time `json:"due_date" db:"due_date"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Id                 int        `json:"id" db:"id"`
+	UserId             string     `json:"creator_assignment_id" db:"creator_assignment_id"`
+	ClassId            int        `json:"class_id" db:"class_id"`
+	Title              string     `json:"title" db:"title"`
+	Description        string     `json:"description" db:"description"`
+	DueDate            time.Time  `json:"due_date" db:"due_date"`
+	AllowedFileTypes   []string   `json:"allowed_file_types" db:"allowed_file_types"`
+	MaxFiles           int        `json:"max_files" db:"max_files"`
+	AllowLate          bool       `json:"allow_late" db:"allow_late"`
+	LatePenaltyPercent float64    `json:"late_penalty_percent" db:"late_penalty_percent"`
+	LateCutoff         *time.Time `json:"late_cutoff" db:"late_cutoff"`
+	ResubmissionPolicy string     `json:"resubmission_policy" db:"resubmission_policy"`
+	MaxScore           float64    `json:"max_score" db:"max_score"`
+	PeerReviewCount    int        `json:"peer_review_count" db:"peer_review_count"`
+	PeerReviewWeight   float64    `json:"peer_review_weight" db:"peer_review_weight"`
+	IsGroup            bool       `json:"is_group" db:"is_group"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateAssignmentRequest struct {
+	UserId             string    `validate:"required"`
+	AssignmentId       int       `json:"assignment_id" validate:"required"`
+	Title              *string   `json:"title" validate:"omitempty,min=1"`
+	Description        *string   `json:"description"`
+	DueDate            *string   `json:"due_date"`
+	AllowedFileTypes   *[]string `json:"allowed_file_types"`
+	MaxFiles           *int      `json:"max_files" validate:"omitempty,min=0,max=20"`
+	AllowLate          *bool     `json:"allow_late"`
+	LatePenaltyPercent *float64  `json:"late_penalty_percent" validate:"omitempty,min=0,max=100"`
+	LateCutoff         *string   `json:"late_cutoff"`
+	ResubmissionPolicy *string   `json:"resubmission_policy" validate:"omitempty,oneof=none until_graded until_deadline"`
+	MaxScore           *float64  `json:"max_score" validate:"omitempty,gt=0"`
+	PeerReviewCount    *int      `json:"peer_review_count" validate:"omitempty,min=0,max=10"`
+	PeerReviewWeight   *float64  `json:"peer_review_weight" validate:"omitempty,min=0,max=100"`
+	IsGroup            *bool     `json:"is_group"`
+}
+
+type UpdateAssignmentResponse struct {
+	Id                 int        `json:"id" db:"id"`
+	UserId             string     `json:"creator_assignment_id" db:"creator_assignment_id"`
+	ClassId            int        `json:"class_id" db:"class_id"`
+	Title              string     `json:"title" db:"title"`
+	Description        string     `json:"description" db:"description"`
+	DueDate            time.Time  `json:"due_date" db:"due_date"`
+	AllowedFileTypes   []string   `json:"allowed_file_types" db:"allowed_file_types"`
+	MaxFiles           int        `json:"max_files" db:"max_files"`
+	AllowLate          bool       `json:"allow_late" db:"allow_late"`
+	LatePenaltyPercent float64    `json:"late_penalty_percent" db:"late_penalty_percent"`
+	LateCutoff         *time.Time `json:"late_cutoff" db:"late_cutoff"`
+	ResubmissionPolicy string     `json:"resubmission_policy" db:"resubmission_policy"`
+	MaxScore           float64    `json:"max_score" db:"max_score"`
+	PeerReviewCount    int        `json:"peer_review_count" db:"peer_review_count"`
+	PeerReviewWeight   float64    `json:"peer_review_weight" db:"peer_review_weight"`
+	IsGroup            bool       `json:"is_group" db:"is_group"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type DeleteAssignmentRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
 }
 
 type GetAllAssignmentByClassIdRequest struct {
@@ -26,22 +90,44 @@ type GetAllAssignmentByClassIdRequest struct {
 	ClassId string `json:"class_id"`
 }
 
+// Statuses of an assignment from the point of view of a student.
+const (
+	StatusNotSubmitted = "not_submitted"
+	StatusSubmitted    = "submitted"
+	StatusLate         = "late"
+	StatusGraded       = "graded"
+	StatusMissing      = "missing"
+)
+
 type GetAssignmentByClassIdResponse struct {
-	Id                  int       `json:"id" db:"id"`
-	CreatorAssignmentId string    `json:"creator_assignment_id" db:"creator_assignment_id"`
-	ClassId             int       `json:"class_id" db:"class_id"`
-	Title               string    `json:"title" db:"title"`
-	Description         string    `json:"description" db:"description"`
-	Status              string    `json:"status"`
-	DueDate             time.Time `json:"due_date" db:"due_date"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	Id                  int        `json:"id" db:"id"`
+	CreatorAssignmentId string     `json:"creator_assignment_id" db:"creator_assignment_id"`
+	ClassId             int        `json:"class_id" db:"class_id"`
+	Title               string     `json:"title" db:"title"`
+	Description         string     `json:"description" db:"description"`
+	Status              string     `json:"status" db:"status"`
+	DueDate             time.Time  `json:"due_date" db:"due_date"`
+	IsExtended          bool       `json:"is_extended" db:"is_extended"`
+	MaxScore            float64    `json:"max_score" db:"max_score"`
+	IsGroup             bool       `json:"is_group" db:"is_group"`
+	SubmittedAt         *time.Time `json:"submitted_at" db:"submitted_at"`
+	Grade               *float64   `json:"grade" db:"grade"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-type GetAssignmentStatusRequest struct {
-	UserId       string
-	ClassId      string
-	AssignmentId int
+type AssignmentStatusCounts struct {
+	NotSubmitted int `json:"not_submitted"`
+	Submitted    int `json:"submitted"`
+	Late         int `json:"late"`
+	Graded       int `json:"graded"`
+	Missing      int `json:"missing"`
+	Outstanding  int `json:"outstanding"`
+}
+
+type GetAllAssignmentByClassIdResponse struct {
+	Counts      AssignmentStatusCounts           `json:"counts"`
+	Assignments []GetAssignmentByClassIdResponse `json:"assignments"`
 }
 
 type GetAllAssignmentByClassIdAdminRequest struct {
@@ -66,15 +152,21 @@ type GetAssignmentDetailsRequest struct {
 }
 
 type GetAssignmentDetailsResponse struct {
-	Id             int     `json:"id" db:"title"`
-	Title          string  `json:"title" db:"title"`
-	Description    string  `json:"description" db:"description"`
-	DueDate        string  `json:"due_date" db:"due_date"`
-	LinkSubmission *string `json:"link_submission" db:"link"`
-	Grade          *string `json:"grade_subission" db:"grade"`
-	Feedback       *string `json:"feedback_submission" db:"feedback"`
-	Status         *string `json:"status_submission" db:"status"`
-	SubmittedAt    *string `json:"submitted_at" db:"submitted_at"`
+	Id               int      `json:"id" db:"id"`
+	Title            string   `json:"title" db:"title"`
+	Description      string   `json:"description" db:"description"`
+	DueDate          string   `json:"due_date" db:"due_date"`
+	LateCutoff       *string  `json:"late_cutoff" db:"late_cutoff"`
+	IsExtended       bool     `json:"is_extended" db:"is_extended"`
+	MaxScore         float64  `json:"max_score" db:"max_score"`
+	LinkSubmission   *string  `json:"link_submission" db:"link"`
+	Grade            *float64 `json:"grade_subission" db:"grade"`
+	Feedback         *string  `json:"feedback_submission" db:"feedback"`
+	Status           *string  `json:"status_submission" db:"status_submission"`
+	SubmittedAt      *string  `json:"submitted_at" db:"submitted_at"`
+	Attempt          *int     `json:"attempt" db:"attempt"`
+	LateDays         *int     `json:"late_days" db:"late_days"`
+	AssignmentStatus string   `json:"status" db:"status"`
 }
 
 type GetAssignmentDetailsAdminRequest struct {
@@ -83,11 +175,14 @@ type GetAssignmentDetailsAdminRequest struct {
 }
 
 type GetSubmissionResponse struct {
-	Id        string `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
+	Id          string  `json:"id" db:"id"`
+	Name        string  `json:"name" db:"name"`
 	Image       *string `json:"image" db:"image"`
-	Status      string `json:"status" db:"status"`
-	SubmittedAt string `json:"submitted_at" db:"submitted_at"`
+	TeamName    *string `json:"team_name" db:"team_name"`
+	Status      string  `json:"status" db:"status"`
+	Attempt     int     `json:"attempt" db:"attempt"`
+	LateDays    int     `json:"late_days" db:"late_days"`
+	SubmittedAt string  `json:"submitted_at" db:"submitted_at"`
 }
 
 type GetAssignmentDetailsAdminResponse struct {
@@ -96,6 +191,176 @@ type GetAssignmentDetailsAdminResponse struct {
 	Description   string `json:"description" db:"description"`
 	DueDate       string `json:"due_date" db:"due_date"`
 	AllSubmission []GetSubmissionResponse
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type RubricLevel struct {
+	Id          int     `json:"id" db:"id"`
+	CriterionId int     `json:"-" db:"criterion_id"`
+	Title       string  `json:"title" db:"title"`
+	Description string  `json:"description" db:"description"`
+	Points      float64 `json:"points" db:"points"`
+	Position    int     `json:"position" db:"position"`
+}
+
+type RubricCriterion struct {
+	Id          int           `json:"id" db:"id"`
+	Title       string        `json:"title" db:"title"`
+	Description string        `json:"description" db:"description"`
+	Position    int           `json:"position" db:"position"`
+	Levels      []RubricLevel `json:"levels"`
+}
+
+type RubricLevelRequest struct {
+	Title       string  `json:"title" validate:"required,max=255"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points" validate:"min=0"`
+}
+
+type RubricCriterionRequest struct {
+	Title       string               `json:"title" validate:"required,max=255"`
+	Description string               `json:"description"`
+	Levels      []RubricLevelRequest `json:"levels" validate:"required,min=1,dive"`
+}
+
+// ReplaceRubricRequest replaces every criterion of the assignment, an empty list removes the rubric.
+type ReplaceRubricRequest struct {
+	UserId       string                   `validate:"required"`
+	AssignmentId int                      `json:"assignment_id" validate:"required"`
+	Criteria     []RubricCriterionRequest `json:"criteria" validate:"dive"`
+}
+
+type GetRubricRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+type RubricResponse struct {
+	AssignmentId int               `json:"assignment_id"`
+	MaxScore     float64           `json:"max_score"`
+	TotalPoints  float64           `json:"total_points"`
+	Criteria     []RubricCriterion `json:"criteria"`
+}
+
+type TestCase struct {
+	Id             int     `json:"id" db:"id"`
+	Name           string  `json:"name" db:"name"`
+	Input          string  `json:"input" db:"input"`
+	ExpectedOutput string  `json:"expected_output" db:"expected_output"`
+	Points         float64 `json:"points" db:"points"`
+	IsHidden       bool    `json:"is_hidden" db:"is_hidden"`
+	Position       int     `json:"position" db:"position"`
+}
+
+type TestCaseRequest struct {
+	Name           string  `json:"name" validate:"required,max=255"`
+	Input          string  `json:"input"`
+	ExpectedOutput string  `json:"expected_output"`
+	Points         float64 `json:"points" validate:"gt=0"`
+	IsHidden       *bool   `json:"is_hidden"`
+}
+
+// ReplaceTestSuiteRequest replaces the test cases of the assignment, without a language submissions are not run.
+type ReplaceTestSuiteRequest struct {
+	UserId       string            `validate:"required"`
+	AssignmentId int               `json:"assignment_id" validate:"required"`
+	Language     *string           `json:"language" validate:"omitempty,oneof=go python"`
+	TimeLimitMs  int               `json:"time_limit_ms" validate:"omitempty,min=100,max=30000"`
+	MemoryMb     int               `json:"memory_mb" validate:"omitempty,min=16,max=2048"`
+	Tests        []TestCaseRequest `json:"tests" validate:"max=50,dive"`
+}
+
+type GetTestSuiteRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+type TestSuite struct {
+	CreatorId   string  `db:"creator_assignment_id"`
+	MaxScore    float64 `db:"max_score"`
+	Language    *string `db:"autograde_language"`
+	TimeLimitMs int     `db:"autograde_time_limit_ms"`
+	MemoryMb    int     `db:"autograde_memory_mb"`
+}
+
+// TestSuiteResponse only lists the hidden tests to the assignment creator, students get their count and points.
+type TestSuiteResponse struct {
+	AssignmentId int        `json:"assignment_id"`
+	Language     *string    `json:"language"`
+	TimeLimitMs  int        `json:"time_limit_ms"`
+	MemoryMb     int        `json:"memory_mb"`
+	MaxScore     float64    `json:"max_score"`
+	TotalPoints  float64    `json:"total_points"`
+	HiddenTests  int        `json:"hidden_tests"`
+	HiddenPoints float64    `json:"hidden_points"`
+	Tests        []TestCase `json:"tests"`
+}
+
+// GrantExtensionRequest moves the deadline of one student or one team, granting it again replaces the previous one.
+type GrantExtensionRequest struct {
+	UserId       string     `validate:"required"`
+	AssignmentId int        `json:"assignment_id" validate:"required"`
+	StudentId    *string    `json:"student_id" validate:"required_without=TeamId,excluded_with=TeamId,omitempty,uuid"`
+	TeamId       *int       `json:"team_id" validate:"required_without=StudentId,omitempty,min=1"`
+	DueDate      time.Time  `json:"due_date" validate:"required"`
+	LateCutoff   *time.Time `json:"late_cutoff" validate:"omitempty,gtefield=DueDate"`
+	Reason       string     `json:"reason" validate:"required,max=1000"`
+}
+
+type RevokeExtensionRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+	ExtensionId  int    `json:"extension_id" validate:"required"`
+	Reason       string `json:"reason" validate:"required,max=1000"`
+}
+
+type GetExtensionsRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+type Extension struct {
+	Id            int        `json:"id" db:"id"`
+	StudentId     *string    `json:"student_id" db:"student_id"`
+	StudentName   *string    `json:"student_name" db:"student_name"`
+	TeamId        *int       `json:"team_id" db:"team_id"`
+	TeamName      *string    `json:"team_name" db:"team_name"`
+	DueDate       time.Time  `json:"due_date" db:"due_date"`
+	LateCutoff    *time.Time `json:"late_cutoff" db:"late_cutoff"`
+	Reason        string     `json:"reason" db:"reason"`
+	GrantedBy     string     `json:"granted_by" db:"granted_by"`
+	GrantedByName string     `json:"granted_by_name" db:"granted_by_name"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ExtensionAudit is one grant, change or revocation of an extension.
+type ExtensionAudit struct {
+	Id          int        `json:"id" db:"id"`
+	StudentId   *string    `json:"student_id" db:"student_id"`
+	StudentName *string    `json:"student_name" db:"student_name"`
+	TeamId      *int       `json:"team_id" db:"team_id"`
+	TeamName    *string    `json:"team_name" db:"team_name"`
+	Action      string     `json:"action" db:"action"`
+	DueDate     *time.Time `json:"due_date" db:"due_date"`
+	LateCutoff  *time.Time `json:"late_cutoff" db:"late_cutoff"`
+	Reason      string     `json:"reason" db:"reason"`
+	ActorId     *string    `json:"actor_id" db:"actor_id"` // nil once the actor is deleted
+	ActorName   string     `json:"actor_name" db:"actor_name"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type GetExtensionsResponse struct {
+	AssignmentId int              `json:"assignment_id"`
+	DueDate      time.Time        `json:"due_date"`
+	LateCutoff   *time.Time       `json:"late_cutoff"`
+	Extensions   []Extension      `json:"extensions"`
+	History      []ExtensionAudit `json:"history"`
+}
+
+type AssignmentDeadline struct {
+	CreatorId  string     `db:"creator_assignment_id"`
+	DueDate    time.Time  `db:"due_date"`
+	LateCutoff *time.Time `db:"late_cutoff"`
 }
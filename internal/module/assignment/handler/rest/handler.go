@@ -2,6 +2,7 @@ package handler
 
 import (
 	"hacko-app/internal/adapter"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/middleware"
 	"hacko-app/internal/module/assignment/entity"
 	"hacko-app/internal/module/assignment/ports"
@@ -23,7 +24,8 @@ func NewAssignmentHandler() *assignmentHandler {
 	var handler = new(assignmentHandler)
 
 	repo := repository.NewAssignmentRepository(adapter.Adapters.HackoPostgres)
-	assignmentService := service.NewAssignmentService(repo)
+	storage := integStorage.NewStorageIntegration()
+	assignmentService := service.NewAssignmentService(repo, storage)
 
 	handler.service = assignmentService
 	return handler
@@ -32,12 +34,21 @@ func NewAssignmentHandler() *assignmentHandler {
 func (h *assignmentHandler) Register(router fiber.Router) {
 	// admin routes
 	router.Post("/class/:classId/assignment", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.CreateAssignment)
+	router.Patch("/class/assignment/:assignmentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UpdateAssignment)
+	router.Delete("/class/assignment/:assignmentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.DeleteAssignment)
+	router.Put("/class/assignment/:assignmentId/rubric", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.ReplaceRubric)
+	router.Put("/class/assignment/:assignmentId/tests", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.ReplaceTestSuite)
+	router.Put("/class/assignment/:assignmentId/extensions", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GrantExtension)
+	router.Delete("/class/assignment/:assignmentId/extensions/:extensionId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.RevokeExtension)
+	router.Get("/class/assignment/:assignmentId/extensions", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetExtensions)
 	router.Get("teacher/class/:classId/assignment", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetAllAssignmentByClassIdAdmin)
 	router.Get("teacher/class/:classId/assignment/:assignmentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetAssignmentDetailsAdmin)
 
 	// user routes
 	router.Get("/class/:classId/assignment", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetAllAssignmentByClassId)
 	router.Get("/class/assignment/:assignmentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetAssignmentDetails)
+	router.Get("/class/assignment/:assignmentId/rubric", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetRubric)
+	router.Get("/class/assignment/:assignmentId/tests", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetTestSuite)
 }
 
 func (h *assignmentHandler) CreateAssignment(c *fiber.Ctx) error {
@@ -80,6 +91,334 @@ func (h *assignmentHandler) CreateAssignment(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
 }
 
+func (h *assignmentHandler) UpdateAssignment(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateAssignmentRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAssignment - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAssignment - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateAssignment - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateAssignment(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) DeleteAssignment(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteAssignmentRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteAssignment - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteAssignment - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteAssignment(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, "successfully deleted assignment"))
+}
+
+func (h *assignmentHandler) ReplaceRubric(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ReplaceRubricRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceRubric - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceRubric - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceRubric - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.ReplaceRubric(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) GetRubric(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetRubricRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetRubric - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetRubric - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetRubric(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) ReplaceTestSuite(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ReplaceTestSuiteRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceTestSuite - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceTestSuite - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ReplaceTestSuite - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.ReplaceTestSuite(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) GetTestSuite(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTestSuiteRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetTestSuite - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetTestSuite - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetTestSuite(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) GrantExtension(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GrantExtensionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GrantExtension - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GrantExtension - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GrantExtension - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GrantExtension(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) RevokeExtension(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RevokeExtensionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::RevokeExtension - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::RevokeExtension - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	extensionId, err := strconv.Atoi(c.Params("extensionId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::RevokeExtension - Failed to parsing id extension")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id extension"))))
+	}
+
+	req.AssignmentId = reqId
+	req.ExtensionId = extensionId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::RevokeExtension - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.RevokeExtension(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *assignmentHandler) GetExtensions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetExtensionsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetExtensions - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetExtensions - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetExtensions(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
 func (h *assignmentHandler) GetAllAssignmentByClassId(c *fiber.Ctx) error{
 	var (
 		req = new(entity.GetAllAssignmentByClassIdRequest)
@@ -8,14 +8,27 @@ import (
 type AssignmentRepository interface {
 	// utils validation
 	FindClass(ctx context.Context, req string) error
-	GetAssignmentStatus(ctx context.Context, req *entity.GetAssignmentStatusRequest) string
+	GetAssignmentMaxScore(ctx context.Context, assignmentId int) (float64, error)
+	HasSubmissions(ctx context.Context, assignmentId int) (bool, error)
 
 	// admin contract
 	CreateAssignment(ctx context.Context, req *entity.CreateAssignmentRequest) (*entity.CreateAssignmentResponse, error)
+	UpdateAssignment(ctx context.Context, req *entity.UpdateAssignmentRequest) (*entity.UpdateAssignmentResponse, error)
+	DeleteAssignment(ctx context.Context, req *entity.DeleteAssignmentRequest) ([]string, error)
 	GetAllAssignmentByClassIdAdmin(ctx context.Context, req *entity.GetAllAssignmentByClassIdAdminRequest) (*[]entity.GetAllAssignmentByClassIdAdminResponse, error)
 	GetAssignmentDetailsAdmin(ctx context.Context, req *entity.GetAssignmentDetailsAdminRequest) (*entity.GetAssignmentDetailsAdminResponse, error)
+	ReplaceRubric(ctx context.Context, req *entity.ReplaceRubricRequest) error
+	ReplaceTestSuite(ctx context.Context, req *entity.ReplaceTestSuiteRequest) error
+	GetAssignmentDeadline(ctx context.Context, assignmentId int) (*entity.AssignmentDeadline, error)
+	GrantExtension(ctx context.Context, req *entity.GrantExtensionRequest) (int, error)
+	RevokeExtension(ctx context.Context, req *entity.RevokeExtensionRequest) error
+	GetExtensions(ctx context.Context, assignmentId int) ([]entity.Extension, error)
+	GetExtensionHistory(ctx context.Context, assignmentId int) ([]entity.ExtensionAudit, error)
 
 	// user contract
+	GetRubric(ctx context.Context, assignmentId int) ([]entity.RubricCriterion, error)
+	GetTestSuite(ctx context.Context, assignmentId int) (*entity.TestSuite, error)
+	GetTestCases(ctx context.Context, assignmentId int) ([]entity.TestCase, error)
 	GetAssignmentDetails(ctx context.Context, req *entity.GetAssignmentDetailsRequest) (*entity.GetAssignmentDetailsResponse, error)
 	GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) ([]entity.GetAssignmentByClassIdResponse, error)
 }
@@ -23,10 +36,19 @@ type AssignmentRepository interface {
 type AssignmentService interface {
 	// admin contract
 	CreateAssignment(ctx context.Context, req *entity.CreateAssignmentRequest) (*entity.CreateAssignmentResponse, error)
+	UpdateAssignment(ctx context.Context, req *entity.UpdateAssignmentRequest) (*entity.UpdateAssignmentResponse, error)
+	DeleteAssignment(ctx context.Context, req *entity.DeleteAssignmentRequest) error
 	GetAllAssignmentByClassIdAdmin(ctx context.Context, req *entity.GetAllAssignmentByClassIdAdminRequest) (*[]entity.GetAllAssignmentByClassIdAdminResponse, error)
 	GetAssignmentDetailsAdmin(ctx context.Context, req *entity.GetAssignmentDetailsAdminRequest) (*entity.GetAssignmentDetailsAdminResponse, error)
+	ReplaceRubric(ctx context.Context, req *entity.ReplaceRubricRequest) (*entity.RubricResponse, error)
+	ReplaceTestSuite(ctx context.Context, req *entity.ReplaceTestSuiteRequest) (*entity.TestSuiteResponse, error)
+	GrantExtension(ctx context.Context, req *entity.GrantExtensionRequest) (*entity.GetExtensionsResponse, error)
+	RevokeExtension(ctx context.Context, req *entity.RevokeExtensionRequest) (*entity.GetExtensionsResponse, error)
+	GetExtensions(ctx context.Context, req *entity.GetExtensionsRequest) (*entity.GetExtensionsResponse, error)
 
 	// user contract
-	GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) ([]entity.GetAssignmentByClassIdResponse, error)
+	GetRubric(ctx context.Context, req *entity.GetRubricRequest) (*entity.RubricResponse, error)
+	GetTestSuite(ctx context.Context, req *entity.GetTestSuiteRequest) (*entity.TestSuiteResponse, error)
+	GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) (*entity.GetAllAssignmentByClassIdResponse, error)
 	GetAssignmentDetails(ctx context.Context, req *entity.GetAssignmentDetailsRequest) (*entity.GetAssignmentDetailsResponse, error)
 }
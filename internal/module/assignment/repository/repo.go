@@ -9,11 +9,17 @@ import (
 	"hacko-app/pkg/errmsg"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
 var _ ports.AssignmentRepository = &assignmentRepository{}
 
+var (
+	errLateCutoff      = errmsg.NewCustomErrors(400, errmsg.WithErrors("late_cutoff", "late_cutoff must not be before due_date."))
+	errGroupPeerReview = errmsg.NewCustomErrors(400, errmsg.WithErrors("peer_review_count", "peer review is not available for group assignments."))
+)
+
 type assignmentRepository struct {
 	db *sqlx.DB
 }
@@ -32,13 +38,29 @@ func (r *assignmentRepository) CreateAssignment(ctx context.Context, req *entity
 			title, 
 			description, 
 			due_date, 
+			allowed_file_types,
+			max_files,
+			allow_late,
+			late_penalty_percent,
+			late_cutoff,
+			resubmission_policy,
+			max_score,
+			peer_review_count,
+			peer_review_weight,
+			is_group,
 			created_at, 
 			updated_at
 		) 
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) 
-		RETURNING id, creator_assignment_id, class_id, title, description, due_date, created_at, updated_at
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 1), $8, $9, $10, COALESCE(NULLIF($11, ''), 'none')::resubmission_policy, COALESCE($12, 100), $13, $14, $15, NOW(), NOW()) 
+		RETURNING id, creator_assignment_id, class_id, title, description, due_date, allowed_file_types, max_files,
+			allow_late, late_penalty_percent, late_cutoff, resubmission_policy, max_score, peer_review_count, peer_review_weight, is_group, created_at, updated_at
 	`
 
+	allowedFileTypes := req.AllowedFileTypes
+	if allowedFileTypes == nil {
+		allowedFileTypes = []string{}
+	}
+
 	var response entity.CreateAssignmentResponse
 	err := r.db.QueryRowContext(
 		ctx,
@@ -48,6 +70,16 @@ func (r *assignmentRepository) CreateAssignment(ctx context.Context, req *entity
 		req.Title,
 		req.Description,
 		req.DueDate,
+		pq.Array(allowedFileTypes),
+		req.MaxFiles,
+		req.AllowLate,
+		req.LatePenaltyPercent,
+		req.LateCutoff,
+		req.ResubmissionPolicy,
+		req.MaxScore,
+		req.PeerReviewCount,
+		req.PeerReviewWeight,
+		req.IsGroup,
 	).Scan(
 		&response.Id,
 		&response.UserId,
@@ -55,11 +87,29 @@ func (r *assignmentRepository) CreateAssignment(ctx context.Context, req *entity
 		&response.Title,
 		&response.Description,
 		&response.DueDate,
+		pq.Array(&response.AllowedFileTypes),
+		&response.MaxFiles,
+		&response.AllowLate,
+		&response.LatePenaltyPercent,
+		&response.LateCutoff,
+		&response.ResubmissionPolicy,
+		&response.MaxScore,
+		&response.PeerReviewCount,
+		&response.PeerReviewWeight,
+		&response.IsGroup,
 		&response.CreatedAt,
 		&response.UpdatedAt,
 	)
 
 	if err != nil {
+		if isLateCutoffViolation(err) {
+			log.Warn().Any("payload", req).Msg("repo::CreateAssignment - Late cutoff before due date")
+			return nil, errLateCutoff
+		}
+		if isGroupPeerReviewViolation(err) {
+			log.Warn().Any("payload", req).Msg("repo::CreateAssignment - Peer review on a group assignment")
+			return nil, errGroupPeerReview
+		}
 		log.Error().Err(err).Any("payload", req).Msg("repo::CreateAssignment - Failed to insert assignment")
 		return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Module not found or unable to create"))
 	}
@@ -67,133 +117,247 @@ func (r *assignmentRepository) CreateAssignment(ctx context.Context, req *entity
 	return &response, nil
 }
 
-func (r *assignmentRepository) FindClass(ctx context.Context, req string) error {
-	query := `SELECT id FROM class WHERE id = $1`
+func (r *assignmentRepository) UpdateAssignment(ctx context.Context, req *entity.UpdateAssignmentRequest) (*entity.UpdateAssignmentResponse, error) {
+	query := `
+		UPDATE assignments
+		SET
+			title = COALESCE($1, title),
+			description = COALESCE($2, description),
+			due_date = COALESCE($3::TIMESTAMPTZ, due_date),
+			allowed_file_types = COALESCE($4, allowed_file_types),
+			max_files = COALESCE($5, max_files),
+			allow_late = COALESCE($6, allow_late),
+			late_penalty_percent = COALESCE($7, late_penalty_percent),
+			late_cutoff = CASE WHEN $8::TEXT IS NULL THEN late_cutoff ELSE NULLIF($8, '')::TIMESTAMPTZ END,
+			resubmission_policy = COALESCE($9::resubmission_policy, resubmission_policy),
+			max_score = COALESCE($10, max_score),
+			peer_review_count = COALESCE($11, peer_review_count),
+			peer_review_weight = COALESCE($12, peer_review_weight),
+			is_group = COALESCE($13, is_group),
+			updated_at = NOW()
+		WHERE id = $14 AND creator_assignment_id = $15
+		RETURNING id, creator_assignment_id, class_id, title, description, due_date, allowed_file_types, max_files,
+			allow_late, late_penalty_percent, late_cutoff, resubmission_policy, max_score, peer_review_count, peer_review_weight, is_group, created_at, updated_at
+	`
 
-	var classId string
-	err := r.db.QueryRowContext(ctx, query, req).Scan(&classId)
+	var allowedFileTypes interface{}
+	if req.AllowedFileTypes != nil {
+		allowedFileTypes = pq.Array(*req.AllowedFileTypes)
+	}
 
+	var response entity.UpdateAssignmentResponse
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		req.Title,
+		req.Description,
+		req.DueDate,
+		allowedFileTypes,
+		req.MaxFiles,
+		req.AllowLate,
+		req.LatePenaltyPercent,
+		req.LateCutoff,
+		req.ResubmissionPolicy,
+		req.MaxScore,
+		req.PeerReviewCount,
+		req.PeerReviewWeight,
+		req.IsGroup,
+		req.AssignmentId,
+		req.UserId,
+	).Scan(
+		&response.Id,
+		&response.UserId,
+		&response.ClassId,
+		&response.Title,
+		&response.Description,
+		&response.DueDate,
+		pq.Array(&response.AllowedFileTypes),
+		&response.MaxFiles,
+		&response.AllowLate,
+		&response.LatePenaltyPercent,
+		&response.LateCutoff,
+		&response.ResubmissionPolicy,
+		&response.MaxScore,
+		&response.PeerReviewCount,
+		&response.PeerReviewWeight,
+		&response.IsGroup,
+		&response.CreatedAt,
+		&response.UpdatedAt,
+	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Error().Err(err).Str("class_id", req).Msg("repo::FindClass - Class not found")
-			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateAssignment - Assignment not found or not owned by user")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
 		}
-		log.Error().Err(err).Str("class_id", req).Msg("repo::FindClass - Failed to query class")
-		return err
+		if isLateCutoffViolation(err) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateAssignment - Late cutoff before due date")
+			return nil, errLateCutoff
+		}
+		if isGroupPeerReviewViolation(err) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateAssignment - Peer review on a group assignment")
+			return nil, errGroupPeerReview
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateAssignment - Failed to update assignment")
+		return nil, err
 	}
 
-	return nil
+	return &response, nil
 }
 
-func (r *assignmentRepository) GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) ([]entity.GetAssignmentByClassIdResponse, error) {
-	query := `
-        SELECT id, creator_assignment_id, class_id, title, description, due_date, created_at, updated_at
-        FROM assignments
-        WHERE class_id = $1;
-    `
-
-	rows, err := r.db.QueryContext(ctx, query, req.ClassId)
+// DeleteAssignment removes the assignment with its submissions and returns the storage keys of the submitted files.
+func (r *assignmentRepository) DeleteAssignment(ctx context.Context, req *entity.DeleteAssignmentRequest) (keys []string, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		log.Error().Err(err).Str("class_id", req.ClassId).Msg("repo::GetAllAssignmentByClassId - Failed to query assignments")
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteAssignment - Failed to begin transaction")
 		return nil, err
 	}
-	defer rows.Close()
-
-	var assignments []entity.GetAssignmentByClassIdResponse
-	for rows.Next() {
-		var assignment entity.GetAssignmentByClassIdResponse
-		err := rows.Scan(
-			&assignment.Id,
-			&assignment.CreatorAssignmentId,
-			&assignment.ClassId,
-			&assignment.Title,
-			&assignment.Description,
-			&assignment.DueDate,
-			&assignment.CreatedAt,
-			&assignment.UpdatedAt,
-		)
+	defer func() {
 		if err != nil {
-			log.Error().Err(err).Msg("repo::GetAllAssignmentByClassId - Failed to scan assignment")
-			return nil, err
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::DeleteAssignment - Failed to rollback transaction")
+			}
 		}
+	}()
 
-		status := r.GetAssignmentStatus(ctx, &entity.GetAssignmentStatusRequest{
-			ClassId:      req.ClassId,
-			AssignmentId: assignment.Id,
-			UserId:       req.UserId,
-		})
+	keysQuery := `
+		SELECT sf.storage_key
+		FROM submission_files sf
+		JOIN submissions s ON s.id = sf.submission_id
+		WHERE s.assignment_id = $1
+	`
 
-		if status == "" {
-			assignment.Status = "not_submit_yet"
-		} else {
-			assignment.Status = status
-		}
+	keys = []string{}
+	if err = tx.SelectContext(ctx, &keys, keysQuery, req.AssignmentId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteAssignment - Failed to get submission files")
+		return nil, err
+	}
 
-		assignments = append(assignments, assignment)
+	result, err := tx.ExecContext(ctx, `DELETE FROM assignments WHERE id = $1 AND creator_assignment_id = $2`, req.AssignmentId, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteAssignment - Failed to delete assignment")
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
-		log.Error().Err(err).Msg("repo::GetAllAssignmentByClassId - Error during rows iteration")
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteAssignment - Failed to get affected rows")
+		return nil, err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteAssignment - Assignment not found or not owned by user")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
 		return nil, err
 	}
 
-	return assignments, nil
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteAssignment - Failed to commit transaction")
+		return nil, err
+	}
+
+	return keys, nil
 }
 
-func (r *assignmentRepository) GetAssignmentStatus(ctx context.Context, req *entity.GetAssignmentStatusRequest) string {
-	query := `
-        SELECT status
-        FROM submission
-        WHERE class_id = $1 AND submission_id = $2 AND user_id = $3
-        LIMIT 1; 
-    `
-	var status string
-	err := r.db.QueryRowContext(ctx, query, req.ClassId, req.AssignmentId, req.UserId).Scan(&status)
+func (r *assignmentRepository) FindClass(ctx context.Context, req string) error {
+	query := `SELECT id FROM class WHERE id = $1`
+
+	var classId string
+	err := r.db.QueryRowContext(ctx, query, req).Scan(&classId)
+
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Error().Err(err).Str("class_id", req.ClassId).Msg("repo::GetAssignmentStatus - No assignments found")
-			return ""
+			log.Error().Err(err).Str("class_id", req).Msg("repo::FindClass - Class not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
 		}
-		log.Error().Err(err).Str("class_id", req.ClassId).Msg("repo::GetAssignmentStatus - Failed to query status")
-		return ""
+		log.Error().Err(err).Str("class_id", req).Msg("repo::FindClass - Failed to query class")
+		return err
+	}
+
+	return nil
+}
+
+// studentSubmissionJoin picks the active submission of student $1 for assignment a, their own or their team's,
+// together with the grade that counts for them.
+const studentSubmissionJoin = `
+	LEFT JOIN LATERAL (
+		SELECT
+			s.id, s.link, s.status, s.submitted_at, s.attempt, s.late_days,
+			COALESCE(sm.grade, s.grade) AS grade, COALESCE(sm.feedback, s.feedback) AS feedback
+		FROM submissions s
+		LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = $1
+		WHERE s.assignment_id = a.id AND s.is_active AND (s.student_id = $1 OR sm.student_id IS NOT NULL)
+		ORDER BY s.submitted_at DESC
+		LIMIT 1
+	) s ON TRUE
+`
+
+// studentDeadlineJoin joins the deadline of student $1 for assignment a as d, extensions included.
+const studentDeadlineJoin = `
+	CROSS JOIN LATERAL assignment_deadline(a.id, $1) d
+`
+
+// studentStatus is the status of an assignment for the student whose submission is joined as s and deadline as d.
+const studentStatus = `
+	CASE
+		WHEN s.id IS NULL AND NOW() > d.due_date THEN 'missing'
+		WHEN s.id IS NULL THEN 'not_submitted'
+		WHEN s.status = 'rated' THEN 'graded'
+		WHEN s.late_days > 0 THEN 'late'
+		ELSE 'submitted'
+	END
+`
+
+func (r *assignmentRepository) GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) ([]entity.GetAssignmentByClassIdResponse, error) {
+	var res = make([]entity.GetAssignmentByClassIdResponse, 0)
+
+	query := `
+		SELECT
+			a.id, a.creator_assignment_id, a.class_id, a.title, a.description, d.due_date, d.extended AS is_extended,
+			a.max_score, a.is_group, a.created_at, a.updated_at, s.submitted_at, s.grade,
+			` + studentStatus + ` AS status
+		FROM assignments a
+		` + studentDeadlineJoin + `
+		` + studentSubmissionJoin + `
+		WHERE a.class_id = $2
+		ORDER BY d.due_date, a.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, req.UserId, req.ClassId); err != nil {
+		log.Error().Err(err).Str("class_id", req.ClassId).Msg("repo::GetAllAssignmentByClassId - Failed to query assignments")
+		return nil, err
 	}
 
-	return status
+	return res, nil
 }
 
 func (r *assignmentRepository) GetAssignmentDetails(ctx context.Context, req *entity.GetAssignmentDetailsRequest) (*entity.GetAssignmentDetailsResponse, error) {
 	query := `
         SELECT 
-            a.id AS assignment_id,
+            a.id,
             a.title,
             a.description,
-            a.due_date,
-            s.link AS link_submission,
-            s.grade AS grade_submission,
-            s.feedback AS feedback_submission,
+            d.due_date,
+            d.late_cutoff,
+            d.extended AS is_extended,
+            a.max_score,
+            s.link,
+            s.grade,
+            s.feedback,
             s.status AS status_submission,
-            s.submitted_at
+            s.submitted_at,
+            s.attempt,
+            s.late_days,
+            ` + studentStatus + ` AS status
         FROM 
             assignments a
-        LEFT JOIN 
-            submissions s ON a.id = s.assignment_id AND s.student_id = $1
+        ` + studentDeadlineJoin + `
+        ` + studentSubmissionJoin + `
         WHERE 
             a.id = $2
     `
 
 	var response entity.GetAssignmentDetailsResponse
-	err := r.db.QueryRowContext(ctx, query, req.UserId, req.AssignmentId).Scan(
-		&response.Id,
-		&response.Title,
-		&response.Description,
-		&response.DueDate,
-		&response.LinkSubmission,
-		&response.Grade,
-		&response.Feedback,
-		&response.Status,
-		&response.SubmittedAt,
-	)
-	if err != nil {
+	if err := r.db.GetContext(ctx, &response, query, req.UserId, req.AssignmentId); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Error().Any("assignment_id", req.AssignmentId).Msg("No assignment details found")
 			return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Module with that id not found"))
@@ -274,7 +438,10 @@ func (r *assignmentRepository) GetAssignmentDetailsAdmin(ctx context.Context, re
 				s.id,
 				u.name AS name, 
 				u.image_url AS image, 
+				t.name AS team_name,
 				s.status AS status, 
+				s.attempt AS attempt,
+				s.late_days AS late_days,
 				s.submitted_at AS submitted_at
 			FROM 
 				submissions s
@@ -282,8 +449,10 @@ func (r *assignmentRepository) GetAssignmentDetailsAdmin(ctx context.Context, re
 				users u
 			ON 
 				s.student_id = u.id
+			LEFT JOIN
+				teams t ON t.id = s.team_id
 			WHERE 
-				s.assignment_id = $1
+				s.assignment_id = $1 AND s.is_active
 		`
 
 	if err := r.db.SelectContext(ctx, &submissions, submissionQuery, req.AssignmentId); err != nil {
@@ -294,3 +463,515 @@ func (r *assignmentRepository) GetAssignmentDetailsAdmin(ctx context.Context, re
 	assignment.AllSubmission = submissions
 	return &assignment, nil
 }
+
+func (r *assignmentRepository) HasSubmissions(ctx context.Context, assignmentId int) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM submissions WHERE assignment_id = $1)`
+
+	if err := r.db.QueryRowContext(ctx, query, assignmentId).Scan(&exists); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::HasSubmissions - Failed to check submissions")
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *assignmentRepository) GetAssignmentMaxScore(ctx context.Context, assignmentId int) (float64, error) {
+	query := `SELECT max_score FROM assignments WHERE id = $1`
+
+	var maxScore float64
+	if err := r.db.QueryRowContext(ctx, query, assignmentId).Scan(&maxScore); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::GetAssignmentMaxScore - Assignment not found")
+			return 0, errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetAssignmentMaxScore - Failed to get assignment")
+		return 0, err
+	}
+
+	return maxScore, nil
+}
+
+func (r *assignmentRepository) GetRubric(ctx context.Context, assignmentId int) ([]entity.RubricCriterion, error) {
+	var criteria = make([]entity.RubricCriterion, 0)
+
+	criteriaQuery := `
+		SELECT id, title, COALESCE(description, '') AS description, position
+		FROM rubric_criteria
+		WHERE assignment_id = $1
+		ORDER BY position, id
+	`
+
+	if err := r.db.SelectContext(ctx, &criteria, criteriaQuery, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetRubric - Failed to get rubric criteria")
+		return nil, err
+	}
+
+	var levels []entity.RubricLevel
+
+	levelsQuery := `
+		SELECT l.id, l.criterion_id, l.title, COALESCE(l.description, '') AS description, l.points, l.position
+		FROM rubric_levels l
+		JOIN rubric_criteria c ON c.id = l.criterion_id
+		WHERE c.assignment_id = $1
+		ORDER BY l.position, l.id
+	`
+
+	if err := r.db.SelectContext(ctx, &levels, levelsQuery, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetRubric - Failed to get rubric levels")
+		return nil, err
+	}
+
+	index := make(map[int]int, len(criteria))
+	for i := range criteria {
+		criteria[i].Levels = []entity.RubricLevel{}
+		index[criteria[i].Id] = i
+	}
+	for _, level := range levels {
+		i := index[level.CriterionId]
+		criteria[i].Levels = append(criteria[i].Levels, level)
+	}
+
+	return criteria, nil
+}
+
+// ReplaceRubric swaps the rubric of an assignment, it is refused once any submission was graded against it.
+func (r *assignmentRepository) ReplaceRubric(ctx context.Context, req *entity.ReplaceRubricRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::ReplaceRubric - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	var owned bool
+	ownerQuery := `SELECT EXISTS (SELECT 1 FROM assignments WHERE id = $1 AND creator_assignment_id = $2)`
+
+	if err = tx.QueryRowContext(ctx, ownerQuery, req.AssignmentId, req.UserId).Scan(&owned); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to check assignment owner")
+		return err
+	}
+
+	if !owned {
+		log.Warn().Any("payload", req).Msg("repo::ReplaceRubric - Assignment not found or not owned by user")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		return err
+	}
+
+	var graded bool
+	gradedQuery := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM submission_rubric_scores rs
+			JOIN rubric_criteria c ON c.id = rs.criterion_id
+			WHERE c.assignment_id = $1
+		) OR EXISTS (
+			SELECT 1
+			FROM peer_review_scores ps
+			JOIN rubric_criteria c ON c.id = ps.criterion_id
+			WHERE c.assignment_id = $1
+		)
+	`
+
+	if err = tx.QueryRowContext(ctx, gradedQuery, req.AssignmentId).Scan(&graded); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to check graded submissions")
+		return err
+	}
+
+	if graded {
+		log.Warn().Any("payload", req).Msg("repo::ReplaceRubric - Rubric already used for grading")
+		err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Rubric is already used to grade submissions"))
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM rubric_criteria WHERE assignment_id = $1`, req.AssignmentId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to delete rubric criteria")
+		return err
+	}
+
+	criterionQuery := `
+		INSERT INTO rubric_criteria (assignment_id, title, description, position)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id
+	`
+	levelQuery := `
+		INSERT INTO rubric_levels (criterion_id, title, description, points, position)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+	`
+
+	for i, criterion := range req.Criteria {
+		var criterionId int
+		if err = tx.QueryRowContext(ctx, criterionQuery, req.AssignmentId, criterion.Title, criterion.Description, i).Scan(&criterionId); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to insert rubric criterion")
+			return err
+		}
+
+		for j, level := range criterion.Levels {
+			if _, err = tx.ExecContext(ctx, levelQuery, criterionId, level.Title, level.Description, level.Points, j); err != nil {
+				log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to insert rubric level")
+				return err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceRubric - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *assignmentRepository) GetTestSuite(ctx context.Context, assignmentId int) (*entity.TestSuite, error) {
+	var res entity.TestSuite
+
+	query := `
+		SELECT creator_assignment_id, max_score, autograde_language, autograde_time_limit_ms, autograde_memory_mb
+		FROM assignments
+		WHERE id = $1
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, assignmentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::GetTestSuite - Assignment not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetTestSuite - Failed to get assignment")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *assignmentRepository) GetTestCases(ctx context.Context, assignmentId int) ([]entity.TestCase, error) {
+	var res = make([]entity.TestCase, 0)
+
+	query := `
+		SELECT id, name, input, expected_output, points, is_hidden, position
+		FROM assignment_test_cases
+		WHERE assignment_id = $1
+		ORDER BY position, id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetTestCases - Failed to get test cases")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ReplaceTestSuite stores the grading settings and replaces every test case, as long as no submission was run against them.
+func (r *assignmentRepository) ReplaceTestSuite(ctx context.Context, req *entity.ReplaceTestSuiteRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	settingsQuery := `
+		UPDATE assignments
+		SET
+			autograde_language = $1,
+			autograde_time_limit_ms = COALESCE(NULLIF($2, 0), autograde_time_limit_ms),
+			autograde_memory_mb = COALESCE(NULLIF($3, 0), autograde_memory_mb),
+			updated_at = NOW()
+		WHERE id = $4 AND creator_assignment_id = $5
+	`
+
+	result, err := tx.ExecContext(ctx, settingsQuery, req.Language, req.TimeLimitMs, req.MemoryMb, req.AssignmentId, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to update grading settings")
+		return err
+	}
+
+	if affected, errAffected := result.RowsAffected(); errAffected != nil || affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::ReplaceTestSuite - Assignment not found or not owned by user")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		return err
+	}
+
+	var graded bool
+	gradedQuery := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM submission_test_results tr
+			JOIN assignment_test_cases tc ON tc.id = tr.test_case_id
+			WHERE tc.assignment_id = $1
+		)
+	`
+
+	if err = tx.QueryRowContext(ctx, gradedQuery, req.AssignmentId).Scan(&graded); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to check graded submissions")
+		return err
+	}
+
+	if graded {
+		log.Warn().Any("payload", req).Msg("repo::ReplaceTestSuite - Tests already used for grading")
+		err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Tests are already used to grade submissions"))
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM assignment_test_cases WHERE assignment_id = $1`, req.AssignmentId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to delete test cases")
+		return err
+	}
+
+	testQuery := `
+		INSERT INTO assignment_test_cases (assignment_id, name, input, expected_output, points, is_hidden, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for i, test := range req.Tests {
+		hidden := test.IsHidden == nil || *test.IsHidden
+		if _, err = tx.ExecContext(ctx, testQuery, req.AssignmentId, test.Name, test.Input, test.ExpectedOutput, test.Points, hidden, i); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to insert test case")
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceTestSuite - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *assignmentRepository) GetAssignmentDeadline(ctx context.Context, assignmentId int) (*entity.AssignmentDeadline, error) {
+	var res entity.AssignmentDeadline
+
+	query := `
+		SELECT creator_assignment_id, due_date, late_cutoff
+		FROM assignments
+		WHERE id = $1
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, assignmentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::GetAssignmentDeadline - Assignment not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetAssignmentDeadline - Failed to get assignment")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GrantExtension stores the extension of a student or a team of the class, replacing the one they had, and records it
+// in the audit. It returns the id of the extension.
+func (r *assignmentRepository) GrantExtension(ctx context.Context, req *entity.GrantExtensionRequest) (id int, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GrantExtension - Failed to begin transaction")
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::GrantExtension - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	var exists bool
+	targetQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM users_classes uc JOIN assignments a ON a.class_id = uc.class_id
+			WHERE a.id = $1 AND uc.user_id = $2
+		) OR EXISTS (
+			SELECT 1 FROM teams t JOIN assignments a ON a.class_id = t.class_id
+			WHERE a.id = $1 AND t.id = $3
+		)
+	`
+
+	if err = tx.QueryRowContext(ctx, targetQuery, req.AssignmentId, req.StudentId, req.TeamId).Scan(&exists); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GrantExtension - Failed to check student or team")
+		return 0, err
+	}
+
+	if !exists {
+		log.Warn().Any("payload", req).Msg("repo::GrantExtension - Student or team not in class")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Student or team not found in the class"))
+		return 0, err
+	}
+
+	action := "updated"
+	updateQuery := `
+		UPDATE assignment_extensions
+		SET due_date = $4, late_cutoff = $5, reason = $6, granted_by = $7, updated_at = NOW()
+		WHERE assignment_id = $1 AND student_id IS NOT DISTINCT FROM $2 AND team_id IS NOT DISTINCT FROM $3
+		RETURNING id
+	`
+
+	err = tx.QueryRowContext(ctx, updateQuery, req.AssignmentId, req.StudentId, req.TeamId, req.DueDate, req.LateCutoff, req.Reason, req.UserId).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		action = "granted"
+		insertQuery := `
+			INSERT INTO assignment_extensions (assignment_id, student_id, team_id, due_date, late_cutoff, reason, granted_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`
+		err = tx.QueryRowContext(ctx, insertQuery, req.AssignmentId, req.StudentId, req.TeamId, req.DueDate, req.LateCutoff, req.Reason, req.UserId).Scan(&id)
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			log.Warn().Any("payload", req).Msg("repo::GrantExtension - Concurrent extension for the same student or team")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Another extension for this student or team is being granted"))
+			return 0, err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::GrantExtension - Failed to save extension")
+		return 0, err
+	}
+
+	auditQuery := `
+		INSERT INTO assignment_extension_audit (assignment_id, student_id, student_name, team_id, team_name, action, due_date, late_cutoff, reason, actor_id, actor_name)
+		SELECT
+			$1::INT, $2::UUID, (SELECT u.name FROM users u WHERE u.id = $2::UUID), $3::INT, (SELECT t.name FROM teams t WHERE t.id = $3::INT),
+			$4::TEXT, $5::TIMESTAMPTZ, $6::TIMESTAMPTZ, $7::TEXT, g.id, g.name
+		FROM users g
+		WHERE g.id = $8
+	`
+
+	if _, err = tx.ExecContext(ctx, auditQuery, req.AssignmentId, req.StudentId, req.TeamId, action, req.DueDate, req.LateCutoff, req.Reason, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GrantExtension - Failed to insert audit")
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GrantExtension - Failed to commit transaction")
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// RevokeExtension deletes an extension, the student or team is back on the deadline of the assignment.
+func (r *assignmentRepository) RevokeExtension(ctx context.Context, req *entity.RevokeExtensionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RevokeExtension - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::RevokeExtension - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	var (
+		studentId *string
+		teamId    *int
+	)
+
+	deleteQuery := `
+		DELETE FROM assignment_extensions
+		WHERE id = $1 AND assignment_id = $2
+		RETURNING student_id, team_id
+	`
+
+	if err = tx.QueryRowContext(ctx, deleteQuery, req.ExtensionId, req.AssignmentId).Scan(&studentId, &teamId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::RevokeExtension - Extension not found")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Extension not found"))
+			return err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::RevokeExtension - Failed to delete extension")
+		return err
+	}
+
+	auditQuery := `
+		INSERT INTO assignment_extension_audit (assignment_id, student_id, student_name, team_id, team_name, action, reason, actor_id, actor_name)
+		SELECT
+			$1::INT, $2::UUID, (SELECT u.name FROM users u WHERE u.id = $2::UUID), $3::INT, (SELECT t.name FROM teams t WHERE t.id = $3::INT),
+			'revoked', $4::TEXT, g.id, g.name
+		FROM users g
+		WHERE g.id = $5
+	`
+
+	if _, err = tx.ExecContext(ctx, auditQuery, req.AssignmentId, studentId, teamId, req.Reason, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RevokeExtension - Failed to insert audit")
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RevokeExtension - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *assignmentRepository) GetExtensions(ctx context.Context, assignmentId int) ([]entity.Extension, error) {
+	var res = make([]entity.Extension, 0)
+
+	query := `
+		SELECT
+			e.id, e.student_id, u.name AS student_name, e.team_id, t.name AS team_name, e.due_date,
+			COALESCE(e.late_cutoff, a.late_cutoff + (e.due_date - a.due_date)) AS late_cutoff,
+			e.reason, e.granted_by, g.name AS granted_by_name, e.created_at, e.updated_at
+		FROM assignment_extensions e
+		JOIN assignments a ON a.id = e.assignment_id
+		JOIN users g ON g.id = e.granted_by
+		LEFT JOIN users u ON u.id = e.student_id
+		LEFT JOIN teams t ON t.id = e.team_id
+		WHERE e.assignment_id = $1
+		ORDER BY e.due_date, e.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetExtensions - Failed to get extensions")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *assignmentRepository) GetExtensionHistory(ctx context.Context, assignmentId int) ([]entity.ExtensionAudit, error) {
+	var res = make([]entity.ExtensionAudit, 0)
+
+	query := `
+		SELECT
+			ea.id, ea.student_id, ea.student_name, ea.team_id, ea.team_name, ea.action, ea.due_date,
+			ea.late_cutoff, ea.reason, ea.actor_id, ea.actor_name, ea.created_at
+		FROM assignment_extension_audit ea
+		WHERE ea.assignment_id = $1
+		ORDER BY ea.created_at DESC, ea.id DESC
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetExtensionHistory - Failed to get audit")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// isGroupPeerReviewViolation reports whether err comes from the check that keeps peer review off group assignments.
+func isGroupPeerReviewViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Name() == "check_violation" && pqErr.Constraint == "assignments_group_peer_review_check"
+}
+
+// isLateCutoffViolation reports whether err comes from the late_cutoff >= due_date check.
+func isLateCutoffViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Name() == "check_violation" && pqErr.Constraint == "assignments_late_cutoff_check"
+}
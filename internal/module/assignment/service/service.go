@@ -2,23 +2,36 @@ package service
 
 import (
 	"context"
+	"hacko-app/internal/infrastructure/config"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/module/assignment/entity"
 	"hacko-app/internal/module/assignment/ports"
+	"hacko-app/pkg/errmsg"
+	"slices"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
 )
 
 var _ ports.AssignmentService = &assignmentService{}
 
 type assignmentService struct {
-	repo ports.AssignmentRepository
+	repo    ports.AssignmentRepository
+	storage integStorage.Storage
 }
 
-func NewAssignmentService(repo ports.AssignmentRepository) *assignmentService {
+func NewAssignmentService(repo ports.AssignmentRepository, storage integStorage.Storage) *assignmentService {
 	return &assignmentService{
-		repo: repo,
+		repo:    repo,
+		storage: storage,
 	}
 }
 
 func (s *assignmentService) CreateAssignment(ctx context.Context, req *entity.CreateAssignmentRequest) (*entity.CreateAssignmentResponse, error) {
+	if err := validateFileTypes(req.AllowedFileTypes); err != nil {
+		return nil, err
+	}
+
 	response, err := s.repo.CreateAssignment(ctx, req)
 	if err != nil {
 		return nil, err
@@ -27,21 +40,315 @@ func (s *assignmentService) CreateAssignment(ctx context.Context, req *entity.Cr
 	return response, nil
 }
 
-func (s *assignmentService) GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) ([]entity.GetAssignmentByClassIdResponse, error) {
+func (s *assignmentService) UpdateAssignment(ctx context.Context, req *entity.UpdateAssignmentRequest) (*entity.UpdateAssignmentResponse, error) {
+	if req.AllowedFileTypes != nil {
+		if err := validateFileTypes(*req.AllowedFileTypes); err != nil {
+			return nil, err
+		}
+	}
 
-	err := s.repo.FindClass(ctx, req.ClassId)
+	if req.MaxScore != nil {
+		criteria, err := s.repo.GetRubric(ctx, req.AssignmentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if total := rubricTotal(criteria); total > *req.MaxScore {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("max_score", "max_score must not be lower than the rubric total of "+strconv.FormatFloat(total, 'f', -1, 64)+"."))
+		}
+
+		tests, err := s.repo.GetTestCases(ctx, req.AssignmentId)
+		if err != nil {
+			return nil, err
+		}
+
+		var points float64
+		for _, test := range tests {
+			points += test.Points
+		}
+
+		if points > *req.MaxScore {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("max_score", "max_score must not be lower than the test points of "+strconv.FormatFloat(points, 'f', -1, 64)+"."))
+		}
+	}
+
+	// submissions are either per student or per team, so the mode is fixed once someone submitted
+	if req.IsGroup != nil {
+		submitted, err := s.repo.HasSubmissions(ctx, req.AssignmentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if submitted {
+			return nil, errmsg.NewCustomErrors(409, errmsg.WithErrors("is_group", "is_group can't be changed once the assignment has submissions."))
+		}
+	}
+
+	response, err := s.repo.UpdateAssignment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *assignmentService) DeleteAssignment(ctx context.Context, req *entity.DeleteAssignmentRequest) error {
+	keys, err := s.repo.DeleteAssignment(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// the rows are gone with the assignment, objects that fail to delete are left for the storage cleanup
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Warn().Err(err).Str("storage_key", key).Msg("service::DeleteAssignment - Failed to delete submission file from storage")
+		}
+	}
+
+	return nil
+}
+
+func (s *assignmentService) ReplaceRubric(ctx context.Context, req *entity.ReplaceRubricRequest) (*entity.RubricResponse, error) {
+	maxScore, err := s.repo.GetAssignmentMaxScore(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the criteria are checked the way they are stored and read back
+	criteria := make([]entity.RubricCriterion, len(req.Criteria))
+	for i, criterion := range req.Criteria {
+		for _, level := range criterion.Levels {
+			criteria[i].Levels = append(criteria[i].Levels, entity.RubricLevel{Points: level.Points})
+		}
+	}
+
+	if total := rubricTotal(criteria); total > maxScore {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("criteria", "rubric total of "+strconv.FormatFloat(total, 'f', -1, 64)+" exceeds the max score of the assignment."))
+	}
+
+	if err := s.repo.ReplaceRubric(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetRubric(ctx, &entity.GetRubricRequest{UserId: req.UserId, AssignmentId: req.AssignmentId})
+}
+
+func (s *assignmentService) GetRubric(ctx context.Context, req *entity.GetRubricRequest) (*entity.RubricResponse, error) {
+	maxScore, err := s.repo.GetAssignmentMaxScore(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria, err := s.repo.GetRubric(ctx, req.AssignmentId)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := s.repo.GetAllAssignmentByClassId(ctx, req)
+	return &entity.RubricResponse{
+		AssignmentId: req.AssignmentId,
+		MaxScore:     maxScore,
+		TotalPoints:  rubricTotal(criteria),
+		Criteria:     criteria,
+	}, nil
+}
+
+func (s *assignmentService) ReplaceTestSuite(ctx context.Context, req *entity.ReplaceTestSuiteRequest) (*entity.TestSuiteResponse, error) {
+	suite, err := s.repo.GetTestSuite(ctx, req.AssignmentId)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.Language != nil && len(req.Tests) == 0 {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("tests", "tests are required to grade submissions automatically."))
+	}
+
+	var total float64
+	for _, test := range req.Tests {
+		total += test.Points
+	}
+
+	if total > suite.MaxScore {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("tests", "test points of "+strconv.FormatFloat(total, 'f', -1, 64)+" exceed the max score of the assignment."))
+	}
+
+	if err := s.repo.ReplaceTestSuite(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetTestSuite(ctx, &entity.GetTestSuiteRequest{UserId: req.UserId, AssignmentId: req.AssignmentId})
+}
+
+func (s *assignmentService) GetTestSuite(ctx context.Context, req *entity.GetTestSuiteRequest) (*entity.TestSuiteResponse, error) {
+	suite, err := s.repo.GetTestSuite(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	tests, err := s.repo.GetTestCases(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &entity.TestSuiteResponse{
+		AssignmentId: req.AssignmentId,
+		Language:     suite.Language,
+		TimeLimitMs:  suite.TimeLimitMs,
+		MemoryMb:     suite.MemoryMb,
+		MaxScore:     suite.MaxScore,
+		Tests:        make([]entity.TestCase, 0, len(tests)),
+	}
+
+	for _, test := range tests {
+		response.TotalPoints += test.Points
+
+		if test.IsHidden && suite.CreatorId != req.UserId {
+			response.HiddenTests++
+			response.HiddenPoints += test.Points
+			continue
+		}
+
+		response.Tests = append(response.Tests, test)
+	}
+
 	return response, nil
 }
 
+func (s *assignmentService) GrantExtension(ctx context.Context, req *entity.GrantExtensionRequest) (*entity.GetExtensionsResponse, error) {
+	if _, err := s.extensionDeadline(ctx, req.UserId, req.AssignmentId); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GrantExtension(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetExtensions(ctx, &entity.GetExtensionsRequest{UserId: req.UserId, AssignmentId: req.AssignmentId})
+}
+
+func (s *assignmentService) RevokeExtension(ctx context.Context, req *entity.RevokeExtensionRequest) (*entity.GetExtensionsResponse, error) {
+	if _, err := s.extensionDeadline(ctx, req.UserId, req.AssignmentId); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RevokeExtension(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetExtensions(ctx, &entity.GetExtensionsRequest{UserId: req.UserId, AssignmentId: req.AssignmentId})
+}
+
+func (s *assignmentService) GetExtensions(ctx context.Context, req *entity.GetExtensionsRequest) (*entity.GetExtensionsResponse, error) {
+	deadline, err := s.extensionDeadline(ctx, req.UserId, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	extensions, err := s.repo.GetExtensions(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.GetExtensionHistory(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetExtensionsResponse{
+		AssignmentId: req.AssignmentId,
+		DueDate:      deadline.DueDate,
+		LateCutoff:   deadline.LateCutoff,
+		Extensions:   extensions,
+		History:      history,
+	}, nil
+}
+
+// extensionDeadline returns the deadline of the assignment when the user is its creator, the only one managing extensions.
+func (s *assignmentService) extensionDeadline(ctx context.Context, userId string, assignmentId int) (*entity.AssignmentDeadline, error) {
+	deadline, err := s.repo.GetAssignmentDeadline(ctx, assignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline.CreatorId != userId {
+		log.Warn().Str("user_id", userId).Int("assignment_id", assignmentId).Msg("service::extensionDeadline - User is not the assignment creator")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the assignment creator can manage extensions"))
+	}
+
+	return deadline, nil
+}
+
+// rubricTotal is the best score reachable with the rubric, the top level of every criterion.
+func rubricTotal(criteria []entity.RubricCriterion) float64 {
+	var total float64
+	for _, criterion := range criteria {
+		var best float64
+		for _, level := range criterion.Levels {
+			best = max(best, level.Points)
+		}
+		total += best
+	}
+
+	return total
+}
+
+// validateFileTypes only accepts types that are allowed for submissions in general.
+func validateFileTypes(types []string) error {
+	errs := errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid allowed file types"))
+	for _, t := range types {
+		if !slices.Contains(config.Envs.Upload.SubmissionTypes, t) {
+			errs.Add("allowed_file_types", t+" is not an accepted submission file type.")
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+func (s *assignmentService) GetAllAssignmentByClassId(ctx context.Context, req *entity.GetAllAssignmentByClassIdRequest) (*entity.GetAllAssignmentByClassIdResponse, error) {
+
+	err := s.repo.FindClass(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.GetAllAssignmentByClassId(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetAllAssignmentByClassIdResponse{
+		Counts:      countStatuses(assignments),
+		Assignments: assignments,
+	}, nil
+}
+
+// countStatuses counts the assignments per status, outstanding are the ones the student still has to hand in.
+func countStatuses(assignments []entity.GetAssignmentByClassIdResponse) entity.AssignmentStatusCounts {
+	var counts entity.AssignmentStatusCounts
+
+	for _, assignment := range assignments {
+		switch assignment.Status {
+		case entity.StatusNotSubmitted:
+			counts.NotSubmitted++
+		case entity.StatusSubmitted:
+			counts.Submitted++
+		case entity.StatusLate:
+			counts.Late++
+		case entity.StatusGraded:
+			counts.Graded++
+		case entity.StatusMissing:
+			counts.Missing++
+		}
+	}
+
+	counts.Outstanding = counts.NotSubmitted + counts.Missing
+
+	return counts
+}
+
 func (s *assignmentService) GetAssignmentDetails(ctx context.Context, req *entity.GetAssignmentDetailsRequest) (*entity.GetAssignmentDetailsResponse, error) {
 	response, err := s.repo.GetAssignmentDetails(ctx, req)
 	if err != nil {
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+type IssueCertificateRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+type GetCertificateRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+type VerifyCertificateRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// CertificateEligibility is what decides whether a student gets the certificate of a class, CompletedAt is when
+// the last counted item was completed.
+type CertificateEligibility struct {
+	StudentName      string     `db:"student_name"`
+	ClassTitle       string     `db:"class_title"`
+	EnrollmentStatus string     `db:"enrollment_status"`
+	Progress         float64    `db:"progress"`
+	CompletedAt      *time.Time `db:"completed_at"`
+}
+
+type Certificate struct {
+	Id          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"`
+	UserId      string    `json:"user_id" db:"user_id"`
+	ClassId     int       `json:"class_id" db:"class_id"`
+	StudentName string    `json:"student_name" db:"student_name"`
+	ClassTitle  string    `json:"class_title" db:"class_title"`
+	CompletedAt time.Time `json:"completed_at" db:"completed_at"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	Size        int64     `json:"size" db:"size"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Url         string    `json:"url" db:"-"`
+}
+
+// VerifyCertificateResponse only carries the details printed on the certificate, and only for a valid code.
+type VerifyCertificateResponse struct {
+	Valid       bool       `json:"valid"`
+	Code        string     `json:"code"`
+	StudentName string     `json:"student_name,omitempty"`
+	ClassTitle  string     `json:"class_title,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	IssuedAt    *time.Time `json:"issued_at,omitempty"`
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	integStorage "hacko-app/internal/integration/storage"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/certificate/entity"
+	"hacko-app/internal/module/certificate/ports"
+	"hacko-app/internal/module/certificate/repository"
+	"hacko-app/internal/module/certificate/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type certificateHandler struct {
+	service ports.CertificateService
+}
+
+func NewCertificateHandler() *certificateHandler {
+	var handler = new(certificateHandler)
+
+	repo := repository.NewCertificateRepository(adapter.Adapters.HackoPostgres)
+	storage := integStorage.NewStorageIntegration()
+	certificateService := service.NewCertificateService(repo, storage)
+
+	handler.service = certificateService
+	return handler
+}
+
+func (h *certificateHandler) Register(router fiber.Router) {
+	// route public
+	router.Get("/certificates/verify/:code", h.VerifyCertificate)
+
+	// user routes
+	router.Post("/class/:classId/certificate", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.IssueCertificate)
+	router.Get("/class/:classId/certificate", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetCertificate)
+}
+
+func (h *certificateHandler) IssueCertificate(c *fiber.Ctx) error {
+	var (
+		req = new(entity.IssueCertificateRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::IssueCertificate - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::IssueCertificate - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.IssueCertificate(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *certificateHandler) GetCertificate(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetCertificateRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetCertificate - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetCertificate - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetCertificate(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *certificateHandler) VerifyCertificate(c *fiber.Ctx) error {
+	var (
+		req = new(entity.VerifyCertificateRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Code = c.Params("code")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::VerifyCertificate - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.VerifyCertificate(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/certificate/entity"
+)
+
+type CertificateRepository interface {
+	// users contract
+	GetEligibility(ctx context.Context, classId int, userId string) (*entity.CertificateEligibility, error)
+	GetCertificate(ctx context.Context, classId int, userId string) (*entity.Certificate, error)
+	CreateCertificate(ctx context.Context, req *entity.Certificate) (*entity.Certificate, error)
+
+	// public contract
+	GetCertificateByCode(ctx context.Context, code string) (*entity.Certificate, error)
+}
+
+type CertificateService interface {
+	// users contract
+	IssueCertificate(ctx context.Context, req *entity.IssueCertificateRequest) (*entity.Certificate, error)
+	GetCertificate(ctx context.Context, req *entity.GetCertificateRequest) (*entity.Certificate, error)
+
+	// public contract
+	VerifyCertificate(ctx context.Context, req *entity.VerifyCertificateRequest) (*entity.VerifyCertificateResponse, error)
+}
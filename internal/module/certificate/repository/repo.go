@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hacko-app/internal/module/certificate/entity"
+	"hacko-app/internal/module/certificate/ports"
+	"hacko-app/pkg/errmsg"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CertificateRepository = &certificateRepository{}
+
+type certificateRepository struct {
+	db *sqlx.DB
+}
+
+func NewCertificateRepository(db *sqlx.DB) *certificateRepository {
+	return &certificateRepository{
+		db: db,
+	}
+}
+
+// GetEligibility returns the enrollment and cached progress of the user in the class.
+func (r *certificateRepository) GetEligibility(ctx context.Context, classId int, userId string) (*entity.CertificateEligibility, error) {
+	var res entity.CertificateEligibility
+
+	query := `
+		SELECT
+			u.name AS student_name, c.title AS class_title, uc.enrollment_status, COALESCE(cp.progress, 0) AS progress,
+			(SELECT MAX(i.completed_at) FROM class_completion_items(c.id, u.id) i WHERE i.completed) AS completed_at
+		FROM users_classes uc
+		JOIN class c ON c.id = uc.class_id
+		JOIN users u ON u.id = uc.user_id
+		LEFT JOIN class_progress cp ON cp.class_id = c.id AND cp.user_id = u.id
+		WHERE uc.class_id = $1 AND uc.user_id = $2
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, classId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Str("user_id", userId).Msg("repo::GetEligibility - User not enrolled in class")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("You are not enrolled in this class"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Str("user_id", userId).Msg("repo::GetEligibility - Failed to get enrollment")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetCertificate returns the certificate of the user for the class, nil when it was never issued.
+func (r *certificateRepository) GetCertificate(ctx context.Context, classId int, userId string) (*entity.Certificate, error) {
+	var res entity.Certificate
+
+	query := `
+		SELECT id, code, user_id, class_id, student_name, class_title, completed_at, storage_key, size, created_at
+		FROM certificates
+		WHERE class_id = $1 AND user_id = $2
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, classId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Int("class_id", classId).Str("user_id", userId).Msg("repo::GetCertificate - Failed to get certificate")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// CreateCertificate stores an issued certificate, nil is returned when one was issued for the user and class in the
+// meantime.
+func (r *certificateRepository) CreateCertificate(ctx context.Context, req *entity.Certificate) (*entity.Certificate, error) {
+	var res entity.Certificate
+
+	query := `
+		INSERT INTO certificates (code, user_id, class_id, student_name, class_title, completed_at, storage_key, size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, class_id) DO NOTHING
+		RETURNING id, code, user_id, class_id, student_name, class_title, completed_at, storage_key, size, created_at
+	`
+
+	err := r.db.GetContext(ctx, &res, query, req.Code, req.UserId, req.ClassId, req.StudentName, req.ClassTitle, req.CompletedAt, req.StorageKey, req.Size)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateCertificate - Failed to create certificate")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetCertificateByCode returns the certificate with the verification code, nil when there is none.
+func (r *certificateRepository) GetCertificateByCode(ctx context.Context, code string) (*entity.Certificate, error) {
+	var res entity.Certificate
+
+	query := `
+		SELECT id, code, user_id, class_id, student_name, class_title, completed_at, storage_key, size, created_at
+		FROM certificates
+		WHERE code = $1
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("code", code).Msg("repo::GetCertificateByCode - Failed to get certificate")
+		return nil, err
+	}
+
+	return &res, nil
+}
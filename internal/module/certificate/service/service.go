@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"hacko-app/internal/infrastructure/config"
+	integStorage "hacko-app/internal/integration/storage"
+	storageEntity "hacko-app/internal/integration/storage/entity"
+	"hacko-app/internal/module/certificate/entity"
+	"hacko-app/internal/module/certificate/ports"
+	"hacko-app/pkg"
+	"hacko-app/pkg/errmsg"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CertificateService = &certificateService{}
+
+type certificateService struct {
+	repo    ports.CertificateRepository
+	storage integStorage.Storage
+}
+
+func NewCertificateService(repo ports.CertificateRepository, storage integStorage.Storage) *certificateService {
+	return &certificateService{
+		repo:    repo,
+		storage: storage,
+	}
+}
+
+// certificateCodeLength is the number of characters of a verification code, dashes not included.
+const certificateCodeLength = 12
+
+// IssueCertificate returns the certificate of a completed class, generating it the first time. A class is completed
+// when the enrollment is and the progress under the completion rules of the class reached 100%.
+func (s *certificateService) IssueCertificate(ctx context.Context, req *entity.IssueCertificateRequest) (*entity.Certificate, error) {
+	existing, err := s.repo.GetCertificate(ctx, req.ClassId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return s.signCertificate(ctx, existing)
+	}
+
+	eligibility, err := s.repo.GetEligibility(ctx, req.ClassId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if eligibility.EnrollmentStatus != "completed" || eligibility.Progress < 100 {
+		log.Warn().Any("payload", req).Any("eligibility", eligibility).Msg("service::IssueCertificate - Class not completed")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Complete the class to get its certificate"))
+	}
+
+	certificate := &entity.Certificate{
+		Code:        pkg.GenerateCode(certificateCodeLength),
+		UserId:      req.UserId,
+		ClassId:     req.ClassId,
+		StudentName: eligibility.StudentName,
+		ClassTitle:  eligibility.ClassTitle,
+		CompletedAt: time.Now(),
+	}
+	if eligibility.CompletedAt != nil {
+		certificate.CompletedAt = *eligibility.CompletedAt
+	}
+
+	var buf bytes.Buffer
+	if err := pkg.WritePDF(&buf, certificatePage(certificate)); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("service::IssueCertificate - Failed to write certificate")
+		return nil, err
+	}
+
+	certificate.Size = int64(buf.Len())
+	uploaded, err := s.storage.Put(ctx, &storageEntity.PutObjectRequest{
+		Key:         integStorage.Key(integStorage.VisibilityPrivate, "certificates", strconv.Itoa(req.ClassId), certificate.Code+".pdf"),
+		Body:        &buf,
+		Size:        certificate.Size,
+		ContentType: pkg.MimePDF,
+	})
+	if err != nil {
+		return nil, err
+	}
+	certificate.StorageKey = uploaded.Key
+
+	created, err := s.repo.CreateCertificate(ctx, certificate)
+	if err != nil {
+		s.deleteObject(ctx, uploaded.Key)
+		return nil, err
+	}
+
+	// a concurrent request issued it first, theirs is the one that counts
+	if created == nil {
+		s.deleteObject(ctx, uploaded.Key)
+
+		created, err = s.repo.GetCertificate(ctx, req.ClassId, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.signCertificate(ctx, created)
+}
+
+func (s *certificateService) GetCertificate(ctx context.Context, req *entity.GetCertificateRequest) (*entity.Certificate, error) {
+	certificate, err := s.repo.GetCertificate(ctx, req.ClassId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if certificate == nil {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Certificate not found"))
+	}
+
+	return s.signCertificate(ctx, certificate)
+}
+
+// VerifyCertificate reports whether the code belongs to an issued certificate, codes are not case sensitive.
+func (s *certificateService) VerifyCertificate(ctx context.Context, req *entity.VerifyCertificateRequest) (*entity.VerifyCertificateResponse, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+
+	certificate, err := s.repo.GetCertificateByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if certificate == nil {
+		return &entity.VerifyCertificateResponse{Code: code}, nil
+	}
+
+	return &entity.VerifyCertificateResponse{
+		Valid:       true,
+		Code:        certificate.Code,
+		StudentName: certificate.StudentName,
+		ClassTitle:  certificate.ClassTitle,
+		CompletedAt: &certificate.CompletedAt,
+		IssuedAt:    &certificate.CreatedAt,
+	}, nil
+}
+
+// signCertificate sets a short lived url, certificates are private and only reachable through it.
+func (s *certificateService) signCertificate(ctx context.Context, certificate *entity.Certificate) (*entity.Certificate, error) {
+	url, err := s.storage.Presign(ctx, certificate.StorageKey, time.Duration(config.Envs.HackoStorage.UrlExpiry)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate.Url = url
+	return certificate, nil
+}
+
+func (s *certificateService) deleteObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Warn().Err(err).Str("storage_key", key).Msg("service::deleteObject - Failed to delete object from storage")
+	}
+}
+
+// certificatePage lays the certificate out on a landscape A4 page.
+func certificatePage(certificate *entity.Certificate) pkg.PDFPage {
+	const (
+		width  = 842
+		height = 595
+		center = width / 2
+	)
+
+	var (
+		accent    = [3]float64{0.12, 0.29, 0.55}
+		muted     = [3]float64{0.35, 0.35, 0.35}
+		verifyUrl = strings.TrimRight(config.Envs.App.BaseURL, "/") + "/users/certificates/verify/" + certificate.Code
+	)
+
+	return pkg.PDFPage{
+		Width:  width,
+		Height: height,
+		Title:  "Certificate of Completion - " + certificate.ClassTitle,
+		Rects: []pkg.PDFRect{
+			{X: 24, Y: 24, Width: width - 48, Height: height - 48, LineWidth: 3, Color: accent},
+			{X: 34, Y: 34, Width: width - 68, Height: height - 68, LineWidth: 0.75, Color: accent},
+		},
+		Texts: []pkg.PDFText{
+			{X: center, Y: 470, Size: 30, Bold: true, Center: true, Color: accent, Text: "CERTIFICATE OF COMPLETION"},
+			{X: center, Y: 410, Size: 14, Center: true, Color: muted, Text: "This certifies that"},
+			{X: center, Y: 360, Size: 34, Bold: true, Center: true, MaxWidth: 700, Text: certificate.StudentName},
+			{X: center, Y: 315, Size: 14, Center: true, Color: muted, Text: "has successfully completed the class"},
+			{X: center, Y: 275, Size: 24, Bold: true, Center: true, MaxWidth: 700, Text: certificate.ClassTitle},
+			{X: center, Y: 210, Size: 13, Center: true, Text: "Completed on " + certificate.CompletedAt.UTC().Format("2 January 2006")},
+			{X: center, Y: 92, Size: 11, Bold: true, Center: true, Text: "Verification code: " + certificate.Code},
+			{X: center, Y: 74, Size: 9, Center: true, MaxWidth: 760, Color: muted, Text: "Verify at " + verifyUrl},
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"hacko-app/pkg/types"
+	"mime/multipart"
 	"time"
 )
 
@@ -23,17 +25,18 @@ type CreateClassResponse struct {
 }
 
 type GetClassResponse struct {
-	ID               int       `json:"id" db:"id"`
-	Title            string    `json:"title" db:"title"`
-	Description      string    `json:"description,omitempty" db:"description"`
-	Image            string    `json:"image,omitempty" db:"image"`
-	Video            string    `json:"video,omitempty" db:"video"`
-	Status           string    `json:"status" db:"status"`
-	StatusEnrollment string    `json:"status_enrollment" db:"status_enrollment"`
-	Progress         string    `json:"progress" db:"progress"`
-	CreatorClassID   string    `json:"creator_class_id" db:"creator_class_id"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	ID               int                 `json:"id" db:"id"`
+	Title            string              `json:"title" db:"title"`
+	Description      string              `json:"description,omitempty" db:"description"`
+	Image            string              `json:"image,omitempty" db:"image"`
+	ImageVariants    types.ImageVariants `json:"image_variants" db:"image_variants"`
+	Video            string              `json:"video,omitempty" db:"video"`
+	Status           string              `json:"status" db:"status"`
+	StatusEnrollment string              `json:"status_enrollment" db:"status_enrollment"`
+	Progress         string              `json:"progress" db:"progress"`
+	CreatorClassID   string              `json:"creator_class_id" db:"creator_class_id"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
 }
 
 type GetAllClassesResponse struct {
@@ -47,11 +50,15 @@ type GetOverviewClassByIdRequest struct {
 }
 
 type GetModuleResponse struct {
-	Id          int      `json:"id" db:"id"`
-	Title       string   `json:"title" db:"title"`
-	Content     string   `json:"content" db:"content"`
-	Attachments []string `json:"attachments" db:"attachments"`
-	Videos      []string `json:"videos" db:"attachments"`
+	Id             int             `json:"id" db:"id"`
+	Title          string          `json:"title" db:"title"`
+	Content        string          `json:"content" db:"content"`
+	Attachments    []string        `json:"attachments" db:"attachments"`
+	Videos         []string        `json:"videos" db:"attachments"`
+	Status         string          `json:"status" db:"status"`
+	ProgressStatus string          `json:"progress_status" db:"progress_status"`
+	VideoProgress  []VideoProgress `json:"video_progress"`
+	Resume         *VideoProgress  `json:"resume"` // the video watched last
 }
 
 type GetMaterialResponse struct {
@@ -65,6 +72,7 @@ type GetOverviewClassByIdResponse struct {
 	Title            string                `json:"title" db:"title"`
 	Description      string                `json:"description,omitempty" db:"description"`
 	Image            string                `json:"image,omitempty" db:"image"`
+	ImageVariants    types.ImageVariants   `json:"image_variants" db:"image_variants"`
 	Video            string                `json:"video,omitempty" db:"video"`
 	Status           string                `json:"status" db:"status"`
 	EnrollmentStatus string                `json:"enrollment_status" db:"enrollment_status"`
@@ -72,6 +80,7 @@ type GetOverviewClassByIdResponse struct {
 	CreatedAt        time.Time             `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time             `json:"updated_at" db:"updated_at"`
 	Syllabus         []GetMaterialResponse `json:"syllabus"`
+	Progress         *ClassProgress        `json:"progress"`
 }
 
 type EnrollClassRequest struct {
@@ -101,6 +110,25 @@ type UpdateClassResponse struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+type UploadClassCoverRequest struct {
+	Id     int             `json:"id" validate:"required"`
+	UserId string          `validate:"required"`
+	File   *multipart.Part `form:"image" validate:"required"` // read as it arrives
+}
+
+type UpdateClassCoverRequest struct {
+	Id            int
+	UserId        string
+	Image         string
+	ImageVariants types.ImageVariants
+}
+
+type UploadClassCoverResponse struct {
+	Id            int                 `json:"id"`
+	Image         string              `json:"image"`
+	ImageVariants types.ImageVariants `json:"image_variants"`
+}
+
 type DeleteClassRequest struct {
 	Id     int    `json:"id" validate:"required"`
 	UserId string `validate:"required"`
@@ -171,24 +199,34 @@ type AddUsersToClassResponse struct {
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TrackModuleRequest moves the progress of a module forward, a module that is done stays done.
 type TrackModuleRequest struct {
-	UserId  string `json:"user_id" validate:"required"`
-	ClassId string `json:"class_id" validate:"required"`
-	// UsersClassesId string  `json:"users_classes_id" validate:"required"`
-	MaterialId string `json:"material_id" validate:"required"`
-	ModuleId   string `json:"module_id" validate:"required"`
-	// QuizId         *string `json:"quiz_id,omitempty"`
-	StatusProgress string   `json:"status_progress"`
-	Progress       *float64 `json:"progress"`
+	UserId         string `json:"user_id" validate:"required"`
+	ClassId        string `json:"class_id" validate:"required"`
+	MaterialId     string `json:"material_id" validate:"required"`
+	ModuleId       string `json:"module_id" validate:"required"`
+	StatusProgress string `json:"status_progress" validate:"omitempty,oneof=on_progress done"`
 }
 
 type TrackModuleResponse struct {
-	Id             int       `json:"id" db:"id"`
-	UserId         string    `json:"user_id" db:"user_id"`
-	Progress       *float64  `json:"progress" db:"progress"`
-	StatusProgress string    `json:"status_progress" db:"status_progress"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	Id             int        `json:"id" db:"id"`
+	UserId         string     `json:"user_id" db:"user_id"`
+	ModuleId       int        `json:"module_id" db:"module_id"`
+	Progress       *float64   `json:"progress" db:"progress"`
+	CompletedItems int        `json:"completed_items" db:"completed_items"`
+	TotalItems     int        `json:"total_items" db:"total_items"`
+	StatusProgress string     `json:"status_progress" db:"status_progress"`
+	StartedAt      time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ClassProgress is the cached progress of a user in a class, items are whatever the completion rules of the class count.
+type ClassProgress struct {
+	CompletedItems int     `json:"completed_items" db:"completed_items"`
+	TotalItems     int     `json:"total_items" db:"total_items"`
+	Progress       float64 `json:"progress" db:"progress"`
 }
 
 type GetProgressRequest struct {
@@ -196,6 +234,89 @@ type GetProgressRequest struct {
 	ClassId string `json:"class_id" validate:"required"`
 }
 
+// VideoHeartbeatRequest reports the playback position of a module video, sent every few seconds while it plays.
+type VideoHeartbeatRequest struct {
+	UserId     string  `json:"user_id" validate:"required"`
+	ClassId    string  `json:"class_id" validate:"required"`
+	MaterialId string  `json:"material_id" validate:"required"`
+	ModuleId   string  `json:"module_id" validate:"required"`
+	Video      string  `json:"video" validate:"required"`
+	Position   float64 `json:"position" validate:"gte=0,ltefield=Duration"`
+	Duration   float64 `json:"duration" validate:"required,gt=0,lte=86400"`
+}
+
+// VideoProgress is how much of a module video a user watched, Position is where playback resumes.
+type VideoProgress struct {
+	Video          string    `json:"video" db:"video"`
+	Position       float64   `json:"position" db:"position_seconds"`
+	Duration       float64   `json:"duration" db:"duration_seconds"`
+	WatchedSeconds int       `json:"watched_seconds" db:"watched_seconds"`
+	WatchedPercent float64   `json:"watched_percent" db:"watched_percent"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type VideoHeartbeatResponse struct {
+	VideoProgress
+	ModuleId       int    `json:"module_id"`
+	StatusProgress string `json:"status_progress"`
+	// Progressed is set when the module moved forward or the video was not watched yet today, other heartbeats
+	// are not worth an event.
+	Progressed bool `json:"-"`
+}
+
+// CompletionRules decide which items of a class count towards its completion, a class without rules counts its
+// published modules only.
+type CompletionRules struct {
+	ClassId                  int        `json:"class_id" db:"class_id"`
+	CountModules             bool       `json:"count_modules" db:"count_modules"`
+	CountQuizzes             bool       `json:"count_quizzes" db:"count_quizzes"`
+	CountAssignments         bool       `json:"count_assignments" db:"count_assignments"`
+	MinQuizScore             float64    `json:"min_quiz_score" db:"min_quiz_score"`
+	RequireGradedAssignments bool       `json:"require_graded_assignments" db:"require_graded_assignments"`
+	VideoWatchPercent        float64    `json:"video_watch_percent" db:"video_watch_percent"`
+	UpdatedAt                *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateCompletionRulesRequest struct {
+	UserId                   string  `json:"user_id" validate:"required"`
+	ClassId                  int     `json:"class_id" validate:"required"`
+	CountModules             bool    `json:"count_modules"`
+	CountQuizzes             bool    `json:"count_quizzes"`
+	CountAssignments         bool    `json:"count_assignments"`
+	MinQuizScore             float64 `json:"min_quiz_score" validate:"gte=0,lte=100"` // percent of the quiz max score
+	RequireGradedAssignments bool    `json:"require_graded_assignments"`
+	VideoWatchPercent        float64 `json:"video_watch_percent" validate:"required,gt=0,lte=100"` // of every video before its module is done
+}
+
+type GetCompletionRulesRequest struct {
+	UserId  string `json:"user_id" validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+type GetCompletionRequest struct {
+	UserId  string `json:"user_id" validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+// CompletionItem is a module, quiz or assignment that counts towards the class. Score is the best quiz score or the
+// assignment grade.
+type CompletionItem struct {
+	Type        string     `json:"type" db:"item_type"`
+	Id          int        `json:"id" db:"item_id"`
+	Title       string     `json:"title" db:"title"`
+	Completed   bool       `json:"completed" db:"completed"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	Score       *float64   `json:"score" db:"score"`
+	MaxScore    *float64   `json:"max_score" db:"max_score"`
+}
+
+type GetCompletionResponse struct {
+	Rules     *CompletionRules `json:"rules"`
+	Progress  ClassProgress    `json:"progress"`
+	Completed bool             `json:"completed"`
+	Items     []CompletionItem `json:"items"`
+}
+
 type GetAllClassAdminRequest struct {
 	UserId string `json:"user_id" validate:"required"`
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"hacko-app/internal/adapter"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/middleware"
 	"hacko-app/internal/module/class/entity"
 	"hacko-app/internal/module/class/ports"
@@ -23,7 +24,8 @@ func NewClassHandler() *classHandler {
 	var handler = new(classHandler)
 
 	repo := repository.NewClassRepository(adapter.Adapters.HackoPostgres)
-	classService := service.NewClassService(repo)
+	storage := integStorage.NewStorageIntegration()
+	classService := service.NewClassService(repo, storage)
 
 	handler.service = classService
 	return handler
@@ -37,12 +39,17 @@ func (h *classHandler) Register(router fiber.Router) {
 	// user routes
 	router.Post("/class/:id/enroll", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.EnrollClass)
 	router.Post("/class/:classId/materials/:materialId/modules/:moduleId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.TrackModule)
+	router.Post("/class/:classId/materials/:materialId/modules/:moduleId/videos/heartbeat", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.VideoHeartbeat)
+	router.Get("/class/:id/completion", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetCompletion)
+	router.Get("/class/:id/completion-rules", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetCompletionRules)
 
 	// teacher routes
 	router.Post("/class", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.CreateClassregister)
 	router.Put("/class/:id", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.UpdateClass)
 	router.Delete("/class/:id", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.DeleteClass)
 	router.Patch("/class/:id", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.UpdateVisibilityClass)
+	router.Put("/class/:id/cover", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.UploadClassCover).Name(middleware.UploadRoute(integStorage.CategoryCover))
+	router.Put("/class/:id/completion-rules", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.UpdateCompletionRules)
 	router.Get("/class/:id/users", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.GetAllUsersEnrolledClass)
 	router.Delete("/class/:id/users/:studentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.DeleteStudentClass)
 	router.Get("/class/:classId/users-not-enrolled/", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.GetAllUsersNotEnrolledClass)
@@ -184,6 +191,48 @@ func (h *classHandler) UpdateClass(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(res)
 }
 
+func (h *classHandler) UploadClassCover(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UploadClassCoverRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UploadClassCover - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.Id = reqId
+
+	file, err := middleware.FormFile(c, "image")
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UploadClassCover - Failed to get image from form")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	req.File = file
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UploadClassCover - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UploadClassCover(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
 func (h *classHandler) DeleteClass(c *fiber.Ctx) error {
 	var (
 		req = new(entity.DeleteClassRequest)
@@ -382,6 +431,14 @@ func (h *classHandler) TrackModule(c *fiber.Ctx) error {
 		l   = middleware.GetLocals(c)
 	)
 
+	// the body is optional, tracking without one marks the module as done
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			log.Warn().Err(err).Msg("handler::TrackModule - Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+		}
+	}
+
 	req.UserId = l.GetUserId()
 	req.ClassId = c.Params("classId")
 	req.MaterialId = c.Params("materialId")
@@ -402,6 +459,140 @@ func (h *classHandler) TrackModule(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.Success(res, "Successfully tracking progress"))
 }
 
+func (h *classHandler) VideoHeartbeat(c *fiber.Ctx) error {
+	var (
+		req = new(entity.VideoHeartbeatRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::VideoHeartbeat - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+	req.ClassId = c.Params("classId")
+	req.MaterialId = c.Params("materialId")
+	req.ModuleId = c.Params("moduleId")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::VideoHeartbeat - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.VideoHeartbeat(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *classHandler) UpdateCompletionRules(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateCompletionRulesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateCompletionRules - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	classId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateCompletionRules - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.UserId = l.GetUserId()
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateCompletionRules - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateCompletionRules(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, "Successfully updated completion rules"))
+}
+
+func (h *classHandler) GetCompletionRules(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetCompletionRulesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	classId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetCompletionRules - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.UserId = l.GetUserId()
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetCompletionRules - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetCompletionRules(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *classHandler) GetCompletion(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetCompletionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	classId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetCompletion - Failed to parse id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.UserId = l.GetUserId()
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetCompletion - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetCompletion(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
 func (h *classHandler) GetAllClassAdmin(c *fiber.Ctx) error {
 	var (
 		req = new(entity.GetAllClassAdminRequest)
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"hacko-app/internal/module/class/entity"
+	"hacko-app/pkg/types"
 )
 
 type ClassRepository interface {
@@ -10,7 +11,8 @@ type ClassRepository interface {
 	// utils repo contract
 	FindClass(ctx context.Context, id string) error
 	CheckEnrollment(ctx context.Context, req *entity.AddUsersToClassRequest) error
-	GetAllSyllabus(ctx context.Context, classId string) ([]entity.GetMaterialResponse, error)
+	GetAllSyllabus(ctx context.Context, req *entity.GetOverviewClassByIdRequest) ([]entity.GetMaterialResponse, error)
+	GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error)
 
 	// admin repo contract
 	CreateClass(ctx context.Context, req *entity.CreateClassRequest) (*entity.CreateClassResponse, error)
@@ -22,13 +24,21 @@ type ClassRepository interface {
 	GetAllStudentNotEnrolledClass(ctx context.Context, req *entity.GetAllUserNotEnrolledClassRequest) (*entity.GetAllUserNotEnrolledClassResponse, error)
 	AddUserToClass(ctx context.Context, req *entity.AddUsersToClassRequest) (*entity.AddUsersToClassResponse, error)
 	GetAllClassAdmin(ctx context.Context, req *entity.GetAllClassAdminRequest) (*[]entity.GetAllClassAdminResponse, error)
+	UpdateClassCover(ctx context.Context, req *entity.UpdateClassCoverRequest) (types.ImageVariants, error)
 
 	// users repo contract
 	GetAllClasses(ctx context.Context) (*entity.GetAllClassesResponse, error)
 	GetOverviewClassById(ctx context.Context, req *entity.GetOverviewClassByIdRequest) (*entity.GetOverviewClassByIdResponse, error)
 	EnrollClass(ctx context.Context, req *entity.EnrollClassRequest) error
 	TrackModule(ctx context.Context, req *entity.TrackModuleRequest) (*entity.TrackModuleResponse, error)
-	GetProgress(ctx context.Context, req *entity.GetProgressRequest) (*float64, error)
+	VideoHeartbeat(ctx context.Context, req *entity.VideoHeartbeatRequest) (*entity.VideoHeartbeatResponse, error)
+	GetProgress(ctx context.Context, req *entity.GetProgressRequest) (*entity.ClassProgress, error)
+	FindClassMember(ctx context.Context, classId int, userId string) error
+	GetCompletionRules(ctx context.Context, classId int) (*entity.CompletionRules, error)
+	GetCompletionItems(ctx context.Context, req *entity.GetCompletionRequest) ([]entity.CompletionItem, error)
+
+	// completion rules repo contract
+	UpdateCompletionRules(ctx context.Context, req *entity.UpdateCompletionRulesRequest) (*entity.CompletionRules, error)
 }
 
 type ClassService interface {
@@ -43,10 +53,15 @@ type ClassService interface {
 	GetAllStudentNotEnrolledClass(ctx context.Context, req *entity.GetAllUserNotEnrolledClassRequest) (*entity.GetAllUserNotEnrolledClassResponse, error)
 	AddUserToClass(ctx context.Context, req *entity.AddUsersToClassRequest) (*entity.AddUsersToClassResponse, error)
 	GetAllClassAdmin(ctx context.Context, req *entity.GetAllClassAdminRequest) (*[]entity.GetAllClassAdminResponse, error)
+	UploadClassCover(ctx context.Context, req *entity.UploadClassCoverRequest) (*entity.UploadClassCoverResponse, error)
+	UpdateCompletionRules(ctx context.Context, req *entity.UpdateCompletionRulesRequest) (*entity.CompletionRules, error)
 
 	// users service contract
 	GetAllClasses(ctx context.Context) (*entity.GetAllClassesResponse, error)
 	GetOverviewClassById(ctx context.Context, req *entity.GetOverviewClassByIdRequest) (*entity.GetOverviewClassByIdResponse, error)
 	EnrollClass(ctx context.Context, req *entity.EnrollClassRequest) error
 	TrackModule(ctx context.Context, req *entity.TrackModuleRequest) (*entity.TrackModuleResponse, error)
+	VideoHeartbeat(ctx context.Context, req *entity.VideoHeartbeatRequest) (*entity.VideoHeartbeatResponse, error)
+	GetCompletionRules(ctx context.Context, req *entity.GetCompletionRulesRequest) (*entity.CompletionRules, error)
+	GetCompletion(ctx context.Context, req *entity.GetCompletionRequest) (*entity.GetCompletionResponse, error)
 }
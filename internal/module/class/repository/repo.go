@@ -6,7 +6,11 @@ import (
 	"errors"
 	"hacko-app/internal/module/class/entity"
 	"hacko-app/internal/module/class/ports"
+	storageRepo "hacko-app/internal/module/storage/repository"
+	"hacko-app/pkg"
 	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/types"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -25,6 +29,29 @@ func NewClassRepository(db *sqlx.DB) *classRepository {
 	}
 }
 
+// FindClassByCreator checks that the class exists and was created by the user. It is the ownership check of the
+// pages of other modules only the creator of a class may see, they pass their own database.
+func FindClassByCreator(ctx context.Context, db sqlx.QueryerContext, classId int, userId string) error {
+	query := `SELECT creator_class_id FROM class WHERE id = $1`
+
+	var creatorId string
+	if err := db.QueryRowxContext(ctx, query, classId).Scan(&creatorId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Msg("repo::FindClassByCreator - Class not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::FindClassByCreator - Failed to query class")
+		return err
+	}
+
+	if creatorId != userId {
+		log.Warn().Int("class_id", classId).Str("user_id", userId).Msg("repo::FindClassByCreator - User is not the class creator")
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the class creator can access this class"))
+	}
+
+	return nil
+}
+
 func (r *classRepository) CreateClass(ctx context.Context, req *entity.CreateClassRequest) (*entity.CreateClassResponse, error) {
 	var res = new(entity.CreateClassResponse)
 
@@ -67,19 +94,20 @@ func (r *classRepository) GetAllClasses(ctx context.Context) (*entity.GetAllClas
 			c.title,
 			c.description,
 			c.image,
+			c.image_variants,
 			c.video,
 			c.status,
 			c.creator_class_id,
 			c.created_at,
 			c.updated_at,
 			COALESCE(uc.enrollment_status, 'not_enrolled') AS status_enrollment,
-			COALESCE(up.progress, '0') AS progress
+			COALESCE(cp.progress, 0) AS progress
 		FROM 
 			class c
 		LEFT JOIN 
 			users_classes uc ON c.id = uc.class_id
 		LEFT JOIN 
-			users_progress up ON c.id = up.class_id
+			class_progress cp ON cp.class_id = c.id AND cp.user_id = uc.user_id
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -98,6 +126,7 @@ func (r *classRepository) GetAllClasses(ctx context.Context) (*entity.GetAllClas
 			&class.Title,
 			&class.Description,
 			&class.Image,
+			&class.ImageVariants,
 			&class.Video,
 			&class.Status,
 			&class.CreatorClassID,
@@ -152,7 +181,7 @@ func (r *classRepository) FindClass(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *classRepository) GetAllSyllabus(ctx context.Context, classId string) ([]entity.GetMaterialResponse, error) {
+func (r *classRepository) GetAllSyllabus(ctx context.Context, req *entity.GetOverviewClassByIdRequest) ([]entity.GetMaterialResponse, error) {
 	materialsQuery := `
         SELECT 
             id, 
@@ -163,9 +192,9 @@ func (r *classRepository) GetAllSyllabus(ctx context.Context, classId string) ([
             class_id = $1
     `
 
-	materialsRows, err := r.db.QueryContext(ctx, materialsQuery, classId)
+	materialsRows, err := r.db.QueryContext(ctx, materialsQuery, req.Id)
 	if err != nil {
-		log.Error().Err(err).Str("class_id", classId).Msg("repo::GetAllSyllabus - Failed to query materials")
+		log.Error().Err(err).Str("class_id", req.Id).Msg("repo::GetAllSyllabus - Failed to query materials")
 		return nil, err
 	}
 	defer materialsRows.Close()
@@ -183,17 +212,23 @@ func (r *classRepository) GetAllSyllabus(ctx context.Context, classId string) ([
 
 		modulesQuery := `
             SELECT 
-                id, 
-                title, 
-                content, 
-                attachments, 
-                videos 
+                m.id, 
+                m.title, 
+                m.content, 
+                m.attachments, 
+                m.videos,
+                m.status,
+                COALESCE(up.status::TEXT, 'not_started') AS progress_status
             FROM 
-                modules 
+                modules m
+            LEFT JOIN
+                users_progress up ON up.module_id = m.id AND up.user_id = $2
             WHERE 
-                materials_id = $1
+                m.materials_id = $1 AND
+                (m.status = 'published' OR m.creator_modules_id = $2)
         `
-		modulesRows, err := r.db.QueryContext(ctx, modulesQuery, material.Id)
+		// draft modules are only listed for their creator
+		modulesRows, err := r.db.QueryContext(ctx, modulesQuery, material.Id, req.UserId)
 		if err != nil {
 			log.Error().Err(err).Int("material_id", material.Id).Msg("repo::GetAllSyllabus - Failed to query modules")
 			return nil, err
@@ -211,6 +246,8 @@ func (r *classRepository) GetAllSyllabus(ctx context.Context, classId string) ([
 				&module.Content,
 				pq.Array(&attachments),
 				pq.Array(&videos),
+				&module.Status,
+				&module.ProgressStatus,
 			)
 			if err != nil {
 				log.Error().Err(err).Msg("repo::GetAllSyllabus - Failed to scan module data")
@@ -224,6 +261,38 @@ func (r *classRepository) GetAllSyllabus(ctx context.Context, classId string) ([
 
 		modulesRows.Close()
 
+		// the latest video first, it is where the module resumes
+		videosQuery := `
+			SELECT
+				vp.module_id, vp.video, vp.position_seconds, vp.duration_seconds, vp.watched_seconds,
+				ROUND(LEAST(vp.watched_seconds * 100.0 / vp.duration_seconds, 100), 2) AS watched_percent, vp.updated_at
+			FROM module_video_progress vp
+			JOIN modules m ON m.id = vp.module_id
+			WHERE m.materials_id = $1 AND vp.user_id = $2 AND vp.video = ANY(m.videos)
+			ORDER BY vp.updated_at DESC
+		`
+
+		var videos []struct {
+			ModuleId int `db:"module_id"`
+			entity.VideoProgress
+		}
+		if err := r.db.SelectContext(ctx, &videos, videosQuery, material.Id, req.UserId); err != nil {
+			log.Error().Err(err).Int("material_id", material.Id).Msg("repo::GetAllSyllabus - Failed to query video progress")
+			return nil, err
+		}
+
+		for i := range modules {
+			modules[i].VideoProgress = make([]entity.VideoProgress, 0)
+			for _, video := range videos {
+				if video.ModuleId == modules[i].Id {
+					modules[i].VideoProgress = append(modules[i].VideoProgress, video.VideoProgress)
+				}
+			}
+			if len(modules[i].VideoProgress) > 0 {
+				modules[i].Resume = &modules[i].VideoProgress[0]
+			}
+		}
+
 		material.Modules = modules
 		materials = append(materials, material)
 	}
@@ -246,6 +315,7 @@ func (r *classRepository) GetOverviewClassById(ctx context.Context, req *entity.
 			c.title,
 			c.description,
 			c.image,
+			c.image_variants,
 			c.video,
 			c.status,
 			c.created_at,
@@ -582,112 +652,426 @@ func (r *classRepository) AddUserToClass(ctx context.Context, req *entity.AddUse
 	return &response, nil
 }
 
-func (r *classRepository) TrackModule(ctx context.Context, req *entity.TrackModuleRequest) (*entity.TrackModuleResponse, error) {
-	query := `
-		INSERT INTO users_progress (
-			user_id, class_id, material_id, module_id, status, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, NOW(), NOW()
-		)
-		RETURNING id, user_id, progress, status, created_at, updated_at;
-	`
-	req.StatusProgress = "done"
-
-	// Eksekusi query
-	row := r.db.QueryRowContext(ctx, query,
-		req.UserId,
-		req.ClassId,
-		// req.UsersClassesId,
-		req.MaterialId,
-		req.ModuleId,
-		// req.QuizId,
-		req.StatusProgress,
-		// req.Progress,
-	)
+// trackModuleQuery upserts the progress of user $1 in module $4 with status $5, the module must be published and
+// belong to material $3 of class $2.
+const trackModuleQuery = `
+	INSERT INTO users_progress (user_id, class_id, material_id, module_id, status, started_at, completed_at)
+	SELECT $1, mat.class_id, mat.id, m.id, $5::progress_status, NOW(), CASE WHEN $5::progress_status = 'done' THEN NOW() END
+	FROM modules m
+	JOIN materials mat ON mat.id = m.materials_id
+	WHERE m.id = $4 AND mat.id = $3 AND mat.class_id = $2 AND m.status = 'published'
+	ON CONFLICT (user_id, module_id) DO UPDATE
+	SET
+		status = GREATEST(users_progress.status, EXCLUDED.status),
+		completed_at = COALESCE(users_progress.completed_at, EXCLUDED.completed_at),
+		updated_at = CASE WHEN EXCLUDED.status > users_progress.status THEN NOW() ELSE users_progress.updated_at END
+	RETURNING id, user_id, module_id, status, started_at, completed_at, created_at, updated_at
+`
+
+// TrackModule upserts the progress of the module, its status only moves forward so tracking twice changes nothing,
+// and recomputes the class progress of the user in the same transaction.
+func (r *classRepository) TrackModule(ctx context.Context, req *entity.TrackModuleRequest) (res *entity.TrackModuleResponse, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::TrackModule - Failed to rollback transaction")
+			}
+		}
+	}()
 
-	// Menyiapkan respons
-	var res entity.TrackModuleResponse
-	err := row.Scan(
+	res = new(entity.TrackModuleResponse)
+	err = tx.QueryRowContext(ctx, trackModuleQuery, req.UserId, req.ClassId, req.MaterialId, req.ModuleId, req.StatusProgress).Scan(
 		&res.Id,
 		&res.UserId,
-		&res.Progress,
+		&res.ModuleId,
 		&res.StatusProgress,
+		&res.StartedAt,
+		&res.CompletedAt,
 		&res.CreatedAt,
 		&res.UpdatedAt,
 	)
 	if err != nil {
-		pqErr, ok := err.(*pq.Error)
-		if !ok {
-			log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to track module")
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::TrackModule - Module not found in material and class")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found in the class"))
 			return nil, err
 		}
-
-		switch pqErr.Code.Name() {
-		case "foreign_key_violation":
-			log.Warn().Msg("repo::TrackModule - Class, Materials, Module, or user with the ID not found")
-			return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Invalid class ID, material ID, module ID, or user ID"))
-		default:
-			log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Unhandled pq.Error")
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			log.Warn().Any("payload", req).Msg("repo::TrackModule - User with the ID not found")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Invalid class ID, material ID, module ID, or user ID"))
 			return nil, err
 		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to track module")
+		return nil, err
 	}
 
-	progressReq := &entity.GetProgressRequest{
-		UserId:  req.UserId,
-		ClassId: req.ClassId,
+	if _, err = tx.ExecContext(ctx, `SELECT refresh_class_progress($1::INT, $2::UUID)`, req.ClassId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to refresh class progress")
+		return nil, err
+	}
+
+	var progress entity.ClassProgress
+	progressQuery := `
+		SELECT completed_items, total_items, progress
+		FROM class_progress
+		WHERE user_id = $1 AND class_id = $2
+	`
+
+	if err = tx.GetContext(ctx, &progress, progressQuery, req.UserId, req.ClassId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to get class progress")
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackModule - Failed to commit transaction")
+		return nil, err
 	}
 
-	progress, err := r.GetProgress(ctx, progressReq)
+	res.Progress = &progress.Progress
+	res.CompletedItems = progress.CompletedItems
+	res.TotalItems = progress.TotalItems
+
+	return res, nil
+}
+
+// VideoHeartbeat records the playback of a module video. The seconds played since the previous heartbeat are added
+// to the watched ones and once every video of the module is watched far enough the module is done. The module and
+// class progress only change, in the same transaction, when the status of the module moves forward.
+func (r *classRepository) VideoHeartbeat(ctx context.Context, req *entity.VideoHeartbeatRequest) (res *entity.VideoHeartbeatResponse, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		log.Error().Err(err).Any("payload", progressReq).Msg("repo::TrackModule - Failed to calculate progress")
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to begin transaction")
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	var module struct {
+		Id           int            `db:"id"`
+		Videos       pq.StringArray `db:"videos"`
+		WatchPercent float64        `db:"video_watch_percent"`
+	}
+
+	moduleQuery := `
+		SELECT m.id, m.videos, COALESCE(r.video_watch_percent, 90) AS video_watch_percent
+		FROM modules m
+		JOIN materials mat ON mat.id = m.materials_id
+		LEFT JOIN class_completion_rules r ON r.class_id = mat.class_id
+		WHERE m.id = $1 AND mat.id = $2 AND mat.class_id = $3 AND m.status = 'published' AND $4 = ANY(m.videos)
+	`
+
+	if err = tx.GetContext(ctx, &module, moduleQuery, req.ModuleId, req.MaterialId, req.ClassId, req.Video); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::VideoHeartbeat - Video not found in module, material and class")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Video not found in the module"))
+			return nil, err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to get module")
+		return nil, err
+	}
+
+	// the row exists before it is locked so concurrent first heartbeats of a video wait for each other
+	insertQuery := `
+		INSERT INTO module_video_progress (user_id, module_id, video, position_seconds, duration_seconds)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, module_id, video) DO NOTHING
+	`
+
+	if _, err = tx.ExecContext(ctx, insertQuery, req.UserId, module.Id, req.Video, req.Position, req.Duration); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to create video progress")
+		return nil, err
+	}
+
+	var current struct {
+		Position   float64  `db:"position_seconds"`
+		Duration   float64  `db:"duration_seconds"`
+		Watched    []byte   `db:"watched"`
+		Elapsed    *float64 `db:"elapsed"`
+		FirstToday bool     `db:"first_today"`
+	}
+
+	currentQuery := `
+		SELECT
+			position_seconds, duration_seconds, watched, EXTRACT(EPOCH FROM NOW() - last_heartbeat_at) AS elapsed,
+			last_heartbeat_at IS NULL OR last_heartbeat_at < CURRENT_DATE AS first_today
+		FROM module_video_progress
+		WHERE user_id = $1 AND module_id = $2 AND video = $3
+		FOR UPDATE
+	`
+
+	if err = tx.GetContext(ctx, &current, currentQuery, req.UserId, module.Id, req.Video); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to lock video progress")
+		return nil, err
+	}
+
+	var elapsed *time.Duration
+	if current.Elapsed != nil {
+		e := time.Duration(*current.Elapsed * float64(time.Second))
+		elapsed = &e
+	}
+
+	// the duration stored by the first heartbeat is kept, the watched percent is computed against it
+	watch := pkg.VideoWatch{Position: current.Position, Duration: current.Duration, Watched: current.Watched}
+	watch = watch.Heartbeat(req.Position, req.Duration, elapsed)
 
 	updateQuery := `
-		UPDATE users_progress
-		SET progress = $1, updated_at = NOW()
-		WHERE user_id = $2 AND class_id = $3;
+		UPDATE module_video_progress
+		SET
+			position_seconds = $4,
+			duration_seconds = $5,
+			watched = $6,
+			watched_seconds = $7,
+			last_heartbeat_at = NOW(),
+			updated_at = NOW()
+		WHERE user_id = $1 AND module_id = $2 AND video = $3
+		RETURNING
+			video, position_seconds, duration_seconds, watched_seconds,
+			ROUND(LEAST(watched_seconds * 100.0 / duration_seconds, 100), 2) AS watched_percent, updated_at
 	`
-	_, err = r.db.ExecContext(ctx, updateQuery, progress, req.UserId, req.ClassId)
+
+	res = &entity.VideoHeartbeatResponse{ModuleId: module.Id, Progressed: current.FirstToday}
+	err = tx.GetContext(ctx, &res.VideoProgress, updateQuery, req.UserId, module.Id, req.Video, watch.Position, watch.Duration, []byte(watch.Watched), watch.Seconds())
 	if err != nil {
-		log.Error().Err(err).Msg("repo::TrackModule - Failed to update user progress")
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to update video progress")
+		return nil, err
+	}
+
+	doneQuery := `
+		SELECT NOT EXISTS (
+			SELECT 1
+			FROM unnest($3::TEXT[]) v(video)
+			LEFT JOIN module_video_progress vp ON vp.user_id = $1 AND vp.module_id = $2 AND vp.video = v.video
+			WHERE vp.video IS NULL OR vp.watched_seconds * 100 < vp.duration_seconds * $4
+		)
+	`
+
+	var done bool
+	if err = tx.GetContext(ctx, &done, doneQuery, req.UserId, module.Id, module.Videos, module.WatchPercent); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to check module videos")
+		return nil, err
+	}
+
+	res.StatusProgress = "on_progress"
+	if done {
+		res.StatusProgress = "done"
+	}
+
+	// most heartbeats leave the module where it is, only a status that moves forward is written
+	statusQuery := `
+		SELECT status::TEXT
+		FROM users_progress
+		WHERE user_id = $1 AND module_id = $2 AND status >= $3::progress_status
+	`
+
+	err = tx.GetContext(ctx, &res.StatusProgress, statusQuery, req.UserId, module.Id, res.StatusProgress)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		if _, err = tx.ExecContext(ctx, trackModuleQuery, req.UserId, req.ClassId, req.MaterialId, req.ModuleId, res.StatusProgress); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to track module")
+			return nil, err
+		}
+		res.Progressed = true
+
+		if _, err = tx.ExecContext(ctx, `SELECT refresh_class_progress($1::INT, $2::UUID)`, req.ClassId, req.UserId); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to refresh class progress")
+			return nil, err
+		}
+	default:
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to get module progress")
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::VideoHeartbeat - Failed to commit transaction")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetProgress returns the cached class progress of the user, computed from the completion rules when there is no
+// cache yet.
+func (r *classRepository) GetProgress(ctx context.Context, req *entity.GetProgressRequest) (*entity.ClassProgress, error) {
+	var res entity.ClassProgress
+
+	query := `
+		SELECT completed_items, total_items, progress
+		FROM class_progress
+		WHERE user_id = $1 AND class_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &res, query, req.UserId, req.ClassId)
+	if err == nil {
+		return &res, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetProgress - Failed to get class progress")
+		return nil, err
+	}
+
+	query = `
+		SELECT
+			(COUNT(*) FILTER (WHERE completed))::INT AS completed_items,
+			COUNT(*)::INT AS total_items,
+			CASE WHEN COUNT(*) > 0 THEN ROUND((COUNT(*) FILTER (WHERE completed)) * 100.0 / COUNT(*), 2) ELSE 0 END AS progress
+		FROM class_completion_items($2::INT, $1::UUID)
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, req.UserId, req.ClassId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetProgress - Failed to compute class progress")
 		return nil, err
 	}
 
-	res.Progress = progress
 	return &res, nil
 }
 
-func (r *classRepository) GetProgress(ctx context.Context, req *entity.GetProgressRequest) (*float64, error) {
+// UpdateCompletionRules replaces the completion rules of a class of the user and recomputes the progress of every
+// user in the class in the same transaction.
+func (r *classRepository) UpdateCompletionRules(ctx context.Context, req *entity.UpdateCompletionRulesRequest) (res *entity.CompletionRules, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateCompletionRules - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdateCompletionRules - Failed to rollback transaction")
+			}
+		}
+	}()
+
 	query := `
-		SELECT 
-			COUNT(DISTINCT m.id) AS total_modules,
-			COUNT(DISTINCT up.module_id) FILTER (WHERE up.status = 'done') AS completed_modules
-		FROM 
-			class c
-		JOIN 
-			materials mat ON c.id = mat.class_id
-		JOIN 
-			modules m ON mat.id = m.materials_id
-		LEFT JOIN 
-			users_progress up ON m.id = up.module_id AND up.user_id = $1
-		WHERE 
-			c.id = $2;
+		INSERT INTO class_completion_rules (
+			class_id, count_modules, count_quizzes, count_assignments, min_quiz_score, require_graded_assignments, video_watch_percent, updated_by
+		)
+		SELECT c.id, $3, $4, $5, $6, $7, $8, $2
+		FROM class c
+		WHERE c.id = $1 AND c.creator_class_id = $2
+		ON CONFLICT (class_id) DO UPDATE
+		SET
+			count_modules = EXCLUDED.count_modules,
+			count_quizzes = EXCLUDED.count_quizzes,
+			count_assignments = EXCLUDED.count_assignments,
+			min_quiz_score = EXCLUDED.min_quiz_score,
+			require_graded_assignments = EXCLUDED.require_graded_assignments,
+			video_watch_percent = EXCLUDED.video_watch_percent,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING class_id, count_modules, count_quizzes, count_assignments, min_quiz_score, require_graded_assignments, video_watch_percent, updated_at
 	`
 
-	var totalModules, completedModules int
-	err := r.db.QueryRowContext(ctx, query, req.UserId, req.ClassId).Scan(&totalModules, &completedModules)
+	res = new(entity.CompletionRules)
+	err = tx.GetContext(ctx, res, query, req.ClassId, req.UserId, req.CountModules, req.CountQuizzes, req.CountAssignments, req.MinQuizScore, req.RequireGradedAssignments, req.VideoWatchPercent)
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetProgress - Failed to calculate progress")
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateCompletionRules - Class not found or not created by the user")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found or unauthorized access"))
+			return nil, err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateCompletionRules - Failed to update completion rules")
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `SELECT refresh_class_progress($1::INT, NULL)`, req.ClassId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateCompletionRules - Failed to refresh class progress")
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateCompletionRules - Failed to commit transaction")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FindClassMember checks that the class exists and the user created it or is enrolled in it.
+func (r *classRepository) FindClassMember(ctx context.Context, classId int, userId string) error {
+	query := `
+		SELECT c.creator_class_id = $2 OR EXISTS (
+			SELECT 1 FROM users_classes uc WHERE uc.class_id = c.id AND uc.user_id = $2
+		)
+		FROM class c
+		WHERE c.id = $1
+	`
+
+	var member bool
+	if err := r.db.GetContext(ctx, &member, query, classId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Msg("repo::FindClassMember - Class not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Str("user_id", userId).Msg("repo::FindClassMember - Failed to query class")
+		return err
+	}
+
+	if !member {
+		log.Warn().Int("class_id", classId).Str("user_id", userId).Msg("repo::FindClassMember - User not enrolled in class")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("You are not enrolled in this class"))
+	}
+
+	return nil
+}
+
+// GetCompletionRules returns the rules of the class, the defaults when the creator never set any.
+func (r *classRepository) GetCompletionRules(ctx context.Context, classId int) (*entity.CompletionRules, error) {
+	var res entity.CompletionRules
+
+	query := `
+		SELECT
+			c.id AS class_id,
+			COALESCE(r.count_modules, TRUE) AS count_modules,
+			COALESCE(r.count_quizzes, FALSE) AS count_quizzes,
+			COALESCE(r.count_assignments, FALSE) AS count_assignments,
+			COALESCE(r.min_quiz_score, 0) AS min_quiz_score,
+			COALESCE(r.require_graded_assignments, FALSE) AS require_graded_assignments,
+			COALESCE(r.video_watch_percent, 90) AS video_watch_percent,
+			r.updated_at
+		FROM class c
+		LEFT JOIN class_completion_rules r ON r.class_id = c.id
+		WHERE c.id = $1
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, classId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Msg("repo::GetCompletionRules - Class not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetCompletionRules - Failed to get completion rules")
 		return nil, err
 	}
 
-	progress := 0.0
-	if totalModules > 0 {
-		progress = (float64(completedModules) / float64(totalModules)) * 100
+	return &res, nil
+}
+
+// GetCompletionItems lists every item that counts towards the class and whether the user completed it.
+func (r *classRepository) GetCompletionItems(ctx context.Context, req *entity.GetCompletionRequest) ([]entity.CompletionItem, error) {
+	var res = make([]entity.CompletionItem, 0)
+
+	query := `
+		SELECT item_type, item_id, title, completed, completed_at, score, max_score
+		FROM class_completion_items($1::INT, $2::UUID)
+		ORDER BY array_position(ARRAY['module', 'quiz', 'assignment'], item_type), item_id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, req.ClassId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetCompletionItems - Failed to get completion items")
+		return nil, err
 	}
 
-	return &progress, nil
+	return res, nil
 }
 
 func (r *classRepository) GetAllClassAdmin(ctx context.Context, req *entity.GetAllClassAdminRequest) (*[]entity.GetAllClassAdminResponse, error) {
@@ -773,3 +1157,37 @@ func (r *classRepository) GetAllClassAdmin(ctx context.Context, req *entity.GetA
 
 	return &classes, nil
 }
+
+// UpdateClassCover sets the new cover and returns the variants it replaced so they can be removed from storage.
+func (r *classRepository) UpdateClassCover(ctx context.Context, req *entity.UpdateClassCoverRequest) (types.ImageVariants, error) {
+	var old types.ImageVariants
+
+	query := `
+		UPDATE class c
+		SET
+			image = ?,
+			image_variants = ?,
+			updated_at = NOW()
+		FROM (SELECT id, image_variants FROM class WHERE id = ? AND creator_class_id = ? FOR UPDATE) prev
+		WHERE c.id = prev.id
+		RETURNING prev.image_variants
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.Image, req.ImageVariants, req.Id, req.UserId).Scan(&old)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Any("payload", req).Msg("repo::UpdateClassCover - Class not found or not owned by user")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateClassCover - Failed to update class cover")
+		return nil, err
+	}
+
+	return old, nil
+}
+
+// GetStorageUsage is the quota check of the storage module.
+func (r *classRepository) GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error) {
+	return storageRepo.GetStorageUsage(ctx, r.db, userId)
+}
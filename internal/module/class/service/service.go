@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/module/class/entity"
 	"hacko-app/internal/module/class/ports"
+	"hacko-app/pkg"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/eventbus"
+	"math"
+	"strconv"
 
 	// "hacko-app/pkg/response"
 
@@ -14,15 +20,24 @@ import (
 var _ ports.ClassService = &classService{}
 
 type classService struct {
-	repo ports.ClassRepository
+	repo    ports.ClassRepository
+	storage integStorage.Storage
 }
 
-func NewClassService(repo ports.ClassRepository) *classService {
+func NewClassService(repo ports.ClassRepository, storage integStorage.Storage) *classService {
 	return &classService{
-		repo: repo,
+		repo:    repo,
+		storage: storage,
 	}
 }
 
+// coverVariants are the 16:9 sizes generated for every class cover, "large" doubles as image.
+var coverVariants = []pkg.ImageVariantSpec{
+	{Name: "thumbnail", Width: 320, Height: 180},
+	{Name: "medium", Width: 640, Height: 360},
+	{Name: "large", Width: 1280, Height: 720},
+}
+
 func (s *classService) CreateClass(ctx context.Context, req *entity.CreateClassRequest) (*entity.CreateClassResponse, error) {
 
 	result, err := s.repo.CreateClass(ctx, req)
@@ -54,12 +69,18 @@ func (s *classService) GetOverviewClassById(ctx context.Context, req *entity.Get
 		return nil, err
 	}
 
-	syllabus, err := s.repo.GetAllSyllabus(ctx, req.Id)
+	syllabus, err := s.repo.GetAllSyllabus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := s.repo.GetProgress(ctx, &entity.GetProgressRequest{UserId: req.UserId, ClassId: req.Id})
 	if err != nil {
 		return nil, err
 	}
 
 	class.Syllabus = syllabus
+	class.Progress = progress
 
 	return class, nil
 }
@@ -149,11 +170,89 @@ func (s *classService) AddUserToClass(ctx context.Context, req *entity.AddUsersT
 }
 
 func (s *classService) TrackModule(ctx context.Context, req *entity.TrackModuleRequest) (*entity.TrackModuleResponse, error) {
+	if req.StatusProgress == "" {
+		req.StatusProgress = "done"
+	}
+
 	res, err := s.repo.TrackModule(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	eventbus.Publish(eventbus.ModuleProgressedEvent{UserId: req.UserId, ModuleId: res.ModuleId, Status: res.StatusProgress})
+
+	return res, nil
+}
+
+func (s *classService) VideoHeartbeat(ctx context.Context, req *entity.VideoHeartbeatRequest) (*entity.VideoHeartbeatResponse, error) {
+	res, err := s.repo.VideoHeartbeat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Progressed {
+		eventbus.Publish(eventbus.ModuleProgressedEvent{UserId: req.UserId, ModuleId: res.ModuleId, Status: res.StatusProgress})
+	}
+
+	return res, nil
+}
+
+func (s *classService) UpdateCompletionRules(ctx context.Context, req *entity.UpdateCompletionRulesRequest) (*entity.CompletionRules, error) {
+	if !req.CountModules && !req.CountQuizzes && !req.CountAssignments {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("At least one of modules, quizzes or assignments must count towards completion"))
+	}
+
+	res, err := s.repo.UpdateCompletionRules(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *classService) GetCompletionRules(ctx context.Context, req *entity.GetCompletionRulesRequest) (*entity.CompletionRules, error) {
+	if err := s.repo.FindClassMember(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repo.GetCompletionRules(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetCompletion reports every counted item of the class for the user, the progress is derived from the same items
+// so it never disagrees with them.
+func (s *classService) GetCompletion(ctx context.Context, req *entity.GetCompletionRequest) (*entity.GetCompletionResponse, error) {
+	if err := s.repo.FindClassMember(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repo.GetCompletionRules(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetCompletionItems(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entity.GetCompletionResponse{Rules: rules, Items: items}
+	for _, item := range items {
+		if item.Completed {
+			res.Progress.CompletedItems++
+		}
+	}
+	res.Progress.TotalItems = len(items)
+
+	if res.Progress.TotalItems > 0 {
+		res.Progress.Progress = math.Round(float64(res.Progress.CompletedItems)*10000/float64(res.Progress.TotalItems)) / 100
+		res.Completed = res.Progress.CompletedItems == res.Progress.TotalItems
+	}
+
 	return res, nil
 }
 
@@ -165,3 +264,51 @@ func (s *classService) GetAllClassAdmin(ctx context.Context, req *entity.GetAllC
 
 	return res, nil
 }
+
+func (s *classService) UploadClassCover(ctx context.Context, req *entity.UploadClassCoverRequest) (*entity.UploadClassCoverResponse, error) {
+	data, _, err := integStorage.ReadUpload(integStorage.CategoryCover, req.File.FileName(), req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetStorageUsage(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !usage.Allows(int64(len(data))) {
+		log.Warn().Str("userId", req.UserId).Int64("used", usage.UsedBytes).Int64("quota", usage.QuotaBytes).Msg("service::UploadClassCover - Storage quota exceeded")
+		return nil, integStorage.ErrQuotaExceeded
+	}
+
+	images, err := pkg.ProcessImage(data, coverVariants)
+	if err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("service::UploadClassCover - Failed to process image")
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "image could not be processed."))
+	}
+
+	variants, err := integStorage.PutImageVariants(ctx, s.storage, integStorage.Key(integStorage.VisibilityPublic, "classes", strconv.Itoa(req.Id)), images)
+	if err != nil {
+		return nil, err
+	}
+
+	image := variants["large"].Url
+	old, err := s.repo.UpdateClassCover(ctx, &entity.UpdateClassCoverRequest{
+		Id:            req.Id,
+		UserId:        req.UserId,
+		Image:         image,
+		ImageVariants: variants,
+	})
+	if err != nil {
+		integStorage.DeleteImageVariants(ctx, s.storage, variants)
+		return nil, err
+	}
+
+	integStorage.DeleteImageVariants(ctx, s.storage, old)
+
+	return &entity.UploadClassCoverResponse{
+		Id:            req.Id,
+		Image:         image,
+		ImageVariants: variants,
+	}, nil
+}
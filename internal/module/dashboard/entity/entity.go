@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"hacko-app/pkg/types"
+	"time"
+)
+
+type GetDashboardRequest struct {
+	UserId string `validate:"required"`
+}
+
+// DashboardClass is a class the student is enrolled in with their progress under the completion rules of the class.
+type DashboardClass struct {
+	Id               int                 `json:"id" db:"id"`
+	Title            string              `json:"title" db:"title"`
+	Image            string              `json:"image" db:"image"`
+	ImageVariants    types.ImageVariants `json:"image_variants" db:"image_variants"`
+	EnrollmentStatus string              `json:"enrollment_status" db:"enrollment_status"`
+	CompletedItems   int                 `json:"completed_items" db:"completed_items"`
+	TotalItems       int                 `json:"total_items" db:"total_items"`
+	Progress         float64             `json:"progress" db:"progress"`
+}
+
+// DashboardAssignment is an assignment without an active submission of the student or their team, the deadline
+// includes their extension.
+type DashboardAssignment struct {
+	Id            int        `json:"id" db:"id"`
+	ClassId       int        `json:"class_id" db:"class_id"`
+	ClassTitle    string     `json:"class_title" db:"class_title"`
+	Title         string     `json:"title" db:"title"`
+	DueDate       time.Time  `json:"due_date" db:"due_date"`
+	LateCutoff    *time.Time `json:"late_cutoff" db:"late_cutoff"`
+	IsExtended    bool       `json:"is_extended" db:"is_extended"`
+	CanSubmitLate bool       `json:"can_submit_late" db:"can_submit_late"`
+	MaxScore      float64    `json:"max_score" db:"max_score"`
+}
+
+type DashboardQuiz struct {
+	Id         int       `json:"id" db:"id"`
+	ClassId    int       `json:"class_id" db:"class_id"`
+	ClassTitle string    `json:"class_title" db:"class_title"`
+	Title      string    `json:"title" db:"title"`
+	Questions  int       `json:"questions" db:"questions"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// DashboardGrade is a graded assignment submission or a taken quiz.
+type DashboardGrade struct {
+	Type       string    `json:"type" db:"type"`
+	Id         int       `json:"id" db:"id"`
+	ClassId    int       `json:"class_id" db:"class_id"`
+	ClassTitle string    `json:"class_title" db:"class_title"`
+	Title      string    `json:"title" db:"title"`
+	Score      *float64  `json:"score" db:"score"`
+	MaxScore   *float64  `json:"max_score" db:"max_score"`
+	GradedAt   time.Time `json:"graded_at" db:"graded_at"`
+}
+
+// DashboardContinue is the module the student worked on last, or the next one of the class they were active in last.
+type DashboardContinue struct {
+	ClassId        int      `json:"class_id" db:"class_id"`
+	ClassTitle     string   `json:"class_title" db:"class_title"`
+	MaterialId     int      `json:"material_id" db:"material_id"`
+	ModuleId       int      `json:"module_id" db:"module_id"`
+	ModuleTitle    string   `json:"module_title" db:"module_title"`
+	StatusProgress string   `json:"status_progress" db:"status_progress"`
+	Video          *string  `json:"video" db:"video"`
+	Position       *float64 `json:"position" db:"position_seconds"`
+	Link           string   `json:"link" db:"-"`
+}
+
+type GetDashboardResponse struct {
+	Classes        []DashboardClass      `json:"classes"`
+	Upcoming       []DashboardAssignment `json:"upcoming_assignments"`
+	Overdue        []DashboardAssignment `json:"overdue_assignments"`
+	Quizzes        []DashboardQuiz       `json:"unfinished_quizzes"`
+	RecentlyGraded []DashboardGrade      `json:"recently_graded"`
+	Continue       *DashboardContinue    `json:"continue"`
+}
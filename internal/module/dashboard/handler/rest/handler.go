@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/dashboard/entity"
+	"hacko-app/internal/module/dashboard/ports"
+	"hacko-app/internal/module/dashboard/repository"
+	"hacko-app/internal/module/dashboard/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type dashboardHandler struct {
+	service ports.DashboardService
+}
+
+func NewDashboardHandler() *dashboardHandler {
+	var handler = new(dashboardHandler)
+
+	repo := repository.NewDashboardRepository(adapter.Adapters.HackoPostgres)
+	dashboardService := service.NewDashboardService(repo)
+
+	handler.service = dashboardService
+	return handler
+}
+
+func (h *dashboardHandler) Register(router fiber.Router) {
+	// user routes
+	router.Get("/dashboard", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetDashboard)
+}
+
+func (h *dashboardHandler) GetDashboard(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetDashboardRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetDashboard - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetDashboard(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
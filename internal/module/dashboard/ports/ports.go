@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/dashboard/entity"
+)
+
+type DashboardRepository interface {
+	// users contract
+	GetClasses(ctx context.Context, userId string) ([]entity.DashboardClass, error)
+	GetPendingAssignments(ctx context.Context, userId string, overdue bool, limit int) ([]entity.DashboardAssignment, error)
+	GetUnfinishedQuizzes(ctx context.Context, userId string, limit int) ([]entity.DashboardQuiz, error)
+	GetRecentlyGraded(ctx context.Context, userId string, limit int) ([]entity.DashboardGrade, error)
+	GetContinueModule(ctx context.Context, userId string) (*entity.DashboardContinue, error)
+}
+
+type DashboardService interface {
+	// users contract
+	GetDashboard(ctx context.Context, req *entity.GetDashboardRequest) (*entity.GetDashboardResponse, error)
+}
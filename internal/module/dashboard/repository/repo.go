@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hacko-app/internal/module/dashboard/entity"
+	"hacko-app/internal/module/dashboard/ports"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.DashboardRepository = &dashboardRepository{}
+
+type dashboardRepository struct {
+	db *sqlx.DB
+}
+
+func NewDashboardRepository(db *sqlx.DB) *dashboardRepository {
+	return &dashboardRepository{
+		db: db,
+	}
+}
+
+// GetClasses returns the classes the user is enrolled in, the progress is computed from the completion rules when
+// it was never cached.
+func (r *dashboardRepository) GetClasses(ctx context.Context, userId string) ([]entity.DashboardClass, error) {
+	var res = make([]entity.DashboardClass, 0)
+
+	query := `
+		SELECT
+			c.id, c.title, COALESCE(c.image, '') AS image, c.image_variants, uc.enrollment_status,
+			COALESCE(cp.completed_items, live.completed_items) AS completed_items,
+			COALESCE(cp.total_items, live.total_items) AS total_items,
+			COALESCE(cp.progress, CASE WHEN live.total_items > 0 THEN ROUND(live.completed_items * 100.0 / live.total_items, 2) ELSE 0 END) AS progress
+		FROM users_classes uc
+		JOIN class c ON c.id = uc.class_id
+		LEFT JOIN class_progress cp ON cp.class_id = c.id AND cp.user_id = uc.user_id
+		LEFT JOIN LATERAL (
+			SELECT (COUNT(*) FILTER (WHERE i.completed))::INT AS completed_items, COUNT(*)::INT AS total_items
+			FROM class_completion_items(c.id, uc.user_id) i
+			WHERE cp.user_id IS NULL
+		) live ON TRUE
+		WHERE uc.user_id = $1 AND uc.enrollment_status IN ('active', 'completed')
+		ORDER BY cp.updated_at DESC NULLS LAST, uc.created_at DESC
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetClasses - Failed to get enrolled classes")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPendingAssignments returns the assignments of the active classes of the user that neither they nor their team
+// submitted, the upcoming ones by the nearest deadline or the overdue ones by the latest.
+func (r *dashboardRepository) GetPendingAssignments(ctx context.Context, userId string, overdue bool, limit int) ([]entity.DashboardAssignment, error) {
+	var res = make([]entity.DashboardAssignment, 0)
+
+	query := `
+		SELECT
+			a.id, a.class_id, c.title AS class_title, a.title, d.due_date, d.late_cutoff, d.extended AS is_extended,
+			a.allow_late AND (d.late_cutoff IS NULL OR d.late_cutoff > NOW()) AS can_submit_late, a.max_score
+		FROM users_classes uc
+		JOIN class c ON c.id = uc.class_id
+		JOIN assignments a ON a.class_id = c.id
+		CROSS JOIN LATERAL assignment_deadline(a.id, uc.user_id) d
+		WHERE uc.user_id = $1 AND uc.enrollment_status = 'active'
+			AND (d.due_date < NOW()) = $2
+			AND NOT EXISTS (
+				SELECT 1
+				FROM submissions s
+				LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = $1
+				WHERE s.assignment_id = a.id AND s.is_active AND (s.student_id = $1 OR sm.student_id IS NOT NULL)
+			)
+		ORDER BY
+			CASE WHEN $2 THEN NULL ELSE d.due_date END,
+			d.due_date DESC,
+			a.id
+		LIMIT $3
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId, overdue, limit); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Bool("overdue", overdue).Msg("repo::GetPendingAssignments - Failed to get pending assignments")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetUnfinishedQuizzes returns the public quizzes of the active classes of the user they never took, newest first.
+func (r *dashboardRepository) GetUnfinishedQuizzes(ctx context.Context, userId string, limit int) ([]entity.DashboardQuiz, error) {
+	var res = make([]entity.DashboardQuiz, 0)
+
+	query := `
+		SELECT
+			q.id, q.class_id, c.title AS class_title, q.title, q.created_at,
+			(SELECT COUNT(*) FROM questions_quiz qq WHERE qq.quiz_id = q.id) AS questions
+		FROM users_classes uc
+		JOIN class c ON c.id = uc.class_id
+		JOIN quiz q ON q.class_id = c.id AND q.status = 'public'
+		WHERE uc.user_id = $1 AND uc.enrollment_status = 'active'
+			AND NOT EXISTS (SELECT 1 FROM users_completed_quiz ucq WHERE ucq.quiz_id = q.id AND ucq.user_id = $1)
+		ORDER BY q.created_at DESC, q.id
+		LIMIT $2
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId, limit); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetUnfinishedQuizzes - Failed to get unfinished quizzes")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetRecentlyGraded returns the latest graded submissions of the user or their team and the quizzes they took.
+// A member grade overrides the grade of the team.
+func (r *dashboardRepository) GetRecentlyGraded(ctx context.Context, userId string, limit int) ([]entity.DashboardGrade, error) {
+	var res = make([]entity.DashboardGrade, 0)
+
+	query := `
+		SELECT type, id, class_id, class_title, title, score, max_score, graded_at
+		FROM (
+			SELECT
+				'assignment' AS type, a.id, a.class_id, c.title AS class_title, a.title,
+				COALESCE(sm.grade, s.grade) AS score, a.max_score, s.graded_at
+			FROM submissions s
+			JOIN assignments a ON a.id = s.assignment_id
+			JOIN class c ON c.id = a.class_id
+			LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = $1
+			WHERE s.is_active AND s.status = 'rated' AND s.graded_at IS NOT NULL
+				AND (s.student_id = $1 OR sm.student_id IS NOT NULL)
+
+			UNION ALL
+
+			SELECT
+				'quiz', q.id, q.class_id, c.title, q.title,
+				ucq.score, ucq.max_score, ucq.created_at
+			FROM users_completed_quiz ucq
+			JOIN quiz q ON q.id = ucq.quiz_id
+			JOIN class c ON c.id = q.class_id
+			WHERE ucq.user_id = $1 AND ucq.score IS NOT NULL
+		) g
+		ORDER BY graded_at DESC
+		LIMIT $2
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, userId, limit); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetRecentlyGraded - Failed to get recently graded work")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetContinueModule returns the unfinished module of an active class the user touched last, by tracking or watching
+// one of its videos. Without any, it is the first unfinished module of the class they were active in last. Nothing
+// is returned when every module is done.
+func (r *dashboardRepository) GetContinueModule(ctx context.Context, userId string) (*entity.DashboardContinue, error) {
+	var res entity.DashboardContinue
+
+	query := `
+		SELECT
+			c.id AS class_id, c.title AS class_title, mat.id AS material_id, m.id AS module_id, m.title AS module_title,
+			COALESCE(up.status::TEXT, 'not_started') AS status_progress, vp.video, vp.position_seconds
+		FROM users_classes uc
+		JOIN class c ON c.id = uc.class_id
+		JOIN materials mat ON mat.class_id = c.id
+		JOIN modules m ON m.materials_id = mat.id AND m.status = 'published'
+		LEFT JOIN users_progress up ON up.module_id = m.id AND up.user_id = uc.user_id
+		LEFT JOIN LATERAL (
+			SELECT x.video, x.position_seconds, x.updated_at
+			FROM module_video_progress x
+			WHERE x.user_id = uc.user_id AND x.module_id = m.id AND x.video = ANY(m.videos)
+			ORDER BY x.updated_at DESC
+			LIMIT 1
+		) vp ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT GREATEST(
+				(SELECT MAX(x.updated_at) FROM users_progress x WHERE x.user_id = uc.user_id AND x.class_id = c.id),
+				(SELECT MAX(x.updated_at) FROM module_video_progress x JOIN modules xm ON xm.id = x.module_id JOIN materials xmat ON xmat.id = xm.materials_id
+					WHERE x.user_id = uc.user_id AND xmat.class_id = c.id)
+			) AS active_at
+		) ca ON TRUE
+		WHERE uc.user_id = $1 AND uc.enrollment_status = 'active' AND up.status IS DISTINCT FROM 'done'
+		ORDER BY
+			GREATEST(up.updated_at, vp.updated_at) DESC NULLS LAST,
+			ca.active_at DESC NULLS LAST,
+			uc.created_at DESC,
+			mat.id,
+			m.id
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetContinueModule - Failed to get module to continue")
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hacko-app/internal/module/dashboard/entity"
+	"hacko-app/internal/module/dashboard/ports"
+)
+
+var _ ports.DashboardService = &dashboardService{}
+
+type dashboardService struct {
+	repo ports.DashboardRepository
+}
+
+func NewDashboardService(repo ports.DashboardRepository) *dashboardService {
+	return &dashboardService{
+		repo: repo,
+	}
+}
+
+// dashboardLimit caps every list of the dashboard, the full lists live in their classes.
+const dashboardLimit = 10
+
+func (s *dashboardService) GetDashboard(ctx context.Context, req *entity.GetDashboardRequest) (*entity.GetDashboardResponse, error) {
+	classes, err := s.repo.GetClasses(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming, err := s.repo.GetPendingAssignments(ctx, req.UserId, false, dashboardLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	overdue, err := s.repo.GetPendingAssignments(ctx, req.UserId, true, dashboardLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	quizzes, err := s.repo.GetUnfinishedQuizzes(ctx, req.UserId, dashboardLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	graded, err := s.repo.GetRecentlyGraded(ctx, req.UserId, dashboardLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := s.repo.GetContinueModule(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if next != nil {
+		// the class overview lists every module, the anchor scrolls to the one to continue
+		next.Link = fmt.Sprintf("/users/class/%d#module-%d", next.ClassId, next.ModuleId)
+	}
+
+	return &entity.GetDashboardResponse{
+		Classes:        classes,
+		Upcoming:       upcoming,
+		Overdue:        overdue,
+		Quizzes:        quizzes,
+		RecentlyGraded: graded,
+		Continue:       next,
+	}, nil
+}
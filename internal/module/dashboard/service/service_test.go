@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hacko-app/internal/module/dashboard/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDashboardRepository struct {
+	classes  []entity.DashboardClass
+	upcoming []entity.DashboardAssignment
+	overdue  []entity.DashboardAssignment
+	quizzes  []entity.DashboardQuiz
+	graded   []entity.DashboardGrade
+	next     *entity.DashboardContinue
+	err      error
+
+	limits []int
+}
+
+func (r *fakeDashboardRepository) GetClasses(ctx context.Context, userId string) ([]entity.DashboardClass, error) {
+	return r.classes, nil
+}
+
+func (r *fakeDashboardRepository) GetPendingAssignments(ctx context.Context, userId string, overdue bool, limit int) ([]entity.DashboardAssignment, error) {
+	r.limits = append(r.limits, limit)
+	if overdue {
+		return r.overdue, nil
+	}
+	return r.upcoming, nil
+}
+
+func (r *fakeDashboardRepository) GetUnfinishedQuizzes(ctx context.Context, userId string, limit int) ([]entity.DashboardQuiz, error) {
+	r.limits = append(r.limits, limit)
+	return r.quizzes, r.err
+}
+
+func (r *fakeDashboardRepository) GetRecentlyGraded(ctx context.Context, userId string, limit int) ([]entity.DashboardGrade, error) {
+	r.limits = append(r.limits, limit)
+	return r.graded, nil
+}
+
+func (r *fakeDashboardRepository) GetContinueModule(ctx context.Context, userId string) (*entity.DashboardContinue, error) {
+	return r.next, nil
+}
+
+func TestGetDashboard(t *testing.T) {
+	repo := &fakeDashboardRepository{
+		classes:  []entity.DashboardClass{{Id: 1, Title: "Go"}},
+		upcoming: []entity.DashboardAssignment{{Id: 2, Title: "Upcoming"}},
+		overdue:  []entity.DashboardAssignment{{Id: 3, Title: "Overdue"}},
+		quizzes:  []entity.DashboardQuiz{{Id: 4}},
+		graded:   []entity.DashboardGrade{{Type: "quiz", Id: 5}},
+		next:     &entity.DashboardContinue{ClassId: 1, MaterialId: 6, ModuleId: 7},
+	}
+
+	res, err := NewDashboardService(repo).GetDashboard(context.Background(), &entity.GetDashboardRequest{UserId: "user"})
+	require.NoError(t, err)
+
+	assert.Equal(t, repo.classes, res.Classes)
+	assert.Equal(t, repo.upcoming, res.Upcoming)
+	assert.Equal(t, repo.overdue, res.Overdue)
+	assert.Equal(t, repo.quizzes, res.Quizzes)
+	assert.Equal(t, repo.graded, res.RecentlyGraded)
+	assert.Equal(t, []int{dashboardLimit, dashboardLimit, dashboardLimit, dashboardLimit}, repo.limits)
+
+	require.NotNil(t, res.Continue)
+	assert.Equal(t, "/users/class/1#module-7", res.Continue.Link)
+}
+
+func TestGetDashboardNothingToContinue(t *testing.T) {
+	res, err := NewDashboardService(&fakeDashboardRepository{}).GetDashboard(context.Background(), &entity.GetDashboardRequest{UserId: "user"})
+	require.NoError(t, err)
+
+	assert.Nil(t, res.Continue)
+}
+
+func TestGetDashboardError(t *testing.T) {
+	repo := &fakeDashboardRepository{err: errors.New("query failed")}
+
+	res, err := NewDashboardService(repo).GetDashboard(context.Background(), &entity.GetDashboardRequest{UserId: "user"})
+	assert.ErrorIs(t, err, repo.err)
+	assert.Nil(t, res)
+}
@@ -0,0 +1,108 @@
+package entity
+
+import "time"
+
+const (
+	ItemAssignment = "assignment"
+	ItemQuiz       = "quiz"
+)
+
+type GetGradebookRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+type ExportGradebookRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+	Format  string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+}
+
+type ExportGradebookResponse struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type GradeCategory struct {
+	Id       int     `json:"id" db:"id"`
+	Name     string  `json:"name" db:"name"`
+	Weight   float64 `json:"weight" db:"weight"`
+	Position int     `json:"position" db:"position"`
+}
+
+type GradeLetter struct {
+	Letter     string  `json:"letter" db:"letter" validate:"required,max=5"`
+	MinPercent float64 `json:"min_percent" db:"min_percent" validate:"min=0,max=100"`
+}
+
+// GradebookItem is a graded column of the gradebook, either an assignment or a quiz.
+type GradebookItem struct {
+	Type       string    `json:"type" db:"type"`
+	Id         int       `json:"id" db:"id"`
+	Title      string    `json:"title" db:"title"`
+	CategoryId *int      `json:"category_id" db:"category_id"`
+	MaxScore   float64   `json:"max_score" db:"max_score"`
+	SortAt     time.Time `json:"-" db:"sort_at"`
+}
+
+type GradebookStudent struct {
+	Id    string `db:"id"`
+	Name  string `db:"name"`
+	Email string `db:"email"`
+}
+
+// GradebookScore is a graded result, MaxScore is the one it was graded against.
+type GradebookScore struct {
+	ItemType  string  `db:"item_type"`
+	ItemId    int     `db:"item_id"`
+	StudentId string  `db:"student_id"`
+	Score     float64 `db:"score"`
+	MaxScore  float64 `db:"max_score"`
+}
+
+type CategoryGrade struct {
+	CategoryId int      `json:"category_id"`
+	Percent    *float64 `json:"percent"`
+}
+
+type GradebookRow struct {
+	StudentId    string          `json:"student_id"`
+	Name         string          `json:"name"`
+	Email        string          `json:"email"`
+	Scores       []*float64      `json:"scores"`
+	Categories   []CategoryGrade `json:"categories"`
+	FinalPercent *float64        `json:"final_percent"`
+	Letter       string          `json:"letter"`
+}
+
+// GetGradebookResponse is a students x items matrix, the scores of every row follow the order of Items.
+type GetGradebookResponse struct {
+	ClassId    int             `json:"class_id"`
+	Categories []GradeCategory `json:"categories"`
+	Letters    []GradeLetter   `json:"letters"`
+	Items      []GradebookItem `json:"items"`
+	Students   []GradebookRow  `json:"students"`
+}
+
+type GradeCategoryRequest struct {
+	Name          string  `json:"name" validate:"required,max=255"`
+	Weight        float64 `json:"weight" validate:"min=0,max=100"`
+	AssignmentIds []int   `json:"assignment_ids"`
+	QuizIds       []int   `json:"quiz_ids"`
+}
+
+// UpdateGradebookSettingsRequest replaces the categories and the letter scale of a class.
+type UpdateGradebookSettingsRequest struct {
+	UserId     string                 `validate:"required"`
+	ClassId    int                    `json:"class_id" validate:"required"`
+	Categories []GradeCategoryRequest `json:"categories" validate:"dive"`
+	Letters    []GradeLetter          `json:"letters" validate:"dive"`
+}
+
+type GradebookSettingsResponse struct {
+	ClassId    int             `json:"class_id"`
+	Categories []GradeCategory `json:"categories"`
+	Letters    []GradeLetter   `json:"letters"`
+	Items      []GradebookItem `json:"items"`
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/gradebook/entity"
+	"hacko-app/internal/module/gradebook/ports"
+	"hacko-app/internal/module/gradebook/repository"
+	"hacko-app/internal/module/gradebook/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type gradebookHandler struct {
+	service ports.GradebookService
+}
+
+func NewGradebookHandler() *gradebookHandler {
+	var handler = new(gradebookHandler)
+
+	repo := repository.NewGradebookRepository(adapter.Adapters.HackoPostgres)
+	gradebookService := service.NewGradebookService(repo)
+
+	handler.service = gradebookService
+	return handler
+}
+
+func (h *gradebookHandler) Register(router fiber.Router) {
+	// admin routes
+	router.Get("/teacher/class/:classId/gradebook", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetGradebook)
+	router.Get("/teacher/class/:classId/gradebook/export", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.ExportGradebook)
+	router.Put("/teacher/class/:classId/gradebook/settings", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UpdateSettings)
+}
+
+func (h *gradebookHandler) GetGradebook(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetGradebookRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetGradebook - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetGradebook - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetGradebook(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *gradebookHandler) ExportGradebook(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportGradebookRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ExportGradebook - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid query"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::ExportGradebook - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ExportGradebook - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.ExportGradebook(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Set(fiber.HeaderContentType, res.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+res.Filename+`"`)
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	return c.Status(fiber.StatusOK).Send(res.Data)
+}
+
+func (h *gradebookHandler) UpdateSettings(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateGradebookSettingsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateSettings(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
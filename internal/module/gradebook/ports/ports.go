@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/gradebook/entity"
+)
+
+type GradebookRepository interface {
+	// utils contract
+	FindClassByCreator(ctx context.Context, classId int, userId string) error
+
+	// admin contract
+	GetCategories(ctx context.Context, classId int) ([]entity.GradeCategory, error)
+	GetLetters(ctx context.Context, classId int) ([]entity.GradeLetter, error)
+	GetItems(ctx context.Context, classId int) ([]entity.GradebookItem, error)
+	GetStudents(ctx context.Context, classId int) ([]entity.GradebookStudent, error)
+	GetScores(ctx context.Context, classId int) ([]entity.GradebookScore, error)
+	ReplaceSettings(ctx context.Context, req *entity.UpdateGradebookSettingsRequest) error
+}
+
+type GradebookService interface {
+	// admin contract
+	GetGradebook(ctx context.Context, req *entity.GetGradebookRequest) (*entity.GetGradebookResponse, error)
+	ExportGradebook(ctx context.Context, req *entity.ExportGradebookRequest) (*entity.ExportGradebookResponse, error)
+	UpdateSettings(ctx context.Context, req *entity.UpdateGradebookSettingsRequest) (*entity.GradebookSettingsResponse, error)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	classRepo "hacko-app/internal/module/class/repository"
+	"hacko-app/internal/module/gradebook/entity"
+	"hacko-app/internal/module/gradebook/ports"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.GradebookRepository = &gradebookRepository{}
+
+type gradebookRepository struct {
+	db *sqlx.DB
+}
+
+func NewGradebookRepository(db *sqlx.DB) *gradebookRepository {
+	return &gradebookRepository{
+		db: db,
+	}
+}
+
+// FindClassByCreator is the ownership check of the class module.
+func (r *gradebookRepository) FindClassByCreator(ctx context.Context, classId int, userId string) error {
+	return classRepo.FindClassByCreator(ctx, r.db, classId, userId)
+}
+
+func (r *gradebookRepository) GetCategories(ctx context.Context, classId int) ([]entity.GradeCategory, error) {
+	var res = make([]entity.GradeCategory, 0)
+
+	query := `
+		SELECT id, name, weight, position
+		FROM grade_categories
+		WHERE class_id = $1
+		ORDER BY position, id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetCategories - Failed to get grade categories")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *gradebookRepository) GetLetters(ctx context.Context, classId int) ([]entity.GradeLetter, error) {
+	var res = make([]entity.GradeLetter, 0)
+
+	query := `
+		SELECT letter, min_percent
+		FROM grade_letters
+		WHERE class_id = $1
+		ORDER BY min_percent DESC
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetLetters - Failed to get grade letters")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetItems returns the assignments and the public quizzes of a class, a quiz is worth the points of its graded questions.
+func (r *gradebookRepository) GetItems(ctx context.Context, classId int) ([]entity.GradebookItem, error) {
+	var res = make([]entity.GradebookItem, 0)
+
+	query := `
+		SELECT 'assignment' AS type, a.id, a.title, a.grade_category_id AS category_id, a.max_score, a.due_date AS sort_at
+		FROM assignments a
+		WHERE a.class_id = $1
+		UNION ALL
+		SELECT
+			'quiz' AS type, q.id, q.title, q.grade_category_id AS category_id,
+			COALESCE((SELECT SUM(qq.points) FROM questions_quiz qq WHERE qq.quiz_id = q.id AND qq.correct_answer IS NOT NULL), 0) AS max_score,
+			q.created_at AS sort_at
+		FROM quiz q
+		WHERE q.class_id = $1 AND q.status = 'public'
+		ORDER BY sort_at, type, id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetItems - Failed to get gradebook items")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *gradebookRepository) GetStudents(ctx context.Context, classId int) ([]entity.GradebookStudent, error) {
+	var res = make([]entity.GradebookStudent, 0)
+
+	query := `
+		SELECT u.id, u.name, u.email
+		FROM users_classes uc
+		JOIN users u ON u.id = uc.user_id
+		WHERE uc.class_id = $1 AND uc.enrollment_status IN ('active', 'completed')
+		ORDER BY u.name, u.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetStudents - Failed to get enrolled students")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetScores returns the graded active submissions and the scored quiz completions of a class.
+// A team submission counts for every member it was submitted for, with their override if any.
+func (r *gradebookRepository) GetScores(ctx context.Context, classId int) ([]entity.GradebookScore, error) {
+	var res = make([]entity.GradebookScore, 0)
+
+	query := `
+		SELECT 'assignment' AS item_type, s.assignment_id AS item_id, s.student_id, s.grade AS score, a.max_score
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		WHERE a.class_id = $1 AND s.is_active AND s.team_id IS NULL AND s.status = 'rated' AND s.grade IS NOT NULL
+		UNION ALL
+		SELECT 'assignment' AS item_type, s.assignment_id AS item_id, sm.student_id, COALESCE(sm.grade, s.grade) AS score, a.max_score
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		JOIN submission_members sm ON sm.submission_id = s.id
+		WHERE a.class_id = $1 AND s.is_active AND s.status = 'rated' AND COALESCE(sm.grade, s.grade) IS NOT NULL
+		UNION ALL
+		SELECT 'quiz' AS item_type, uc.quiz_id AS item_id, uc.user_id AS student_id, uc.score, uc.max_score
+		FROM users_completed_quiz uc
+		JOIN quiz q ON q.id = uc.quiz_id
+		WHERE q.class_id = $1 AND uc.score IS NOT NULL AND uc.max_score > 0
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetScores - Failed to get scores")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *gradebookRepository) ReplaceSettings(ctx context.Context, req *entity.UpdateGradebookSettingsRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::ReplaceSettings - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	// items of removed categories fall back to uncategorized through ON DELETE SET NULL
+	if _, err = tx.ExecContext(ctx, `DELETE FROM grade_categories WHERE class_id = $1`, req.ClassId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to delete grade categories")
+		return err
+	}
+
+	categoryQuery := `
+		INSERT INTO grade_categories (class_id, name, weight, position)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	for i, category := range req.Categories {
+		var categoryId int
+		if err = tx.QueryRowContext(ctx, categoryQuery, req.ClassId, category.Name, category.Weight, i).Scan(&categoryId); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to insert grade category")
+			return err
+		}
+
+		if len(category.AssignmentIds) > 0 {
+			_, err = tx.ExecContext(ctx, `UPDATE assignments SET grade_category_id = $1 WHERE class_id = $2 AND id = ANY($3)`, categoryId, req.ClassId, pq.Array(category.AssignmentIds))
+			if err != nil {
+				log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to categorize assignments")
+				return err
+			}
+		}
+
+		if len(category.QuizIds) > 0 {
+			_, err = tx.ExecContext(ctx, `UPDATE quiz SET grade_category_id = $1 WHERE class_id = $2 AND id = ANY($3)`, categoryId, req.ClassId, pq.Array(category.QuizIds))
+			if err != nil {
+				log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to categorize quizzes")
+				return err
+			}
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM grade_letters WHERE class_id = $1`, req.ClassId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to delete grade letters")
+		return err
+	}
+
+	for _, letter := range req.Letters {
+		_, err = tx.ExecContext(ctx, `INSERT INTO grade_letters (class_id, letter, min_percent) VALUES ($1, $2, $3)`, req.ClassId, letter.Letter, letter.MinPercent)
+		if err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to insert grade letter")
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::ReplaceSettings - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
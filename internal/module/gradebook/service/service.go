@@ -0,0 +1,369 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"hacko-app/internal/module/gradebook/entity"
+	"hacko-app/internal/module/gradebook/ports"
+	"hacko-app/pkg"
+	"hacko-app/pkg/errmsg"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.GradebookService = &gradebookService{}
+
+type gradebookService struct {
+	repo ports.GradebookRepository
+}
+
+func NewGradebookService(repo ports.GradebookRepository) *gradebookService {
+	return &gradebookService{
+		repo: repo,
+	}
+}
+
+// defaultLetters is used for classes that did not configure their own scale.
+var defaultLetters = []entity.GradeLetter{
+	{Letter: "A", MinPercent: 85},
+	{Letter: "B", MinPercent: 70},
+	{Letter: "C", MinPercent: 55},
+	{Letter: "D", MinPercent: 40},
+	{Letter: "E", MinPercent: 0},
+}
+
+func (s *gradebookService) GetGradebook(ctx context.Context, req *entity.GetGradebookRequest) (*entity.GetGradebookResponse, error) {
+	if err := s.repo.FindClassByCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.GetCategories(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	letters, err := s.repo.GetLetters(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+	if len(letters) == 0 {
+		letters = defaultLetters
+	}
+
+	items, err := s.repo.GetItems(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.repo.GetStudents(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := s.repo.GetScores(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetGradebookResponse{
+		ClassId:    req.ClassId,
+		Categories: categories,
+		Letters:    letters,
+		Items:      items,
+		Students:   buildRows(categories, letters, items, students, scores),
+	}, nil
+}
+
+func (s *gradebookService) ExportGradebook(ctx context.Context, req *entity.ExportGradebookRequest) (*entity.ExportGradebookResponse, error) {
+	gradebook, err := s.GetGradebook(ctx, &entity.GetGradebookRequest{UserId: req.UserId, ClassId: req.ClassId})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rows     = exportRows(gradebook)
+		buf      bytes.Buffer
+		filename = "gradebook-class-" + strconv.Itoa(req.ClassId)
+	)
+
+	if req.Format == "xlsx" {
+		if err := pkg.WriteXLSX(&buf, "Gradebook", rows); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("service::ExportGradebook - Failed to write xlsx")
+			return nil, err
+		}
+
+		return &entity.ExportGradebookResponse{
+			Filename:    filename + ".xlsx",
+			ContentType: pkg.MimeXLSX,
+			Data:        buf.Bytes(),
+		}, nil
+	}
+
+	w := csv.NewWriter(&buf)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = csvValue(value)
+		}
+		if err := w.Write(record); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("service::ExportGradebook - Failed to write csv")
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("service::ExportGradebook - Failed to write csv")
+		return nil, err
+	}
+
+	return &entity.ExportGradebookResponse{
+		Filename:    filename + ".csv",
+		ContentType: "text/csv; charset=utf-8",
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+func (s *gradebookService) UpdateSettings(ctx context.Context, req *entity.UpdateGradebookSettingsRequest) (*entity.GradebookSettingsResponse, error) {
+	if err := s.repo.FindClassByCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := validateSettings(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("service::UpdateSettings - Invalid gradebook settings")
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceSettings(ctx, req); err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.GetCategories(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	letters, err := s.repo.GetLetters(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+	if len(letters) == 0 {
+		letters = defaultLetters
+	}
+
+	items, err := s.repo.GetItems(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GradebookSettingsResponse{
+		ClassId:    req.ClassId,
+		Categories: categories,
+		Letters:    letters,
+		Items:      items,
+	}, nil
+}
+
+// validateSettings requires the weights to add up to 100 and every item and letter to appear once.
+func validateSettings(req *entity.UpdateGradebookSettingsRequest) error {
+	var (
+		errs        = errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid gradebook settings"))
+		total       float64
+		assignments = map[int]bool{}
+		quizzes     = map[int]bool{}
+		letters     = map[string]bool{}
+	)
+
+	for _, category := range req.Categories {
+		total += category.Weight
+
+		for _, id := range category.AssignmentIds {
+			if assignments[id] {
+				errs.Add("categories", "assignment "+strconv.Itoa(id)+" is in more than one category.")
+			}
+			assignments[id] = true
+		}
+		for _, id := range category.QuizIds {
+			if quizzes[id] {
+				errs.Add("categories", "quiz "+strconv.Itoa(id)+" is in more than one category.")
+			}
+			quizzes[id] = true
+		}
+	}
+
+	if len(req.Categories) > 0 && math.Abs(total-100) > 0.001 {
+		errs.Add("categories", "category weights must add up to 100, got "+strconv.FormatFloat(total, 'f', -1, 64)+".")
+	}
+
+	for _, letter := range req.Letters {
+		key := strings.ToUpper(letter.Letter)
+		if letters[key] {
+			errs.Add("letters", "letter "+letter.Letter+" is used more than once.")
+		}
+		letters[key] = true
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// buildRows computes one row per student. Only graded items count: within a category the points are summed,
+// categories are weighted and the weights of categories without any grade are left out. A class without
+// categories is graded on its total points, with categories the uncategorized items are shown but not counted.
+func buildRows(categories []entity.GradeCategory, letters []entity.GradeLetter, items []entity.GradebookItem, students []entity.GradebookStudent, scores []entity.GradebookScore) []entity.GradebookRow {
+	type key struct {
+		itemType  string
+		itemId    int
+		studentId string
+	}
+
+	graded := make(map[key]entity.GradebookScore, len(scores))
+	for _, score := range scores {
+		graded[key{score.ItemType, score.ItemId, score.StudentId}] = score
+	}
+
+	weights := make(map[int]float64, len(categories))
+	for _, category := range categories {
+		weights[category.Id] = category.Weight
+	}
+
+	rows := make([]entity.GradebookRow, 0, len(students))
+	for _, student := range students {
+		var (
+			row = entity.GradebookRow{
+				StudentId:  student.Id,
+				Name:       student.Name,
+				Email:      student.Email,
+				Scores:     make([]*float64, len(items)),
+				Categories: make([]entity.CategoryGrade, 0, len(categories)),
+			}
+			earned   = map[int]float64{}
+			possible = map[int]float64{}
+		)
+
+		for i, item := range items {
+			score, ok := graded[key{item.Type, item.Id, student.Id}]
+			if !ok {
+				continue
+			}
+
+			value := score.Score
+			row.Scores[i] = &value
+
+			category := 0
+			if item.CategoryId != nil {
+				category = *item.CategoryId
+			}
+			earned[category] += score.Score
+			possible[category] += score.MaxScore
+		}
+
+		if len(categories) == 0 {
+			var totalEarned, totalPossible float64
+			for category := range possible {
+				totalEarned += earned[category]
+				totalPossible += possible[category]
+			}
+			if totalPossible > 0 {
+				row.FinalPercent = percent(totalEarned, totalPossible)
+			}
+		} else {
+			var weighted, usedWeight float64
+			for _, category := range categories {
+				grade := entity.CategoryGrade{CategoryId: category.Id}
+				if possible[category.Id] > 0 {
+					grade.Percent = percent(earned[category.Id], possible[category.Id])
+					weighted += weights[category.Id] * *grade.Percent
+					usedWeight += weights[category.Id]
+				}
+				row.Categories = append(row.Categories, grade)
+			}
+			if usedWeight > 0 {
+				final := math.Round(weighted/usedWeight*100) / 100
+				row.FinalPercent = &final
+			}
+		}
+
+		if row.FinalPercent != nil {
+			row.Letter = letterFor(letters, *row.FinalPercent)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func percent(earned, possible float64) *float64 {
+	p := math.Round(earned/possible*10000) / 100
+	return &p
+}
+
+// letterFor returns the letter with the highest minimum that the percentage reaches.
+func letterFor(letters []entity.GradeLetter, p float64) string {
+	sorted := make([]entity.GradeLetter, len(letters))
+	copy(sorted, letters)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MinPercent > sorted[j].MinPercent })
+
+	for _, letter := range sorted {
+		if p >= letter.MinPercent {
+			return letter.Letter
+		}
+	}
+
+	return ""
+}
+
+func exportRows(gradebook *entity.GetGradebookResponse) [][]any {
+	header := []any{"Student", "Email"}
+	for _, item := range gradebook.Items {
+		header = append(header, item.Title+" ("+item.Type+", /"+strconv.FormatFloat(item.MaxScore, 'f', -1, 64)+")")
+	}
+	for _, category := range gradebook.Categories {
+		header = append(header, category.Name+" % ("+strconv.FormatFloat(category.Weight, 'f', -1, 64)+"%)")
+	}
+	header = append(header, "Final %", "Letter")
+
+	rows := [][]any{header}
+	for _, student := range gradebook.Students {
+		row := []any{student.Name, student.Email}
+		for _, score := range student.Scores {
+			row = append(row, score)
+		}
+		for _, category := range student.Categories {
+			row = append(row, category.Percent)
+		}
+		row = append(row, student.FinalPercent, student.Letter)
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	case string:
+		// keep spreadsheet apps from evaluating names as formulas
+		if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		return ""
+	}
+}
@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"hacko-app/pkg"
+	"mime/multipart"
+)
+
 type CreateModulesRequest struct {
 	UserId      string   `validate:"required"`
 	MaterialsId int      `json:"materials_id"`
@@ -7,6 +12,7 @@ type CreateModulesRequest struct {
 	Content     string   `json:"content"`
 	Attachments []string `json:"attachments"`
 	Videos      []string `json:"videos"`
+	Draft       bool     `json:"draft"`
 }
 
 type CreateModulesResponse struct {
@@ -15,6 +21,8 @@ type CreateModulesResponse struct {
 	Content     string   `json:"content" db:"content"`
 	Attachments []string `json:"attachments" db:"attachments"`
 	Videos      []string `json:"videos" db:"videos"`
+	Status      string   `json:"status" db:"status"`
+	RevisionId  int      `json:"revision_id" db:"revision_id"`
 	CreatedAt   string   `json:"created_at" db:"created_at"`
 	UpdatedAt   string   `json:"updated_at" db:"updated_at"`
 }
@@ -26,6 +34,10 @@ type UpdateModulesRequest struct {
 	Content     string   `json:"content"`
 	Attachments []string `json:"attachments"`
 	Videos      []string `json:"videos"`
+	Draft       bool     `json:"draft"`
+
+	// SourceRevisionId is set when the update comes from restoring or publishing a revision
+	SourceRevisionId *int `json:"-"`
 }
 
 type UpdateModulesResponse struct {
@@ -34,6 +46,8 @@ type UpdateModulesResponse struct {
 	Content     string   `json:"content" db:"content"`
 	Attachments []string `json:"attachments" db:"attachments"`
 	Videos      []string `json:"videos" db:"videos"`
+	Status      string   `json:"status" db:"status"`
+	RevisionId  int      `json:"revision_id" db:"revision_id"`
 	CreatedAt   string   `json:"created_at" db:"created_at"`
 	UpdatedAt   string   `json:"updated_at" db:"updated_at"`
 }
@@ -41,4 +55,107 @@ type UpdateModulesResponse struct {
 type DeleteModulesRequest struct {
 	UserId      string   `validate:"required"`
 	ModulesId   int      `json:"modules_id"`
-}
\ No newline at end of file
+}
+
+type ModuleRevision struct {
+	Id               int      `json:"id" db:"id"`
+	ModuleId         int      `json:"module_id" db:"module_id"`
+	AuthorId         string   `json:"author_id" db:"author_id"`
+	AuthorName       string   `json:"author_name" db:"author_name"`
+	Title            string   `json:"title" db:"title"`
+	Content          string   `json:"content" db:"content"`
+	Attachments      []string `json:"attachments" db:"attachments"`
+	Videos           []string `json:"videos" db:"videos"`
+	Status           string   `json:"status" db:"status"`
+	SourceRevisionId *int     `json:"source_revision_id" db:"source_revision_id"`
+	CreatedAt        string   `json:"created_at" db:"created_at"`
+}
+
+type GetModuleRevisionsRequest struct {
+	UserId    string `validate:"required"`
+	ModulesId int    `json:"modules_id" validate:"required"`
+}
+
+type GetModuleRevisionsResponse struct {
+	Id               int    `json:"id" db:"id"`
+	AuthorId         string `json:"author_id" db:"author_id"`
+	AuthorName       string `json:"author_name" db:"author_name"`
+	Title            string `json:"title" db:"title"`
+	Status           string `json:"status" db:"status"`
+	SourceRevisionId *int   `json:"source_revision_id" db:"source_revision_id"`
+	CreatedAt        string `json:"created_at" db:"created_at"`
+}
+
+type GetModuleRevisionRequest struct {
+	UserId     string `validate:"required"`
+	ModulesId  int    `json:"modules_id" validate:"required"`
+	RevisionId int    `json:"revision_id" validate:"required"`
+}
+
+type DiffModuleRevisionsRequest struct {
+	UserId         string `validate:"required"`
+	ModulesId      int    `json:"modules_id" validate:"required"`
+	FromRevisionId int    `query:"from" validate:"required"`
+	ToRevisionId   int    `query:"to" validate:"required"`
+}
+
+type DiffValue struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Changed bool   `json:"changed"`
+}
+
+type DiffSet struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+type DiffModuleRevisionsResponse struct {
+	From        GetModuleRevisionsResponse `json:"from"`
+	To          GetModuleRevisionsResponse `json:"to"`
+	Title       DiffValue                  `json:"title"`
+	Content     []pkg.DiffLine             `json:"content"`
+	Attachments DiffSet                    `json:"attachments"`
+	Videos      DiffSet                    `json:"videos"`
+}
+
+type RestoreModuleRevisionRequest struct {
+	UserId     string `validate:"required"`
+	ModulesId  int    `json:"modules_id" validate:"required"`
+	RevisionId int    `json:"revision_id" validate:"required"`
+	Draft      bool   `json:"draft"`
+}
+
+type PublishModulesRequest struct {
+	UserId    string `validate:"required"`
+	ModulesId int    `json:"modules_id" validate:"required"`
+}
+
+type ModuleAttachment struct {
+	Id         int    `json:"id" db:"id"`
+	ModuleId   int    `json:"module_id" db:"module_id"`
+	UploaderId string `json:"uploader_id" db:"uploader_id"`
+	Filename   string `json:"filename" db:"filename"`
+	Size       int64  `json:"size" db:"size"`
+	MimeType   string `json:"mime_type" db:"mime_type"`
+	StorageKey string `json:"storage_key" db:"storage_key"`
+	Url        string `json:"url" db:"url"`
+	CreatedAt  string `json:"created_at" db:"created_at"`
+}
+
+type UploadModuleAttachmentRequest struct {
+	UserId    string          `validate:"required"`
+	ModulesId int             `json:"modules_id" validate:"required"`
+	File      *multipart.Part `form:"file" validate:"required"` // read as it arrives
+}
+
+type GetModuleAttachmentsRequest struct {
+	UserId    string `validate:"required"`
+	ModulesId int    `json:"modules_id" validate:"required"`
+}
+
+type DeleteModuleAttachmentRequest struct {
+	UserId       string `validate:"required"`
+	ModulesId    int    `json:"modules_id" validate:"required"`
+	AttachmentId int    `json:"attachment_id" validate:"required"`
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"hacko-app/internal/adapter"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/middleware"
 	"hacko-app/internal/module/modules/entity"
 	"hacko-app/internal/module/modules/ports"
@@ -23,7 +24,8 @@ func NewModulesHandler() *modulesHandler {
 	var handler = new(modulesHandler)
 
 	repo := repository.NewModulesRepository(adapter.Adapters.HackoPostgres)
-	modulesService := service.NewModulesService(repo)
+	storage := integStorage.NewStorageIntegration()
+	modulesService := service.NewModulesService(repo, storage)
 
 	handler.service = modulesService
 	return handler
@@ -33,6 +35,18 @@ func (h *modulesHandler) Register(router fiber.Router) {
 	router.Post("/class/materials/:materialsId/modules", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.CreateModules)
 	router.Put("/class/materials/modules/:modulesId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UpdateModules)
 	router.Delete("/class/materials/modules/:modulesId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.DeleteModules)
+	router.Patch("/class/materials/modules/:modulesId/publish", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.PublishModules)
+
+	// attachment routes
+	router.Post("/class/materials/modules/:modulesId/attachments", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UploadModuleAttachment).Name(middleware.UploadRoute(integStorage.CategoryAttachment))
+	router.Get("/class/materials/modules/:modulesId/attachments", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetModuleAttachments)
+	router.Delete("/class/materials/modules/:modulesId/attachments/:attachmentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.DeleteModuleAttachment)
+
+	// revision routes
+	router.Get("/class/materials/modules/:modulesId/revisions", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetModuleRevisions)
+	router.Get("/class/materials/modules/:modulesId/revisions/diff", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.DiffModuleRevisions)
+	router.Get("/class/materials/modules/:modulesId/revisions/:revisionId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetModuleRevision)
+	router.Post("/class/materials/modules/:modulesId/revisions/:revisionId/restore", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.RestoreModuleRevision)
 }
 
 func (h *modulesHandler) CreateModules(c *fiber.Ctx) error {
@@ -148,4 +162,309 @@ func (h *modulesHandler) DeleteModules(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(nil, "successfully deleted module"))
-}
\ No newline at end of file
+}
+
+func (h *modulesHandler) PublishModules(c *fiber.Ctx) error {
+	var (
+		req = new(entity.PublishModulesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::PublishModules - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	req.ModulesId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::PublishModules - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.PublishModules(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, "successfully published module"))
+}
+
+func (h *modulesHandler) GetModuleRevisions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetModuleRevisionsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleRevisions - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	req.ModulesId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleRevisions - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetModuleRevisions(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *modulesHandler) GetModuleRevision(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetModuleRevisionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	modulesId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleRevision - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	revisionId, err := strconv.Atoi(c.Params("revisionId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleRevision - Failed to parsing id revision")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id revision"))))
+	}
+
+	req.ModulesId = modulesId
+	req.RevisionId = revisionId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleRevision - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetModuleRevision(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *modulesHandler) DiffModuleRevisions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DiffModuleRevisionsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DiffModuleRevisions - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	req.ModulesId = reqId
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::DiffModuleRevisions - Failed to parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::DiffModuleRevisions - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.DiffModuleRevisions(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *modulesHandler) RestoreModuleRevision(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RestoreModuleRevisionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	modulesId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::RestoreModuleRevision - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	revisionId, err := strconv.Atoi(c.Params("revisionId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::RestoreModuleRevision - Failed to parsing id revision")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id revision"))))
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			log.Warn().Err(err).Msg("handler::RestoreModuleRevision - Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+		}
+	}
+
+	req.ModulesId = modulesId
+	req.RevisionId = revisionId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::RestoreModuleRevision - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.RestoreModuleRevision(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, "successfully restored module revision"))
+}
+
+func (h *modulesHandler) UploadModuleAttachment(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UploadModuleAttachmentRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UploadModuleAttachment - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	req.ModulesId = reqId
+
+	file, err := middleware.FormFile(c, "file")
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UploadModuleAttachment - Failed to get file from form")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	req.File = file
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UploadModuleAttachment - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UploadModuleAttachment(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *modulesHandler) GetModuleAttachments(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetModuleAttachmentsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	reqId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleAttachments - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	req.ModulesId = reqId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetModuleAttachments - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetModuleAttachments(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *modulesHandler) DeleteModuleAttachment(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteModuleAttachmentRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	modulesId, err := strconv.Atoi(c.Params("modulesId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteModuleAttachment - Failed to parsing id modules")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id modules"))))
+	}
+
+	attachmentId, err := strconv.Atoi(c.Params("attachmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteModuleAttachment - Failed to parsing id attachment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id attachment"))))
+	}
+
+	req.ModulesId = modulesId
+	req.AttachmentId = attachmentId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteModuleAttachment - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteModuleAttachment(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, "successfully deleted attachment"))
+}
@@ -3,16 +3,42 @@ package ports
 import (
 	"context"
 	"hacko-app/internal/module/modules/entity"
+	"hacko-app/pkg/types"
 )
 
 type ModulesRepository interface {
 	CreateModules(ctx context.Context, req *entity.CreateModulesRequest) (*entity.CreateModulesResponse, error)
 	UpdateModules(ctx context.Context, req *entity.UpdateModulesRequest) (*entity.UpdateModulesResponse, error)
 	DeleteModules(ctx context.Context, req *entity.DeleteModulesRequest) error
+
+	// revision contract
+	GetModuleRevisions(ctx context.Context, req *entity.GetModuleRevisionsRequest) ([]entity.GetModuleRevisionsResponse, error)
+	GetModuleRevision(ctx context.Context, req *entity.GetModuleRevisionRequest) (*entity.ModuleRevision, error)
+	GetLatestModuleRevision(ctx context.Context, req *entity.PublishModulesRequest) (*entity.ModuleRevision, error)
+
+	// attachment contract
+	FindModuleByCreator(ctx context.Context, modulesId int, userId string) error
+	CreateModuleAttachment(ctx context.Context, req *entity.ModuleAttachment) (*entity.ModuleAttachment, error)
+	GetModuleAttachments(ctx context.Context, req *entity.GetModuleAttachmentsRequest) ([]entity.ModuleAttachment, error)
+	GetModuleAttachmentsByCreator(ctx context.Context, modulesId int, userId string) ([]entity.ModuleAttachment, error)
+	DeleteModuleAttachment(ctx context.Context, req *entity.DeleteModuleAttachmentRequest) (*entity.ModuleAttachment, error)
+	GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error)
 }
 
 type ModulesService interface {
 	CreateModules(ctx context.Context, req *entity.CreateModulesRequest) (*entity.CreateModulesResponse, error)
 	UpdateModules(ctx context.Context, req *entity.UpdateModulesRequest) (*entity.UpdateModulesResponse, error)
 	DeleteModules(ctx context.Context, req *entity.DeleteModulesRequest) error
+
+	// revision contract
+	GetModuleRevisions(ctx context.Context, req *entity.GetModuleRevisionsRequest) ([]entity.GetModuleRevisionsResponse, error)
+	GetModuleRevision(ctx context.Context, req *entity.GetModuleRevisionRequest) (*entity.ModuleRevision, error)
+	DiffModuleRevisions(ctx context.Context, req *entity.DiffModuleRevisionsRequest) (*entity.DiffModuleRevisionsResponse, error)
+	RestoreModuleRevision(ctx context.Context, req *entity.RestoreModuleRevisionRequest) (*entity.UpdateModulesResponse, error)
+	PublishModules(ctx context.Context, req *entity.PublishModulesRequest) (*entity.UpdateModulesResponse, error)
+
+	// attachment contract
+	UploadModuleAttachment(ctx context.Context, req *entity.UploadModuleAttachmentRequest) (*entity.ModuleAttachment, error)
+	GetModuleAttachments(ctx context.Context, req *entity.GetModuleAttachmentsRequest) ([]entity.ModuleAttachment, error)
+	DeleteModuleAttachment(ctx context.Context, req *entity.DeleteModuleAttachmentRequest) error
 }
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"hacko-app/internal/module/modules/entity"
 	"hacko-app/internal/module/modules/ports"
+	storageRepo "hacko-app/internal/module/storage/repository"
 	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/types"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -28,6 +30,24 @@ func NewModulesRepository(db *sqlx.DB) *modulesRepository {
 func (r *modulesRepository) CreateModules(ctx context.Context, req *entity.CreateModulesRequest) (*entity.CreateModulesResponse, error) {
 	var res = new(entity.CreateModulesResponse)
 
+	status := "published"
+	if req.Draft {
+		status = "draft"
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateModules - Failed to start transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::CreateModules - Failed to rollback transaction")
+			}
+		}
+	}()
+
 	query := `
 		INSERT INTO modules (
 		creator_modules_id,
@@ -35,31 +55,34 @@ func (r *modulesRepository) CreateModules(ctx context.Context, req *entity.Creat
 		title,
 		content,
 		attachments,
-		videos
+		videos,
+		status
 	)
 	SELECT
-		$1, $2, $3, $4, $5, $6
+		$1, $2, $3, $4, $5, $6, $7
 	WHERE EXISTS (
 		SELECT 1
 		FROM materials
 		WHERE id = $2 AND creator_materials_id = $1 
 	)
-	RETURNING id, title, content, attachments, videos, created_at, updated_at
+	RETURNING id, title, content, attachments, videos, status, created_at, updated_at
     `
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		req.UserId,
 		req.MaterialsId,
 		req.Title,
 		req.Content,
 		pq.Array(req.Attachments),
 		pq.Array(req.Videos),
+		status,
 	).Scan(
 		&res.Id,
 		&res.Title,
 		&res.Content,
 		pq.Array(&res.Attachments),
 		pq.Array(&res.Videos),
+		&res.Status,
 		&res.CreatedAt,
 		&res.UpdatedAt,
 	)
@@ -86,57 +109,145 @@ func (r *modulesRepository) CreateModules(ctx context.Context, req *entity.Creat
 		return nil, err
 	}
 
+	res.RevisionId, err = r.insertRevision(ctx, tx, &entity.ModuleRevision{
+		ModuleId:    res.Id,
+		AuthorId:    req.UserId,
+		Title:       req.Title,
+		Content:     req.Content,
+		Attachments: req.Attachments,
+		Videos:      req.Videos,
+		Status:      status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateModules - Failed to commit transaction")
+		return nil, err
+	}
+
 	return res, nil
 }
 
 func (r *modulesRepository) UpdateModules(ctx context.Context, req *entity.UpdateModulesRequest) (*entity.UpdateModulesResponse, error) {
-	var res = new(entity.UpdateModulesResponse)
+	var (
+		res           = new(entity.UpdateModulesResponse)
+		currentStatus string
+	)
 
-	query := `
+	status := "published"
+	if req.Draft {
+		status = "draft"
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateModules - Failed to start transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::UpdateModules - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	lockQuery := `
+		SELECT 
+			status, created_at, updated_at
+		FROM 
+			modules
+		WHERE 
+			id = $1 AND
+			creator_modules_id = $2
+		FOR UPDATE
+	`
+
+	err = tx.QueryRowContext(ctx, lockQuery, req.ModulesId, req.UserId).Scan(&currentStatus, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::UpdateModules - Module not found or unauthorized")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found or you are not authorized to update it"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateModules - Failed to lock module")
+		return nil, err
+	}
+
+	// a draft of a published module is only stored as a revision, students keep seeing the published content
+	if req.Draft && currentStatus == "published" {
+		res.Id = req.ModulesId
+		res.Title = req.Title
+		res.Content = req.Content
+		res.Attachments = req.Attachments
+		res.Videos = req.Videos
+		res.Status = status
+	} else {
+		query := `
 		UPDATE modules
 		SET 
 			title = $1,
 			content = $2,
 			attachments = $3,
 			videos = $4,
+			status = $5,
 			updated_at = NOW()
 		WHERE 
-			id = $5 AND
-			creator_modules_id = $6
+			id = $6 AND
+			creator_modules_id = $7
 		RETURNING 
-			id, title, content, attachments, videos, created_at, updated_at
+			id, title, content, attachments, videos, status, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
-		req.Title,                    
-		req.Content,                  
-		pq.Array(req.Attachments),    
-		pq.Array(req.Videos),         
-		req.ModulesId,                  
-		req.UserId,                   
-	).Scan(
-		&res.Id,
-		&res.Title,
-		&res.Content,
-		pq.Array(&res.Attachments),
-		pq.Array(&res.Videos),
-		&res.CreatedAt,
-		&res.UpdatedAt,
-	)
+		err = tx.QueryRowContext(ctx, query,
+			req.Title,
+			req.Content,
+			pq.Array(req.Attachments),
+			pq.Array(req.Videos),
+			status,
+			req.ModulesId,
+			req.UserId,
+		).Scan(
+			&res.Id,
+			&res.Title,
+			&res.Content,
+			pq.Array(&res.Attachments),
+			pq.Array(&res.Videos),
+			&res.Status,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+		)
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Warn().Any("payload", req).Msg("repo::UpdateModules - Module not found or unauthorized")
-			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found or you are not authorized to update it"))
-		}
+		if err != nil {
+			pqErr, ok := err.(*pq.Error)
+			if ok {
+				log.Error().Err(pqErr).Any("payload", req).Msg("repo::UpdateModules - Unhandled PostgreSQL error")
+				return nil, err
+			}
 
-		pqErr, ok := err.(*pq.Error)
-		if ok {
-			log.Error().Err(pqErr).Any("payload", req).Msg("repo::UpdateModules - Unhandled PostgreSQL error")
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateModules - Failed to update module")
 			return nil, err
 		}
+	}
+
+	res.RevisionId, err = r.insertRevision(ctx, tx, &entity.ModuleRevision{
+		ModuleId:         req.ModulesId,
+		AuthorId:         req.UserId,
+		Title:            req.Title,
+		Content:          req.Content,
+		Attachments:      req.Attachments,
+		Videos:           req.Videos,
+		Status:           status,
+		SourceRevisionId: req.SourceRevisionId,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateModules - Failed to update module")
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateModules - Failed to commit transaction")
 		return nil, err
 	}
 
@@ -175,3 +286,335 @@ func (r *modulesRepository) DeleteModules(ctx context.Context, req *entity.Delet
 
 	return nil
 }
+
+func (r *modulesRepository) GetModuleRevisions(ctx context.Context, req *entity.GetModuleRevisionsRequest) ([]entity.GetModuleRevisionsResponse, error) {
+	query := `
+		SELECT 
+			r.id,
+			r.author_id,
+			u.name AS author_name,
+			r.title,
+			r.status,
+			r.source_revision_id,
+			r.created_at
+		FROM 
+			module_revisions r
+		INNER JOIN 
+			modules m ON m.id = r.module_id
+		INNER JOIN 
+			users u ON u.id = r.author_id
+		WHERE 
+			r.module_id = $1 AND
+			m.creator_modules_id = $2
+		ORDER BY 
+			r.id DESC
+	`
+
+	var revisions []entity.GetModuleRevisionsResponse
+	if err := r.db.SelectContext(ctx, &revisions, query, req.ModulesId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetModuleRevisions - Failed to get module revisions")
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		log.Warn().Any("payload", req).Msg("repo::GetModuleRevisions - Module not found or unauthorized")
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found or you are not authorized to access it"))
+	}
+
+	return revisions, nil
+}
+
+func (r *modulesRepository) GetModuleRevision(ctx context.Context, req *entity.GetModuleRevisionRequest) (*entity.ModuleRevision, error) {
+	query := `
+		SELECT 
+			r.id,
+			r.module_id,
+			r.author_id,
+			u.name AS author_name,
+			r.title,
+			COALESCE(r.content, '') AS content,
+			r.attachments,
+			r.videos,
+			r.status,
+			r.source_revision_id,
+			r.created_at
+		FROM 
+			module_revisions r
+		INNER JOIN 
+			modules m ON m.id = r.module_id
+		INNER JOIN 
+			users u ON u.id = r.author_id
+		WHERE 
+			r.id = $1 AND
+			r.module_id = $2 AND
+			m.creator_modules_id = $3
+	`
+
+	res, err := r.scanRevision(r.db.QueryRowContext(ctx, query, req.RevisionId, req.ModulesId, req.UserId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::GetModuleRevision - Revision not found or unauthorized")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Revision not found or you are not authorized to access it"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetModuleRevision - Failed to get module revision")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *modulesRepository) GetLatestModuleRevision(ctx context.Context, req *entity.PublishModulesRequest) (*entity.ModuleRevision, error) {
+	query := `
+		SELECT 
+			r.id,
+			r.module_id,
+			r.author_id,
+			u.name AS author_name,
+			r.title,
+			COALESCE(r.content, '') AS content,
+			r.attachments,
+			r.videos,
+			r.status,
+			r.source_revision_id,
+			r.created_at
+		FROM 
+			module_revisions r
+		INNER JOIN 
+			modules m ON m.id = r.module_id
+		INNER JOIN 
+			users u ON u.id = r.author_id
+		WHERE 
+			r.module_id = $1 AND
+			m.creator_modules_id = $2
+		ORDER BY 
+			r.id DESC
+		LIMIT 1
+	`
+
+	res, err := r.scanRevision(r.db.QueryRowContext(ctx, query, req.ModulesId, req.UserId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::GetLatestModuleRevision - Module not found or unauthorized")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found or you are not authorized to access it"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetLatestModuleRevision - Failed to get module revision")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *modulesRepository) insertRevision(ctx context.Context, tx *sqlx.Tx, rev *entity.ModuleRevision) (int, error) {
+	query := `
+		INSERT INTO module_revisions (
+			module_id,
+			author_id,
+			title,
+			content,
+			attachments,
+			videos,
+			status,
+			source_revision_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	var id int
+	err := tx.QueryRowContext(ctx, query,
+		rev.ModuleId,
+		rev.AuthorId,
+		rev.Title,
+		rev.Content,
+		pq.Array(rev.Attachments),
+		pq.Array(rev.Videos),
+		rev.Status,
+		rev.SourceRevisionId,
+	).Scan(&id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", rev).Msg("repo::insertRevision - Failed to insert module revision")
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *modulesRepository) scanRevision(row *sql.Row) (*entity.ModuleRevision, error) {
+	var res = new(entity.ModuleRevision)
+
+	err := row.Scan(
+		&res.Id,
+		&res.ModuleId,
+		&res.AuthorId,
+		&res.AuthorName,
+		&res.Title,
+		&res.Content,
+		pq.Array(&res.Attachments),
+		pq.Array(&res.Videos),
+		&res.Status,
+		&res.SourceRevisionId,
+		&res.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *modulesRepository) FindModuleByCreator(ctx context.Context, modulesId int, userId string) error {
+	query := `
+		SELECT 
+			1
+		FROM 
+			modules
+		WHERE 
+			id = $1 AND
+			creator_modules_id = $2
+	`
+
+	var exists int
+	err := r.db.QueryRowContext(ctx, query, modulesId, userId).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("modules_id", modulesId).Msg("repo::FindModuleByCreator - Module not found or unauthorized")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found or you are not authorized to update it"))
+		}
+
+		log.Error().Err(err).Int("modules_id", modulesId).Msg("repo::FindModuleByCreator - Failed to query module")
+		return err
+	}
+
+	return nil
+}
+
+func (r *modulesRepository) CreateModuleAttachment(ctx context.Context, req *entity.ModuleAttachment) (*entity.ModuleAttachment, error) {
+	var res = new(entity.ModuleAttachment)
+
+	query := `
+		INSERT INTO module_attachments (
+			module_id,
+			uploader_id,
+			filename,
+			size,
+			mime_type,
+			storage_key,
+			url
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, module_id, uploader_id, filename, size, mime_type, storage_key, url, created_at
+	`
+
+	err := r.db.GetContext(ctx, res, query,
+		req.ModuleId,
+		req.UploaderId,
+		req.Filename,
+		req.Size,
+		req.MimeType,
+		req.StorageKey,
+		req.Url,
+	)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code.Name() == "foreign_key_violation" {
+			log.Warn().Any("payload", req).Msg("repo::CreateModuleAttachment - Module not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Module not found"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateModuleAttachment - Failed to insert module attachment")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *modulesRepository) GetModuleAttachments(ctx context.Context, req *entity.GetModuleAttachmentsRequest) ([]entity.ModuleAttachment, error) {
+	// attachments are visible to the module creator and, once published, to students enrolled in the class
+	query := `
+		SELECT 
+			a.id, a.module_id, a.uploader_id, a.filename, a.size, a.mime_type, a.storage_key, a.url, a.created_at
+		FROM 
+			module_attachments a
+		INNER JOIN 
+			modules m ON m.id = a.module_id
+		INNER JOIN 
+			materials mt ON mt.id = m.materials_id
+		WHERE 
+			a.module_id = $1 AND (
+				m.creator_modules_id = $2 OR (
+					m.status = 'published' AND EXISTS (
+						SELECT 1
+						FROM users_classes uc
+						WHERE uc.class_id = mt.class_id AND uc.user_id = $2
+					)
+				)
+			)
+		ORDER BY 
+			a.id
+	`
+
+	var attachments = []entity.ModuleAttachment{}
+	if err := r.db.SelectContext(ctx, &attachments, query, req.ModulesId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetModuleAttachments - Failed to get module attachments")
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *modulesRepository) GetModuleAttachmentsByCreator(ctx context.Context, modulesId int, userId string) ([]entity.ModuleAttachment, error) {
+	query := `
+		SELECT 
+			a.id, a.module_id, a.uploader_id, a.filename, a.size, a.mime_type, a.storage_key, a.url, a.created_at
+		FROM 
+			module_attachments a
+		INNER JOIN 
+			modules m ON m.id = a.module_id
+		WHERE 
+			a.module_id = $1 AND
+			m.creator_modules_id = $2
+	`
+
+	var attachments = []entity.ModuleAttachment{}
+	if err := r.db.SelectContext(ctx, &attachments, query, modulesId, userId); err != nil {
+		log.Error().Err(err).Int("modules_id", modulesId).Msg("repo::GetModuleAttachmentsByCreator - Failed to get module attachments")
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *modulesRepository) DeleteModuleAttachment(ctx context.Context, req *entity.DeleteModuleAttachmentRequest) (*entity.ModuleAttachment, error) {
+	var res = new(entity.ModuleAttachment)
+
+	query := `
+		DELETE FROM module_attachments a
+		USING modules m
+		WHERE 
+			a.module_id = m.id AND
+			a.id = $1 AND
+			a.module_id = $2 AND
+			m.creator_modules_id = $3
+		RETURNING a.id, a.module_id, a.uploader_id, a.filename, a.size, a.mime_type, a.storage_key, a.url, a.created_at
+	`
+
+	err := r.db.GetContext(ctx, res, query, req.AttachmentId, req.ModulesId, req.UserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::DeleteModuleAttachment - Attachment not found or unauthorized")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Attachment not found or you are not authorized to delete it"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteModuleAttachment - Failed to delete module attachment")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetStorageUsage is the quota check of the storage module.
+func (r *modulesRepository) GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error) {
+	return storageRepo.GetStorageUsage(ctx, r.db, userId)
+}
@@ -2,19 +2,29 @@ package service
 
 import (
 	"context"
+	"hacko-app/internal/infrastructure/config"
+	integStorage "hacko-app/internal/integration/storage"
+	storageEntity "hacko-app/internal/integration/storage/entity"
 	"hacko-app/internal/module/modules/entity"
 	"hacko-app/internal/module/modules/ports"
+	"hacko-app/pkg"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 var _ ports.ModulesService = &modulesService{}
 
 type modulesService struct {
-	repo ports.ModulesRepository
+	repo    ports.ModulesRepository
+	storage integStorage.Storage
 }
 
-func NewModulesService(repo ports.ModulesRepository) *modulesService {
+func NewModulesService(repo ports.ModulesRepository, storage integStorage.Storage) *modulesService {
 	return &modulesService{
-		repo: repo,
+		repo:    repo,
+		storage: storage,
 	}
 }
 
@@ -39,10 +49,230 @@ func (s *modulesService) UpdateModules(ctx context.Context, req *entity.UpdateMo
 }
 
 func (s *modulesService) DeleteModules(ctx context.Context, req *entity.DeleteModulesRequest) error {
-	err := s.repo.DeleteModules(ctx, req)
+	attachments, err := s.repo.GetModuleAttachmentsByCreator(ctx, req.ModulesId, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteModules(ctx, req)
 	if err != nil {
 		return  err
 	}
 
+	// the rows are gone with the module, objects that fail to delete are left for the storage cleanup
+	for _, attachment := range attachments {
+		s.deleteObject(ctx, attachment.StorageKey)
+	}
+
 	return nil
 }
+
+func (s *modulesService) GetModuleRevisions(ctx context.Context, req *entity.GetModuleRevisionsRequest) ([]entity.GetModuleRevisionsResponse, error) {
+	response, err := s.repo.GetModuleRevisions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) GetModuleRevision(ctx context.Context, req *entity.GetModuleRevisionRequest) (*entity.ModuleRevision, error) {
+	response, err := s.repo.GetModuleRevision(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) DiffModuleRevisions(ctx context.Context, req *entity.DiffModuleRevisionsRequest) (*entity.DiffModuleRevisionsResponse, error) {
+	from, err := s.repo.GetModuleRevision(ctx, &entity.GetModuleRevisionRequest{
+		UserId:     req.UserId,
+		ModulesId:  req.ModulesId,
+		RevisionId: req.FromRevisionId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.repo.GetModuleRevision(ctx, &entity.GetModuleRevisionRequest{
+		UserId:     req.UserId,
+		ModulesId:  req.ModulesId,
+		RevisionId: req.ToRevisionId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addedAttachments, removedAttachments := pkg.DiffSets(from.Attachments, to.Attachments)
+	addedVideos, removedVideos := pkg.DiffSets(from.Videos, to.Videos)
+
+	response := &entity.DiffModuleRevisionsResponse{
+		From: revisionSummary(from),
+		To:   revisionSummary(to),
+		Title: entity.DiffValue{
+			From:    from.Title,
+			To:      to.Title,
+			Changed: from.Title != to.Title,
+		},
+		Content: pkg.DiffLines(from.Content, to.Content),
+		Attachments: entity.DiffSet{
+			Added:   addedAttachments,
+			Removed: removedAttachments,
+		},
+		Videos: entity.DiffSet{
+			Added:   addedVideos,
+			Removed: removedVideos,
+		},
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) RestoreModuleRevision(ctx context.Context, req *entity.RestoreModuleRevisionRequest) (*entity.UpdateModulesResponse, error) {
+	revision, err := s.repo.GetModuleRevision(ctx, &entity.GetModuleRevisionRequest{
+		UserId:     req.UserId,
+		ModulesId:  req.ModulesId,
+		RevisionId: req.RevisionId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.repo.UpdateModules(ctx, &entity.UpdateModulesRequest{
+		UserId:           req.UserId,
+		ModulesId:        req.ModulesId,
+		Title:            revision.Title,
+		Content:          revision.Content,
+		Attachments:      revision.Attachments,
+		Videos:           revision.Videos,
+		Draft:            req.Draft,
+		SourceRevisionId: &revision.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) PublishModules(ctx context.Context, req *entity.PublishModulesRequest) (*entity.UpdateModulesResponse, error) {
+	revision, err := s.repo.GetLatestModuleRevision(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// publishing applies the latest revision, which is the pending draft if there is one
+	response, err := s.repo.UpdateModules(ctx, &entity.UpdateModulesRequest{
+		UserId:           req.UserId,
+		ModulesId:        req.ModulesId,
+		Title:            revision.Title,
+		Content:          revision.Content,
+		Attachments:      revision.Attachments,
+		Videos:           revision.Videos,
+		SourceRevisionId: &revision.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) UploadModuleAttachment(ctx context.Context, req *entity.UploadModuleAttachmentRequest) (*entity.ModuleAttachment, error) {
+	if err := s.repo.FindModuleByCreator(ctx, req.ModulesId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetStorageUsage(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded, err := integStorage.PutUpload(ctx, s.storage, &storageEntity.PutUploadRequest{
+		Category: integStorage.CategoryAttachment,
+		Key:      integStorage.Key(integStorage.VisibilityPrivate, "modules", strconv.Itoa(req.ModulesId), pkg.SanitizeFilename(req.File.FileName(), true)),
+		Filename: req.File.FileName(),
+		Body:     req.File,
+		Quota:    usage.Left(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("userId", req.UserId).Int("modulesId", req.ModulesId).Msg("service::UploadModuleAttachment - Failed to store uploaded file")
+		return nil, err
+	}
+
+	response, err := s.repo.CreateModuleAttachment(ctx, &entity.ModuleAttachment{
+		ModuleId:   req.ModulesId,
+		UploaderId: req.UserId,
+		Filename:   req.File.FileName(),
+		Size:       uploaded.Size,
+		MimeType:   uploaded.ContentType,
+		StorageKey: uploaded.Key,
+		Url:        uploaded.Url,
+	})
+	if err != nil {
+		s.deleteObject(ctx, uploaded.Key)
+		return nil, err
+	}
+
+	if err := s.signAttachment(ctx, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) GetModuleAttachments(ctx context.Context, req *entity.GetModuleAttachmentsRequest) ([]entity.ModuleAttachment, error) {
+	response, err := s.repo.GetModuleAttachments(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response {
+		if err := s.signAttachment(ctx, &response[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *modulesService) DeleteModuleAttachment(ctx context.Context, req *entity.DeleteModuleAttachmentRequest) error {
+	attachment, err := s.repo.DeleteModuleAttachment(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	s.deleteObject(ctx, attachment.StorageKey)
+
+	return nil
+}
+
+// signAttachment replaces the url with a short lived one, attachments are private and only reachable through it.
+func (s *modulesService) signAttachment(ctx context.Context, attachment *entity.ModuleAttachment) error {
+	url, err := s.storage.Presign(ctx, attachment.StorageKey, time.Duration(config.Envs.HackoStorage.UrlExpiry)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	attachment.Url = url
+	return nil
+}
+
+func (s *modulesService) deleteObject(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Warn().Err(err).Str("storage_key", key).Msg("service::deleteObject - Failed to delete object from storage")
+	}
+}
+
+func revisionSummary(rev *entity.ModuleRevision) entity.GetModuleRevisionsResponse {
+	return entity.GetModuleRevisionsResponse{
+		Id:               rev.Id,
+		AuthorId:         rev.AuthorId,
+		AuthorName:       rev.AuthorName,
+		Title:            rev.Title,
+		Status:           rev.Status,
+		SourceRevisionId: rev.SourceRevisionId,
+		CreatedAt:        rev.CreatedAt,
+	}
+}
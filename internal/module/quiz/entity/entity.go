@@ -22,12 +22,15 @@ type CreateQuizResponse struct {
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// CreateQuestionQuizRequest, a question without correct_answer is not graded.
 type CreateQuestionQuizRequest struct {
-	UserId   string          `validate:"required"`
-	QuizId   int             `json:"quiz_id" validate:"required"`
-	Type     string          `json:"type" validate:"required"`
-	Question string          `json:"question" validate:"required"`
-	Answers  json.RawMessage `json:"answers" validate:"required"`
+	UserId        string          `validate:"required"`
+	QuizId        int             `json:"quiz_id" validate:"required"`
+	Type          string          `json:"type" validate:"required"`
+	Question      string          `json:"question" validate:"required"`
+	Answers       json.RawMessage `json:"answers" validate:"required"`
+	CorrectAnswer json.RawMessage `json:"correct_answer"`
+	Points        *float64        `json:"points" validate:"omitempty,min=0"`
 }
 
 type CreateQuestionQuizResponse struct {
@@ -36,6 +39,8 @@ type CreateQuestionQuizResponse struct {
 	Type                  string          `json:"type" db:"type"`
 	Question              string          `json:"question" db:"question"`
 	Answers               json.RawMessage `json:"answers" db:"answers"`
+	CorrectAnswer         json.RawMessage `json:"correct_answer" db:"correct_answer"`
+	Points                float64         `json:"points" db:"points"`
 	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -72,14 +77,28 @@ type GetDetailsQuizResponse struct {
 	Question []GetQuestionQuizResponse `json:"question"`
 }
 
+// SubmitQuizRequest holds the answers keyed by question id, Score and MaxScore are filled in by the service.
 type SubmitQuizRequest struct {
-	QuizId int    `json:"quiz_id" validate:"required"`
-	UserId string `json:"user_id" validate:"required"`
+	QuizId   int                        `json:"quiz_id" validate:"required"`
+	UserId   string                     `json:"user_id" validate:"required"`
+	Answers  map[string]json.RawMessage `json:"answers"`
+	Score    float64                    `json:"-"`
+	MaxScore float64                    `json:"-"`
 }
+
 type SubmitQuizResponse struct {
 	Id        int       `json:"id" db:"id"`
 	QuizId    string    `json:"quiz_id" validate:"required"`
 	UserId    string    `json:"user_id" validate:"required"`
 	Status    string    `json:"status"`
+	Score     float64   `json:"score" db:"score"`
+	MaxScore  float64   `json:"max_score" db:"max_score"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// QuestionAnswerKey is the grading data of a question, CorrectAnswer is empty for ungraded questions.
+type QuestionAnswerKey struct {
+	Id            int     `db:"id"`
+	CorrectAnswer string  `db:"correct_answer"`
+	Points        float64 `db:"points"`
+}
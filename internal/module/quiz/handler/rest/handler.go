@@ -181,8 +181,6 @@ func (h *quizHandler) SubmitQuiz(c *fiber.Ctx) error {
 		l   = middleware.GetLocals(c)
 	)
 
-	req.UserId = l.GetUserId()
-
 	id := c.Params("quizId")
 
 	quizId, err := strconv.Atoi(id) 
@@ -191,7 +189,16 @@ func (h *quizHandler) SubmitQuiz(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(errmsg.NewCustomErrors(500, errmsg.WithMessage("Failed to parse quizId"))))
 	}
 
-	req.QuizId = quizId 
+	// answers are optional, a quiz without graded questions can be submitted empty
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			log.Warn().Err(err).Msg("handler::SubmitQuiz - Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+		}
+	}
+
+	req.UserId = l.GetUserId()
+	req.QuizId = quizId
 
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Msg("handler::SubmitQuiz - Invalid request body")
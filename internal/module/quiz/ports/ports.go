@@ -14,6 +14,7 @@ type QuizRepository interface {
 	GetDetailsQuiz(ctx context.Context, req *entity.GetDetailsQuizRequset) (*entity.GetDetailsQuizResponse, error)
 	FindUsersCompletedQuiz(ctx context.Context, req *entity.SubmitQuizRequest) error
 	SubmitQuiz(ctx context.Context, req *entity.SubmitQuizRequest) (*entity.SubmitQuizResponse, error)
+	GetQuizAnswerKey(ctx context.Context, quizId int) ([]entity.QuestionAnswerKey, error)
 }
 
 type QuizService interface {
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"hacko-app/internal/module/quiz/entity"
 	"hacko-app/internal/module/quiz/ports"
 	"hacko-app/pkg/errmsg"
@@ -76,11 +77,16 @@ func (r *quizRepository) FindQuiz(ctx context.Context, req int) error {
 
 func (r *quizRepository) CreateQuestionQuiz(ctx context.Context, req *entity.CreateQuestionQuizRequest) (*entity.CreateQuestionQuizResponse, error) {
 	query := `
-        INSERT INTO questions_quiz (quiz_id, creator_question_quiz_id, type, question, answers, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-        RETURNING id, creator_question_quiz_id, type, question, answers, created_at, updated_at;
+        INSERT INTO questions_quiz (quiz_id, creator_question_quiz_id, type, question, answers, correct_answer, points, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 1), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+        RETURNING id, creator_question_quiz_id, type, question, answers, COALESCE(correct_answer, 'null'), points, created_at, updated_at;
     `
 
+	var correctAnswer interface{}
+	if len(req.CorrectAnswer) > 0 {
+		correctAnswer = []byte(req.CorrectAnswer)
+	}
+
 	var resp entity.CreateQuestionQuizResponse
 	err := r.db.QueryRowContext(ctx, query,
 		req.QuizId,   // quiz_id
@@ -88,12 +94,16 @@ func (r *quizRepository) CreateQuestionQuiz(ctx context.Context, req *entity.Cre
 		req.Type,     // type
 		req.Question, // question
 		req.Answers,  // answers (JSON)
+		correctAnswer,
+		req.Points,
 	).Scan(
 		&resp.Id,
 		&resp.CreatorQuestionQuizId,
 		&resp.Type,
 		&resp.Question,
 		&resp.Answers,
+		&resp.CorrectAnswer,
+		&resp.Points,
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
 	)
@@ -184,16 +194,24 @@ func (r *quizRepository) FindUsersCompletedQuiz(ctx context.Context, req *entity
 func (r *quizRepository) SubmitQuiz(ctx context.Context, req *entity.SubmitQuizRequest) (*entity.SubmitQuizResponse, error) {
 
 	query := `
-        INSERT INTO users_completed_quiz (quiz_id, user_id)
-        VALUES ($1, $2)
-        RETURNING id, quiz_id, user_id, created_at
+        INSERT INTO users_completed_quiz (quiz_id, user_id, answers, score, max_score)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, quiz_id, user_id, score, max_score, created_at
     `
 
+	answers, err := json.Marshal(req.Answers)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::SubmitQuiz - Failed to marshal answers")
+		return nil, err
+	}
+
 	var response entity.SubmitQuizResponse
-	err := r.db.QueryRowContext(ctx, query, req.QuizId, req.UserId).Scan(
+	err = r.db.QueryRowContext(ctx, query, req.QuizId, req.UserId, answers, req.Score, req.MaxScore).Scan(
 		&response.Id,
 		&response.QuizId,
 		&response.UserId,
+		&response.Score,
+		&response.MaxScore,
 		&response.CreatedAt,
 	)
 
@@ -206,3 +224,20 @@ func (r *quizRepository) SubmitQuiz(ctx context.Context, req *entity.SubmitQuizR
 
 	return &response, nil
 }
+
+func (r *quizRepository) GetQuizAnswerKey(ctx context.Context, quizId int) ([]entity.QuestionAnswerKey, error) {
+	var res = make([]entity.QuestionAnswerKey, 0)
+
+	query := `
+		SELECT id, COALESCE(correct_answer::TEXT, '') AS correct_answer, points
+		FROM questions_quiz
+		WHERE quiz_id = $1
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, quizId); err != nil {
+		log.Error().Err(err).Int("quiz_id", quizId).Msg("repo::GetQuizAnswerKey - Failed to get answer key")
+		return nil, err
+	}
+
+	return res, nil
+}
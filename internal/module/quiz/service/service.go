@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"hacko-app/internal/module/quiz/entity"
 	"hacko-app/internal/module/quiz/ports"
+	"hacko-app/pkg/eventbus"
+	"reflect"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
 )
 
 var _ ports.QuizService = &quizService{}
@@ -86,10 +92,51 @@ func (s *quizService) SubmitQuiz(ctx context.Context, req *entity.SubmitQuizRequ
 		return nil, err
 	}
 
+	key, err := s.repo.GetQuizAnswerKey(ctx, req.QuizId)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Score, req.MaxScore = scoreQuiz(key, req.Answers)
+
 	response, err := s.repo.SubmitQuiz(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	eventbus.Publish(eventbus.QuizSubmittedEvent{UserId: req.UserId, QuizId: req.QuizId, Score: response.Score, MaxScore: response.MaxScore})
+
 	return response, nil
 }
+
+// scoreQuiz gives the points of every graded question whose answer equals the correct answer as json value,
+// so key order and formatting do not matter while the order inside arrays (sorting questions) does.
+func scoreQuiz(key []entity.QuestionAnswerKey, answers map[string]json.RawMessage) (score, maxScore float64) {
+	for _, question := range key {
+		if question.CorrectAnswer == "" {
+			continue
+		}
+
+		maxScore += question.Points
+
+		answer, ok := answers[strconv.Itoa(question.Id)]
+		if !ok {
+			continue
+		}
+
+		var want, got any
+		if err := json.Unmarshal([]byte(question.CorrectAnswer), &want); err != nil {
+			log.Warn().Err(err).Int("question_id", question.Id).Msg("service::scoreQuiz - Invalid correct answer")
+			continue
+		}
+		if err := json.Unmarshal(answer, &got); err != nil {
+			continue
+		}
+
+		if reflect.DeepEqual(want, got) {
+			score += question.Points
+		}
+	}
+
+	return score, maxScore
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+type GetStorageUsageRequest struct {
+	UserId string `validate:"required"`
+}
+
+type StorageUsageByKind struct {
+	Kind    string `json:"kind" db:"kind"`
+	Bytes   int64  `json:"bytes" db:"bytes"`
+	Objects int    `json:"objects" db:"objects"`
+}
+
+type GetStorageUsageResponse struct {
+	UsedBytes  int64                `json:"used_bytes"`
+	QuotaBytes int64                `json:"quota_bytes"` // 0 means unlimited
+	ByKind     []StorageUsageByKind `json:"by_kind"`
+}
+
+type CleanupStorageRequest struct {
+	DryRun bool
+	MinAge time.Duration // objects younger than this may belong to an upload that is not committed yet
+}
+
+type CleanupStorageResponse struct {
+	Scanned      int      `json:"scanned"`
+	Orphaned     int      `json:"orphaned"`
+	Deleted      int      `json:"deleted"`
+	DeletedBytes int64    `json:"deleted_bytes"`
+	Keys         []string `json:"keys"`
+	Failed       []string `json:"failed"`
+}
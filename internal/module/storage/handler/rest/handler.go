@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"fmt"
+	"hacko-app/internal/adapter"
+	integStorage "hacko-app/internal/integration/storage"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/storage/entity"
+	"hacko-app/internal/module/storage/ports"
+	"hacko-app/internal/module/storage/repository"
+	"hacko-app/internal/module/storage/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type storageHandler struct {
+	service ports.StorageService
+	storage integStorage.Storage
+}
+
+func NewStorageHandler() *storageHandler {
+	var handler = new(storageHandler)
+
+	repo := repository.NewStorageRepository(adapter.Adapters.HackoPostgres)
+	storage := integStorage.NewStorageIntegration()
+	storageService := service.NewStorageService(repo, storage)
+
+	handler.service = storageService
+	handler.storage = storage
+	return handler
+}
+
+func (h *storageHandler) Register(router fiber.Router) {
+	router.Get("/private/*", middleware.ValidateSignedURL, h.GetPrivateFile)
+	router.Get("/usage", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetStorageUsage)
+}
+
+func (h *storageHandler) GetStorageUsage(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetStorageUsageRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetStorageUsage - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetStorageUsage(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *storageHandler) GetPrivateFile(c *fiber.Ctx) error {
+	var (
+		ctx = c.Context()
+	)
+
+	name, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetPrivateFile - Failed to parse file path")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid file path"))))
+	}
+
+	body, object, err := h.storage.Get(ctx, integStorage.Key(integStorage.VisibilityPrivate, name))
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	disposition := "attachment"
+	if c.Query("download") == "" && isInlineContentType(contentType) {
+		disposition = "inline"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": path.Base(name)}))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set(fiber.HeaderLastModified, object.LastModified.Format(http.TimeFormat))
+
+	start, end, ok, err := parseRange(c.Get(fiber.HeaderRange), object.Size)
+	if err != nil {
+		body.Close()
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", object.Size))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(response.Error(errmsg.NewCustomErrors(416, errmsg.WithMessage("Requested range not satisfiable"))))
+	}
+
+	if !ok {
+		c.Set(fiber.HeaderContentLength, strconv.FormatInt(object.Size, 10))
+		if c.Method() == fiber.MethodHead {
+			body.Close()
+			return c.SendStatus(fiber.StatusOK)
+		}
+		// the stream is closed by fasthttp once the response is written
+		return c.Status(fiber.StatusOK).SendStream(body, int(object.Size))
+	}
+
+	if err := skip(body, start); err != nil {
+		body.Close()
+		log.Error().Err(err).Str("file", name).Msg("handler::GetPrivateFile - Failed to seek file")
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(errmsg.NewCustomErrors(500, errmsg.WithMessage("Failed to read file"))))
+	}
+
+	length := end - start + 1
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, object.Size))
+	c.Set(fiber.HeaderContentLength, strconv.FormatInt(length, 10))
+	if c.Method() == fiber.MethodHead {
+		body.Close()
+		return c.SendStatus(fiber.StatusPartialContent)
+	}
+
+	return c.Status(fiber.StatusPartialContent).SendStream(readCloser{io.LimitReader(body, length), body}, int(length))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// parseRange supports a single "bytes=start-end" range, ok is false when the whole file should be sent.
+func parseRange(header string, size int64) (start, end int64, ok bool, err error) {
+	if header == "" || !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		return 0, 0, false, nil
+	}
+
+	from, to, found := strings.Cut(strings.TrimPrefix(header, "bytes="), "-")
+	if !found {
+		return 0, 0, false, fmt.Errorf("invalid range %q", header)
+	}
+
+	switch {
+	case from == "": // suffix range, the last n bytes
+		n, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false, fmt.Errorf("invalid range %q", header)
+		}
+		if n > size {
+			n = size
+		}
+		start, end = size-n, size-1
+	default:
+		start, err = strconv.ParseInt(from, 10, 64)
+		if err != nil || start < 0 {
+			return 0, 0, false, fmt.Errorf("invalid range %q", header)
+		}
+
+		end = size - 1
+		if to != "" {
+			end, err = strconv.ParseInt(to, 10, 64)
+			if err != nil || end < start {
+				return 0, 0, false, fmt.Errorf("invalid range %q", header)
+			}
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+
+	if start >= size {
+		return 0, 0, false, fmt.Errorf("range %q out of bounds", header)
+	}
+
+	return start, end, true, nil
+}
+
+// skip moves the reader to offset, seeking when the backend supports it.
+func skip(r io.Reader, offset int64) error {
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r, offset)
+	return err
+}
+
+func isInlineContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/") ||
+		strings.HasPrefix(contentType, "video/") ||
+		strings.HasPrefix(contentType, "audio/") ||
+		strings.HasPrefix(contentType, "application/pdf") ||
+		strings.HasPrefix(contentType, "text/plain")
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/storage/entity"
+	"hacko-app/pkg/types"
+)
+
+type StorageRepository interface {
+	GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error)
+	GetStorageUsageByKind(ctx context.Context, userId string) ([]entity.StorageUsageByKind, error)
+	GetReferencedKeys(ctx context.Context) (map[string]bool, error)
+}
+
+type StorageService interface {
+	GetStorageUsage(ctx context.Context, req *entity.GetStorageUsageRequest) (*entity.GetStorageUsageResponse, error)
+	CleanupStorage(ctx context.Context, req *entity.CleanupStorageRequest) (*entity.CleanupStorageResponse, error)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"hacko-app/internal/infrastructure/config"
+	"hacko-app/internal/module/storage/entity"
+	"hacko-app/internal/module/storage/ports"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/types"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.StorageRepository = &storageRepository{}
+
+type storageRepository struct {
+	db *sqlx.DB
+}
+
+func NewStorageRepository(db *sqlx.DB) *storageRepository {
+	return &storageRepository{
+		db: db,
+	}
+}
+
+func (r *storageRepository) GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error) {
+	return GetStorageUsage(ctx, r.db, userId)
+}
+
+// GetStorageUsage returns the bytes stored by the user and the quota they fall under. It is the quota check of the
+// uploads of other modules, they pass their own database.
+func GetStorageUsage(ctx context.Context, db sqlx.QueryerContext, userId string) (*types.StorageUsage, error) {
+	var res = new(types.StorageUsage)
+
+	query := `
+		SELECT
+			storage_usage(u.id) AS used_bytes,
+			COALESCE(u.storage_quota_bytes, $1) AS quota_bytes
+		FROM users u
+		WHERE u.id = $2
+	`
+
+	err := sqlx.GetContext(ctx, db, res, query, config.Envs.HackoStorage.Quota, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Str("userId", userId).Msg("repo::GetStorageUsage - User not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+		}
+
+		log.Error().Err(err).Str("userId", userId).Msg("repo::GetStorageUsage - Failed to get storage usage")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *storageRepository) GetStorageUsageByKind(ctx context.Context, userId string) ([]entity.StorageUsageByKind, error) {
+	var res = make([]entity.StorageUsageByKind, 0)
+
+	query := `
+		SELECT
+			kind,
+			COALESCE(SUM(size), 0) AS bytes,
+			COUNT(*) AS objects
+		FROM storage_references
+		WHERE owner_id = ?
+		GROUP BY kind
+		ORDER BY kind
+	`
+
+	if err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), userId); err != nil {
+		log.Error().Err(err).Str("userId", userId).Msg("repo::GetStorageUsageByKind - Failed to get storage usage")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *storageRepository) GetReferencedKeys(ctx context.Context) (map[string]bool, error) {
+	var keys []string
+
+	query := `SELECT DISTINCT storage_key FROM storage_references WHERE storage_key IS NOT NULL`
+
+	if err := r.db.SelectContext(ctx, &keys, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetReferencedKeys - Failed to get referenced keys")
+		return nil, err
+	}
+
+	res := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		res[key] = true
+	}
+
+	return res, nil
+}
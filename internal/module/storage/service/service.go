@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	integStorage "hacko-app/internal/integration/storage"
+	"hacko-app/internal/module/storage/entity"
+	"hacko-app/internal/module/storage/ports"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.StorageService = &storageService{}
+
+type storageService struct {
+	repo    ports.StorageRepository
+	storage integStorage.Storage
+}
+
+func NewStorageService(repo ports.StorageRepository, storage integStorage.Storage) *storageService {
+	return &storageService{
+		repo:    repo,
+		storage: storage,
+	}
+}
+
+func (s *storageService) GetStorageUsage(ctx context.Context, req *entity.GetStorageUsageRequest) (*entity.GetStorageUsageResponse, error) {
+	usage, err := s.repo.GetStorageUsage(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	byKind, err := s.repo.GetStorageUsageByKind(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetStorageUsageResponse{
+		UsedBytes:  usage.UsedBytes,
+		QuotaBytes: max(usage.QuotaBytes, 0),
+		ByKind:     byKind,
+	}, nil
+}
+
+// CleanupStorage deletes objects that no database row references anymore.
+func (s *storageService) CleanupStorage(ctx context.Context, req *entity.CleanupStorageRequest) (*entity.CleanupStorageResponse, error) {
+	var res = &entity.CleanupStorageResponse{
+		Keys:   []string{},
+		Failed: []string{},
+	}
+
+	// list the objects before reading the references, an upload that commits in between is then never seen as orphan
+	objects, err := s.storage.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := s.repo.GetReferencedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-req.MinAge)
+	for _, object := range objects {
+		res.Scanned++
+
+		if referenced[object.Key] || object.LastModified.After(cutoff) {
+			continue
+		}
+
+		res.Orphaned++
+		res.Keys = append(res.Keys, object.Key)
+
+		if req.DryRun {
+			continue
+		}
+
+		if err := s.storage.Delete(ctx, object.Key); err != nil {
+			log.Warn().Err(err).Str("key", object.Key).Msg("service::CleanupStorage - Failed to delete orphaned object")
+			res.Failed = append(res.Failed, object.Key)
+			continue
+		}
+
+		res.Deleted++
+		res.DeletedBytes += object.Size
+	}
+
+	return res, nil
+}
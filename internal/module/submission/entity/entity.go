@@ -1,20 +1,121 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"hacko-app/pkg"
+	"hacko-app/pkg/types"
+	"mime/multipart"
+	"time"
+)
 
 type SubmitRequest struct {
-	UserId       string `validate:"required"`
-	AssignmentId string `json:"assignment_id" validate:"required"`
-	Link         string `json:"link" validate:"required"`
-	DueDate      string `json:"due_date"`
+	UserId         string     `validate:"required"`
+	AssignmentId   string     `json:"assignment_id" validate:"required"`
+	Link           string     `json:"link" form:"link"`
+	Answer         string     `json:"answer" form:"answer" validate:"max=100000"`
+	Files          FileReader `json:"-" form:"-"` // nil when the submission has no files
+	LateDays       int        `json:"-" form:"-"`
+	PenaltyPercent float64    `json:"-" form:"-"`
+	TeamId         *int       `json:"-" form:"-"`
+	MemberIds      []string   `json:"-" form:"-"`
+	Autograde      bool       `json:"-" form:"-"`
+}
+
+// FileReader yields the uploaded files of a request as they arrive, and io.EOF after the last one. A file is only
+// valid until the next one is read.
+type FileReader interface {
+	NextFile() (*multipart.Part, error)
 }
 
 type SubmitResponse struct {
-	Id          int       `json:"id" db:"id"`
-	UserId      string    `json:"creator_assignment_id" db:"creator_assignment_id"`
-	Link        string    `json:"link" db:"link"`
-	Status      string    `json:"status" db:"status"`
-	SubmittedAt time.Time `json:"submitted_at" db:"submitted_at"`
+	Id              int              `json:"id" db:"id"`
+	UserId          string           `json:"creator_assignment_id" db:"creator_assignment_id"`
+	Link            string           `json:"link" db:"link"`
+	Answer          string           `json:"answer" db:"answer"`
+	Status          string           `json:"status" db:"status"`
+	Attempt         int              `json:"attempt" db:"attempt"`
+	LateDays        int              `json:"late_days" db:"late_days"`
+	PenaltyPercent  float64          `json:"penalty_percent" db:"penalty_percent"`
+	SubmittedAt     time.Time        `json:"submitted_at" db:"submitted_at"`
+	TeamId          *int             `json:"team_id" db:"team_id"`
+	AutogradeStatus *string          `json:"autograde_status" db:"autograde_status"`
+	Files           []SubmissionFile `json:"files"`
+}
+
+type SubmissionFile struct {
+	Id           int    `json:"id" db:"id"`
+	SubmissionId int    `json:"submission_id" db:"submission_id"`
+	Filename     string `json:"filename" db:"filename"`
+	Size         int64  `json:"size" db:"size"`
+	MimeType     string `json:"mime_type" db:"mime_type"`
+	StorageKey   string `json:"-" db:"storage_key"`
+	Url          string `json:"url"`
+	CreatedAt    string `json:"created_at" db:"created_at"`
+}
+
+type AssignmentSubmissionRules struct {
+	Id                 int        `db:"id"`
+	AllowedFileTypes   []string   `db:"allowed_file_types"`
+	MaxFiles           int        `db:"max_files"`
+	DueDate            time.Time  `db:"due_date"`
+	AllowLate          bool       `db:"allow_late"`
+	LatePenaltyPercent float64    `db:"late_penalty_percent"`
+	LateCutoff         *time.Time `db:"late_cutoff"`
+	ResubmissionPolicy string     `db:"resubmission_policy"`
+	IsGroup            bool       `db:"is_group"`
+	AutogradeLanguage  *string    `db:"autograde_language"`
+}
+
+// SubmissionTeam is the team a student hands a group assignment in with.
+type SubmissionTeam struct {
+	Id        int
+	MemberIds []string
+}
+
+// SubmissionMember is a member of a team submission with the grade that counts for them,
+// the team grade unless the teacher overrode it.
+type SubmissionMember struct {
+	StudentId  string   `json:"student_id" db:"student_id"`
+	Name       string   `json:"name" db:"name"`
+	Grade      *float64 `json:"grade" db:"grade"`
+	Feedback   *string  `json:"feedback" db:"feedback"`
+	Overridden bool     `json:"overridden" db:"overridden"`
+}
+
+type OverrideMemberGradeRequest struct {
+	UserId       string   `validate:"required"`
+	SubmissionId int      `json:"submission_id" validate:"required"`
+	StudentId    string   `json:"student_id" validate:"required,uuid"`
+	Grade        *float64 `json:"grade" validate:"omitempty,min=0"`
+	Feedback     *string  `json:"feedback"`
+}
+
+// ActiveSubmission is the attempt of a student that currently counts for grading.
+type ActiveSubmission struct {
+	Id      int    `db:"id"`
+	Attempt int    `db:"attempt"`
+	Status  string `db:"status"`
+}
+
+type GetSubmissionHistoryRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+type SubmissionAttempt struct {
+	Id             int              `json:"id" db:"id"`
+	Attempt        int              `json:"attempt" db:"attempt"`
+	IsActive       bool             `json:"is_active" db:"is_active"`
+	Link           string           `json:"link" db:"link"`
+	Answer         string           `json:"answer" db:"answer"`
+	Status         string           `json:"status" db:"status"`
+	Grade          *float64         `json:"grade" db:"grade"`
+	Feedback       *string          `json:"feedback" db:"feedback"`
+	LateDays       int              `json:"late_days" db:"late_days"`
+	PenaltyPercent float64          `json:"penalty_percent" db:"penalty_percent"`
+	SubmittedAt    time.Time        `json:"submitted_at" db:"submitted_at"`
+	GradedAt       *time.Time       `json:"graded_at" db:"graded_at"`
+	Files          []SubmissionFile `json:"files"`
 }
 
 type GetSubmissionDetailsRequest struct {
@@ -23,30 +124,388 @@ type GetSubmissionDetailsRequest struct {
 }
 
 type GetSubmissionDetailsResponse struct {
-	Id           int        `json:"id" db:"id"`
-	SubmissionId string     `json:"submission_id" db:"submission_id"`
-	Name         string     `json:"name" db:"name"`
-	Image        *string    `json:"image_url" db:"image_url"`
-	Link         string     `json:"link" db:"link"`
-	Status       string     `json:"status" db:"status"`
-	Grade        *string    `json:"grade" db:"grade"`
-	Feedback     *string    `json:"feedback" db:"feedback"`
-	SubmittedAt  time.Time  `json:"submitted_at" db:"submitted_at"`
-	GradedAt     *time.Time `json:"graded_at" db:"graded_at"`
+	Id           int                `json:"id" db:"id"`
+	SubmissionId string             `json:"submission_id" db:"submission_id"`
+	Name         string             `json:"name" db:"name"`
+	Image        *string            `json:"image_url" db:"image_url"`
+	Link         string             `json:"link" db:"link"`
+	Answer       string             `json:"answer" db:"answer"`
+	Status       string             `json:"status" db:"status"`
+	Attempt      int                `json:"attempt" db:"attempt"`
+	IsActive     bool               `json:"is_active" db:"is_active"`
+	LateDays     int                `json:"late_days" db:"late_days"`
+	Grade        *float64           `json:"grade" db:"grade"`
+	MaxScore     float64            `json:"max_score" db:"max_score"`
+	Feedback     *string            `json:"feedback" db:"feedback"`
+	SubmittedAt  time.Time          `json:"submitted_at" db:"submitted_at"`
+	GradedAt     *time.Time         `json:"graded_at" db:"graded_at"`
+	Files        []SubmissionFile   `json:"files"`
+	Scores       []RubricScore      `json:"scores"`
+	Members      []SubmissionMember `json:"members"`
+	Autograde    *Autograde         `json:"autograde"`
 }
 
+type RubricScoreRequest struct {
+	CriterionId int    `json:"criterion_id" validate:"required"`
+	LevelId     int    `json:"level_id" validate:"required"`
+	Comment     string `json:"comment"`
+}
+
+// GradingSubmissionRequest grades with a level per rubric criterion, Grade is only used when the assignment has no rubric.
 type GradingSubmissionRequest struct {
-	UserId       string `validate:"required"`
-	SubmissionId string `json:"submission_id" validate:"required"`
-	Grade        string `json:"grade" validate:"required"`
-	Feedback     string `json:"feedback" validate:"required"`
-	Status       string `json:"status" validate:"required"`
+	UserId       string               `validate:"required"`
+	SubmissionId string               `json:"submission_id" validate:"required"`
+	Scores       []RubricScoreRequest `json:"scores" validate:"dive"`
+	Grade        *float64             `json:"grade" validate:"omitempty,min=0"`
+	Feedback     string               `json:"feedback"`
+	RawGrade     float64              `json:"-"`
+	PeerScore    *float64             `json:"-"`
 }
 
 type GradingSubmissionResponse struct {
-	Id           int        `json:"id" db:"id"`
-	Grade        string     `json:"grade" validate:"required"`
-	Feedback     string     `json:"feedback" validate:"required"`
-	Status       string     `json:"status" validate:"required"`
-	GradedAt     time.Time `json:"graded_at" db:"graded_at"`
+	Id             int                `json:"id" db:"id"`
+	TeacherScore   float64            `json:"teacher_score"`
+	PeerScore      *float64           `json:"peer_score" db:"peer_score"`
+	RawGrade       float64            `json:"raw_grade" db:"raw_grade"`
+	PenaltyPercent float64            `json:"penalty_percent" db:"penalty_percent"`
+	Grade          float64            `json:"grade" db:"grade"`
+	MaxScore       float64            `json:"max_score" db:"max_score"`
+	Feedback       string             `json:"feedback" db:"feedback"`
+	Status         string             `json:"status" db:"status"`
+	GradedAt       time.Time          `json:"graded_at" db:"graded_at"`
+	Scores         []RubricScore      `json:"scores"`
+	Members        []SubmissionMember `json:"members"`
+}
+
+// GradingRubric is the rubric of the assignment a submission belongs to, flattened to its levels.
+type GradingRubric struct {
+	MaxScore         float64
+	PeerReviewWeight float64
+	PeerScore        *float64
+	Levels           []GradingRubricLevel
+}
+
+type GradingRubricLevel struct {
+	Id          int     `db:"id"`
+	CriterionId int     `db:"criterion_id"`
+	Points      float64 `db:"points"`
+}
+
+type RubricScore struct {
+	CriterionId    int     `json:"criterion_id" db:"criterion_id"`
+	CriterionTitle string  `json:"criterion_title,omitempty" db:"criterion_title"`
+	LevelId        int     `json:"level_id" db:"level_id"`
+	LevelTitle     string  `json:"level_title,omitempty" db:"level_title"`
+	Points         float64 `json:"points" db:"points"`
+	Comment        string  `json:"comment" db:"comment"`
+}
+
+// PeerReviewSettings is the part of an assignment that decides when and how peer reviews are handed out.
+type PeerReviewSettings struct {
+	AssignmentId     int        `db:"id"`
+	CreatorId        string     `db:"creator_assignment_id"`
+	ClassId          int        `db:"class_id"`
+	DueDate          time.Time  `db:"due_date"`
+	AllowLate        bool       `db:"allow_late"`
+	LateCutoff       *time.Time `db:"late_cutoff"`
+	PeerReviewCount  int        `db:"peer_review_count"`
+	PeerReviewWeight float64    `db:"peer_review_weight"`
+	AssignedAt       *time.Time `db:"peer_reviews_assigned_at"`
+}
+
+type PeerReviewAuthor struct {
+	SubmissionId int    `db:"id"`
+	StudentId    string `db:"student_id"`
+}
+
+type PeerReviewPair struct {
+	SubmissionId int
+	ReviewerId   string
+}
+
+type AssignPeerReviewsRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+type AssignPeerReviewsResponse struct {
+	AssignmentId int       `json:"assignment_id"`
+	Submissions  int       `json:"submissions"`
+	Reviews      int       `json:"reviews"`
+	AssignedAt   time.Time `json:"assigned_at"`
+}
+
+type GetPeerReviewTasksRequest struct {
+	UserId       string `validate:"required"`
+	AssignmentId int    `json:"assignment_id" validate:"required"`
+}
+
+// PeerReviewTask is a submission handed to a reviewer, it never carries the author.
+type PeerReviewTask struct {
+	Id           int              `json:"id" db:"id"`
+	SubmissionId int              `json:"submission_id" db:"submission_id"`
+	Status       string           `json:"status" db:"status"`
+	Link         string           `json:"link" db:"link"`
+	Answer       string           `json:"answer" db:"answer"`
+	Total        *float64         `json:"total" db:"total"`
+	Feedback     *string          `json:"feedback" db:"feedback"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+	CompletedAt  *time.Time       `json:"completed_at" db:"completed_at"`
+	Files        []SubmissionFile `json:"files"`
+	Scores       []RubricScore    `json:"scores"`
+}
+
+type PeerReviewRef struct {
+	Id           int    `db:"id"`
+	SubmissionId int    `db:"submission_id"`
+	ReviewerId   string `db:"reviewer_id"`
+	Status       string `db:"status"`
+}
+
+type SubmitPeerReviewRequest struct {
+	UserId       string               `validate:"required"`
+	PeerReviewId int                  `json:"peer_review_id" validate:"required"`
+	Scores       []RubricScoreRequest `json:"scores" validate:"dive"`
+	Grade        *float64             `json:"grade" validate:"omitempty,min=0"`
+	Feedback     string               `json:"feedback"`
+	Total        float64              `json:"-"`
+}
+
+type SubmitPeerReviewResponse struct {
+	Id          int           `json:"id" db:"id"`
+	Status      string        `json:"status" db:"status"`
+	Total       float64       `json:"total" db:"total"`
+	Feedback    string        `json:"feedback" db:"feedback"`
+	CompletedAt time.Time     `json:"completed_at" db:"completed_at"`
+	Scores      []RubricScore `json:"scores"`
+}
+
+type GetSubmissionPeerReviewsRequest struct {
+	UserId       string `validate:"required"`
+	SubmissionId int    `json:"submission_id" validate:"required"`
+}
+
+// SubmissionPeerReview is a review as the author and the teacher see it, the reviewer is only numbered.
+type SubmissionPeerReview struct {
+	Id          int           `json:"-" db:"id"`
+	Reviewer    string        `json:"reviewer"`
+	Status      string        `json:"status" db:"status"`
+	Total       *float64      `json:"total" db:"total"`
+	Feedback    *string       `json:"feedback" db:"feedback"`
+	CompletedAt *time.Time    `json:"completed_at" db:"completed_at"`
+	Scores      []RubricScore `json:"scores"`
+}
+
+type GetSubmissionPeerReviewsResponse struct {
+	SubmissionId int                    `json:"submission_id"`
+	PeerScore    *float64               `json:"peer_score"`
+	Reviews      []SubmissionPeerReview `json:"reviews"`
+}
+
+// GetInboxRequest filters the active submissions of every class the teacher created.
+type GetInboxRequest struct {
+	UserId       string `validate:"required"`
+	ClassId      int    `query:"class_id" validate:"omitempty,min=1"`
+	AssignmentId int    `query:"assignment_id" validate:"omitempty,min=1"`
+	Status       string `query:"status" validate:"omitempty,oneof=submitted rated"`
+	LateOnly     bool   `query:"late_only"`
+	Sort         string `query:"sort" validate:"omitempty,oneof=submitted_at due_date student assignment"`
+	Order        string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Paginate     int    `query:"paginate" validate:"omitempty,min=1,max=100"`
+}
+
+type InboxItem struct {
+	SubmissionId    int        `json:"submission_id" db:"submission_id"`
+	ClassId         int        `json:"class_id" db:"class_id"`
+	ClassTitle      string     `json:"class_title" db:"class_title"`
+	AssignmentId    int        `json:"assignment_id" db:"assignment_id"`
+	AssignmentTitle string     `json:"assignment_title" db:"assignment_title"`
+	DueDate         time.Time  `json:"due_date" db:"due_date"`
+	StudentId       string     `json:"student_id" db:"student_id"`
+	StudentName     string     `json:"student_name" db:"student_name"`
+	TeamName        *string    `json:"team_name" db:"team_name"`
+	Status          string     `json:"status" db:"status"`
+	Attempt         int        `json:"attempt" db:"attempt"`
+	LateDays        int        `json:"late_days" db:"late_days"`
+	Grade           *float64   `json:"grade" db:"grade"`
+	MaxScore        float64    `json:"max_score" db:"max_score"`
+	SubmittedAt     time.Time  `json:"submitted_at" db:"submitted_at"`
+	GradedAt        *time.Time `json:"graded_at" db:"graded_at"`
+}
+
+type GetInboxResponse struct {
+	Items []InboxItem `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
+
+type BulkGradingRequest struct {
+	UserId string                     `validate:"required"`
+	Items  []GradingSubmissionRequest `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// BulkGradingResult is the outcome of one item, a failed item leaves the submission as it was.
+type BulkGradingResult struct {
+	SubmissionId string                     `json:"submission_id"`
+	Success      bool                       `json:"success"`
+	Message      string                     `json:"message,omitempty"`
+	Errors       map[string][]string        `json:"errors,omitempty"`
+	Result       *GradingSubmissionResponse `json:"result,omitempty"`
+}
+
+type BulkGradingResponse struct {
+	Graded  int                 `json:"graded"`
+	Failed  int                 `json:"failed"`
+	Results []BulkGradingResult `json:"results"`
+}
+
+// BulkGradingItem is a graded item ready to be stored.
+type BulkGradingItem struct {
+	Request *GradingSubmissionRequest
+	Scores  []RubricScore
+}
+
+// SimilarityDocument is the text of a submission that is compared with the other submissions of its assignment.
+type SimilarityDocument struct {
+	SubmissionId     int
+	Content          string
+	Fingerprints     []pkg.Fingerprint
+	FingerprintCount int
+}
+
+// SimilarityCandidate is another active submission of the assignment sharing fingerprints with a checked one.
+type SimilarityCandidate struct {
+	SubmissionId     int `db:"submission_id"`
+	FingerprintCount int `db:"fingerprint_count"`
+	Shared           int `db:"shared"`
+}
+
+// SimilarityMatch is a suspicious pair, SubmissionA is the lower id and the spans are oriented the same way.
+type SimilarityMatch struct {
+	SubmissionA        int
+	SubmissionB        int
+	Score              float64
+	SharedFingerprints int
+	Spans              []pkg.SimilaritySpan
+}
+
+type GetSimilarityReportRequest struct {
+	UserId       string  `validate:"required"`
+	AssignmentId int     `validate:"required"`
+	MinScore     float64 `query:"min_score" validate:"omitempty,min=0,max=1"`
+	Limit        int     `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+type SimilarityPairRow struct {
+	SubmissionA        int             `db:"submission_a"`
+	SubmissionB        int             `db:"submission_b"`
+	Score              float64         `db:"score"`
+	SharedFingerprints int             `db:"shared_fingerprints"`
+	Spans              json.RawMessage `db:"spans"`
+	CreatedAt          time.Time       `db:"created_at"`
+	StudentA           string          `db:"student_a"`
+	NameA              string          `db:"name_a"`
+	TeamA              *string         `db:"team_a"`
+	ContentA           string          `db:"content_a"`
+	StudentB           string          `db:"student_b"`
+	NameB              string          `db:"name_b"`
+	TeamB              *string         `db:"team_b"`
+	ContentB           string          `db:"content_b"`
+}
+
+type SimilaritySubmission struct {
+	SubmissionId int     `json:"submission_id"`
+	StudentId    string  `json:"student_id"`
+	StudentName  string  `json:"student_name"`
+	TeamName     *string `json:"team_name"`
+}
+
+type SimilarityExcerpt struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+type SimilaritySpan struct {
+	A SimilarityExcerpt `json:"a"`
+	B SimilarityExcerpt `json:"b"`
+}
+
+type SimilarityPair struct {
+	A                  SimilaritySubmission `json:"a"`
+	B                  SimilaritySubmission `json:"b"`
+	Score              float64              `json:"score"`
+	SharedFingerprints int                  `json:"shared_fingerprints"`
+	Spans              []SimilaritySpan     `json:"spans"`
+	CheckedAt          time.Time            `json:"checked_at"`
+}
+
+type GetSimilarityReportResponse struct {
+	Checked int              `json:"checked"`
+	Pairs   []SimilarityPair `json:"pairs"`
+}
+
+// AutogradeSuite is what a submission is run against, the settings of its assignment and every test case.
+type AutogradeSuite struct {
+	Language    string `db:"autograde_language"`
+	TimeLimitMs int    `db:"autograde_time_limit_ms"`
+	MemoryMb    int    `db:"autograde_memory_mb"`
+	Tests       []AutogradeTest
+}
+
+type AutogradeTest struct {
+	Id             int     `db:"id"`
+	Input          string  `db:"input"`
+	ExpectedOutput string  `db:"expected_output"`
+	Points         float64 `db:"points"`
+}
+
+// AutogradeResult is the outcome of an automated grading run, Tests is empty when the code did not compile
+// or could not be run at all.
+type AutogradeResult struct {
+	SubmissionId int
+	Status       string
+	Score        *float64
+	Output       string
+	Tests        []AutogradeTestResult
+}
+
+// AutogradeGrade is the grade an automated grading gave to a submission the teacher had not graded yet.
+type AutogradeGrade struct {
+	Graded   bool    `db:"graded"`
+	Grade    float64 `db:"grade"`
+	MaxScore float64 `db:"max_score"`
+}
+
+type AutogradeTestResult struct {
+	TestCaseId int
+	Passed     bool
+	Points     float64
+	Output     string
+	ExitCode   int
+	TimedOut   bool
+	DurationMs int
+}
+
+// Autograde is the automated grading of a submission as shown in its details, the output of hidden tests is left out.
+type Autograde struct {
+	Status    string           `json:"status" db:"autograde_status"`
+	Score     *float64         `json:"score" db:"autograde_score"`
+	MaxPoints float64          `json:"max_points" db:"max_points"`
+	Output    string           `json:"output" db:"autograde_output"`
+	GradedAt  *time.Time       `json:"graded_at" db:"autograded_at"`
+	Tests     []AutogradeCheck `json:"tests"`
+}
+
+type AutogradeCheck struct {
+	TestCaseId int     `json:"test_case_id" db:"test_case_id"`
+	Name       string  `json:"name" db:"name"`
+	IsHidden   bool    `json:"is_hidden" db:"is_hidden"`
+	Passed     bool    `json:"passed" db:"passed"`
+	Points     float64 `json:"points" db:"points"`
+	MaxPoints  float64 `json:"max_points" db:"max_points"`
+	Output     *string `json:"output" db:"output"`
+	ExitCode   int     `json:"exit_code" db:"exit_code"`
+	TimedOut   bool    `json:"timed_out" db:"timed_out"`
+	DurationMs int     `json:"duration_ms" db:"duration_ms"`
 }
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"hacko-app/internal/adapter"
+	integSandbox "hacko-app/internal/integration/sandbox"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/middleware"
 	"hacko-app/internal/module/submission/entity"
 	"hacko-app/internal/module/submission/ports"
@@ -9,7 +11,7 @@ import (
 	"hacko-app/internal/module/submission/service"
 	"hacko-app/pkg/errmsg"
 	"hacko-app/pkg/response"
-	// "strconv"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -23,7 +25,9 @@ func NewSubmissionHandler() *submissionHandler {
 	var handler = new(submissionHandler)
 
 	repo := repository.NewSubmissionRepository(adapter.Adapters.HackoPostgres)
-	submissionService := service.NewSubmissionService(repo)
+	storage := integStorage.NewStorageIntegration()
+	sandbox := integSandbox.NewSandboxIntegration()
+	submissionService := service.NewSubmissionService(repo, storage, sandbox)
 
 	handler.service = submissionService
 	return handler
@@ -33,11 +37,20 @@ func NewSubmissionHandler() *submissionHandler {
 
 func (h *submissionHandler) Register(router fiber.Router) {
 	// user routes
-	router.Post("/class/assignment/:assignmentId/submission", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.SubmitAssignment)
+	router.Post("/class/assignment/:assignmentId/submission", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.SubmitAssignment).Name(middleware.UploadRoute(integStorage.CategorySubmission))
+	router.Get("/class/assignment/:assignmentId/submission/history", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetSubmissionHistory)
+	router.Get("/class/assignment/:assignmentId/peer-reviews", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetPeerReviewTasks)
+	router.Post("/class/assignment/peer-reviews/:peerReviewId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.SubmitPeerReview)
+	router.Get("/class/assignment/submission/:submissionId/peer-reviews", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetSubmissionPeerReviews)
 
 	// admin routes
 	router.Get("/class/assignment/submission/:submissionId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetSubmissionDetails)
 	router.Post("/class/assignment/submission/:submissionId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GradingSubmission)
+	router.Put("/class/assignment/submission/:submissionId/members/:studentId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.OverrideMemberGrade)
+	router.Post("/class/assignment/:assignmentId/peer-reviews/assign", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.AssignPeerReviews)
+	router.Get("/teacher/submissions", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetInbox)
+	router.Post("/teacher/submissions/grade", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.BulkGradingSubmission)
+	router.Get("/teacher/assignment/:assignmentId/similarity", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetSimilarityReport)
 }
 
 func (h *submissionHandler) SubmitAssignment(c *fiber.Ctx) error {
@@ -54,7 +67,19 @@ func (h *submissionHandler) SubmitAssignment(c *fiber.Ctx) error {
 
 	req.AssignmentId = id
 
-	if err := c.BodyParser(req); err != nil {
+	// files are optional, a link or an answer alone is a submission too and may come without a multipart body
+	if len(c.Request().Header.MultipartFormBoundary()) > 0 {
+		form, err := middleware.ReadMultipartForm(c, "files")
+		if err != nil {
+			log.Warn().Err(err).Msg("handler::CreateAssignment - Failed to read multipart form")
+			code, errs := errmsg.Errors[error](err)
+			return c.Status(code).JSON(response.Error(errs))
+		}
+
+		req.Link = form.Value["link"]
+		req.Answer = form.Value["answer"]
+		req.Files = form
+	} else if err := c.BodyParser(req); err != nil {
 		log.Warn().Err(err).Msg("handler::CreateAssignment - Failed to parse request body")
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
 	}
@@ -74,6 +99,39 @@ func (h *submissionHandler) SubmitAssignment(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
 }
 
+func (h *submissionHandler) GetSubmissionHistory(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetSubmissionHistoryRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	assignmentId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetSubmissionHistory - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = assignmentId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetSubmissionHistory - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetSubmissionHistory(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
 func (h *submissionHandler) GetSubmissionDetails(c *fiber.Ctx) error {
 	var (
 		req = new(entity.GetSubmissionDetailsRequest)
@@ -136,3 +194,280 @@ func (h *submissionHandler) GradingSubmission(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
 }
+
+func (h *submissionHandler) GetPeerReviewTasks(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetPeerReviewTasksRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	assignmentId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetPeerReviewTasks - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = assignmentId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetPeerReviewTasks - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetPeerReviewTasks(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) SubmitPeerReview(c *fiber.Ctx) error {
+	var (
+		req = new(entity.SubmitPeerReviewRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::SubmitPeerReview - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	peerReviewId, err := strconv.Atoi(c.Params("peerReviewId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::SubmitPeerReview - Failed to parsing id peer review")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id peer review"))))
+	}
+
+	req.PeerReviewId = peerReviewId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::SubmitPeerReview - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.SubmitPeerReview(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) GetSubmissionPeerReviews(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetSubmissionPeerReviewsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	submissionId, err := strconv.Atoi(c.Params("submissionId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetSubmissionPeerReviews - Failed to parsing id submission")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id submission"))))
+	}
+
+	req.SubmissionId = submissionId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetSubmissionPeerReviews - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetSubmissionPeerReviews(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) AssignPeerReviews(c *fiber.Ctx) error {
+	var (
+		req = new(entity.AssignPeerReviewsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	assignmentId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::AssignPeerReviews - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = assignmentId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::AssignPeerReviews - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.AssignPeerReviews(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) OverrideMemberGrade(c *fiber.Ctx) error {
+	var (
+		req = new(entity.OverrideMemberGradeRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::OverrideMemberGrade - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+	req.StudentId = c.Params("studentId")
+
+	submissionId, err := strconv.Atoi(c.Params("submissionId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::OverrideMemberGrade - Failed to parsing id submission")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id submission"))))
+	}
+
+	req.SubmissionId = submissionId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::OverrideMemberGrade - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.OverrideMemberGrade(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) GetInbox(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetInboxRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetInbox - Failed to parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetInbox - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetInbox(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) BulkGradingSubmission(c *fiber.Ctx) error {
+	var (
+		req = new(entity.BulkGradingRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::BulkGradingSubmission - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+	for i := range req.Items {
+		req.Items[i].UserId = req.UserId
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::BulkGradingSubmission - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.BulkGradingSubmission(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *submissionHandler) GetSimilarityReport(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetSimilarityReportRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetSimilarityReport - Failed to parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+
+	assignmentId, err := strconv.Atoi(c.Params("assignmentId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetSimilarityReport - Failed to parsing id assignment")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id assignment"))))
+	}
+
+	req.AssignmentId = assignmentId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetSimilarityReport - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetSimilarityReport(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
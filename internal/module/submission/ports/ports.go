@@ -3,25 +3,67 @@ package ports
 import (
 	"context"
 	"hacko-app/internal/module/submission/entity"
+	"hacko-app/pkg"
+	"hacko-app/pkg/types"
+	"time"
 )
 
 type SubmissionRepository interface {
 	// utils contract
-	FindAssignment(ctx context.Context, assignmentId string) error
+	FindAssignment(ctx context.Context, assignmentId string, userId string) (*entity.AssignmentSubmissionRules, error)
+	GetSubmissionFiles(ctx context.Context, submissionId int) ([]entity.SubmissionFile, error)
+	GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error)
+	GetActiveSubmission(ctx context.Context, assignmentId string, userId string, teamId *int) (*entity.ActiveSubmission, error)
+	FindTeam(ctx context.Context, assignmentId string, userId string) (*entity.SubmissionTeam, error)
+	GetSubmissionMembers(ctx context.Context, submissionId int) ([]entity.SubmissionMember, error)
+	GetGradingRubric(ctx context.Context, submissionId string) (*entity.GradingRubric, error)
+	GetRubricScores(ctx context.Context, submissionId int) ([]entity.RubricScore, error)
+	GetPeerReviewSettings(ctx context.Context, assignmentId int) (*entity.PeerReviewSettings, error)
+	GetPeerReviewAuthors(ctx context.Context, assignmentId int) ([]entity.PeerReviewAuthor, error)
+	GetPeerReviewers(ctx context.Context, classId int) ([]string, error)
+	GetPeerReview(ctx context.Context, peerReviewId int) (*entity.PeerReviewRef, error)
+	GetPeerReviewScores(ctx context.Context, peerReviewId int) ([]entity.RubricScore, error)
+	GetSubmissionOwners(ctx context.Context, submissionId int) (string, string, error)
+	GetAutogradeSuite(ctx context.Context, submissionId int) (*entity.AutogradeSuite, error)
+	SaveAutogradeResult(ctx context.Context, res *entity.AutogradeResult) (*entity.AutogradeGrade, error)
+	GetAutograde(ctx context.Context, submissionId int) (*entity.Autograde, error)
 
 	// users contract
-	SubmitAssignment(ctx context.Context, req *entity.SubmitRequest) (*entity.SubmitResponse, error)
+	SubmitAssignment(ctx context.Context, req *entity.SubmitRequest, files []entity.SubmissionFile) (*entity.SubmitResponse, error)
+	GetSubmissionHistory(ctx context.Context, req *entity.GetSubmissionHistoryRequest) ([]entity.SubmissionAttempt, error)
+	GetPeerReviewTasks(ctx context.Context, req *entity.GetPeerReviewTasksRequest) ([]entity.PeerReviewTask, error)
+	CompletePeerReview(ctx context.Context, req *entity.SubmitPeerReviewRequest, scores []entity.RubricScore) (*entity.SubmitPeerReviewResponse, error)
+	GetSubmissionPeerReviews(ctx context.Context, submissionId int) ([]entity.SubmissionPeerReview, error)
 
 	// admin contract
 	GetSubmissionDetails(ctx context.Context, req *entity.GetSubmissionDetailsRequest) (*entity.GetSubmissionDetailsResponse, error)
-	GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest) (*entity.GradingSubmissionResponse, error)
+	GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest, scores []entity.RubricScore) (*entity.GradingSubmissionResponse, error)
+	CreatePeerReviews(ctx context.Context, assignmentId int, pairs []entity.PeerReviewPair) (time.Time, error)
+	OverrideMemberGrade(ctx context.Context, req *entity.OverrideMemberGradeRequest) error
+	BulkGradingSubmission(ctx context.Context, items []entity.BulkGradingItem) ([]entity.BulkGradingResult, error)
+	GetInbox(ctx context.Context, req *entity.GetInboxRequest) ([]entity.InboxItem, int, error)
+	GetAssignmentCreator(ctx context.Context, assignmentId int) (string, error)
+	SaveSimilarityDocument(ctx context.Context, doc *entity.SimilarityDocument) error
+	GetSimilarityCandidates(ctx context.Context, submissionId int) ([]entity.SimilarityCandidate, error)
+	GetFingerprints(ctx context.Context, submissionId int) ([]pkg.Fingerprint, error)
+	SaveSimilarityMatches(ctx context.Context, submissionId int, matches []entity.SimilarityMatch) error
+	GetSimilarityReport(ctx context.Context, req *entity.GetSimilarityReportRequest) ([]entity.SimilarityPairRow, int, error)
 }
 
 type SubmissionService interface {
 	// user contract
 	SubmitAssignment(ctx context.Context, req *entity.SubmitRequest) (*entity.SubmitResponse, error)
+	GetSubmissionHistory(ctx context.Context, req *entity.GetSubmissionHistoryRequest) ([]entity.SubmissionAttempt, error)
+	GetPeerReviewTasks(ctx context.Context, req *entity.GetPeerReviewTasksRequest) ([]entity.PeerReviewTask, error)
+	SubmitPeerReview(ctx context.Context, req *entity.SubmitPeerReviewRequest) (*entity.SubmitPeerReviewResponse, error)
+	GetSubmissionPeerReviews(ctx context.Context, req *entity.GetSubmissionPeerReviewsRequest) (*entity.GetSubmissionPeerReviewsResponse, error)
 
 	// admin contract
 	GetSubmissionDetails(ctx context.Context, req *entity.GetSubmissionDetailsRequest) (*entity.GetSubmissionDetailsResponse, error)
 	GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest) (*entity.GradingSubmissionResponse, error)
+	AssignPeerReviews(ctx context.Context, req *entity.AssignPeerReviewsRequest) (*entity.AssignPeerReviewsResponse, error)
+	OverrideMemberGrade(ctx context.Context, req *entity.OverrideMemberGradeRequest) (*entity.SubmissionMember, error)
+	BulkGradingSubmission(ctx context.Context, req *entity.BulkGradingRequest) (*entity.BulkGradingResponse, error)
+	GetInbox(ctx context.Context, req *entity.GetInboxRequest) (*entity.GetInboxResponse, error)
+	GetSimilarityReport(ctx context.Context, req *entity.GetSimilarityReportRequest) (*entity.GetSimilarityReportResponse, error)
 }
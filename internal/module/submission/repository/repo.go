@@ -3,12 +3,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"hacko-app/internal/module/submission/entity"
 	"hacko-app/internal/module/submission/ports"
+	storageRepo "hacko-app/internal/module/storage/repository"
+	"hacko-app/pkg"
 	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/types"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,48 +30,168 @@ func NewSubmissionRepository(db *sqlx.DB) *submissionRepository {
 	}
 }
 
-func (r *submissionRepository) FindAssignment(ctx context.Context, assignmentId string) error {
+// FindAssignment returns the submission rules of the assignment with the deadline of the student, extensions included.
+func (r *submissionRepository) FindAssignment(ctx context.Context, assignmentId string, userId string) (*entity.AssignmentSubmissionRules, error) {
     query := `
-        SELECT id 
-        FROM assignments 
-        WHERE id = $1
+        SELECT a.id, a.allowed_file_types, a.max_files, d.due_date, a.allow_late, a.late_penalty_percent, d.late_cutoff, a.resubmission_policy, a.is_group, a.autograde_language::TEXT
+        FROM assignments a
+        CROSS JOIN LATERAL assignment_deadline(a.id, $2) d
+        WHERE a.id = $1
     `
 
-    var id int
+    var rules entity.AssignmentSubmissionRules
 
-	err := r.db.QueryRowContext(ctx, query, assignmentId).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, assignmentId, userId).Scan(
+		&rules.Id,
+		pq.Array(&rules.AllowedFileTypes),
+		&rules.MaxFiles,
+		&rules.DueDate,
+		&rules.AllowLate,
+		&rules.LatePenaltyPercent,
+		&rules.LateCutoff,
+		&rules.ResubmissionPolicy,
+		&rules.IsGroup,
+		&rules.AutogradeLanguage,
+	)
     if err != nil {
         if err == sql.ErrNoRows {
             log.Error().Str("assignment_id", assignmentId).Msg("repo::FindAssignment - Assignment not found")
-            return errmsg.NewCustomErrors(400, errmsg.WithMessage("Assignment not found"))
+            return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Assignment not found"))
         }
         log.Error().Err(err).Msg("repo::FindAssignment - Failed to check assignment")
-        return err
+        return nil, err
     }
 
-    return nil
+    return &rules, nil
 }
 
+// FindTeam returns the team of the student in the class of the assignment, nil when they have none.
+func (r *submissionRepository) FindTeam(ctx context.Context, assignmentId string, userId string) (*entity.SubmissionTeam, error) {
+	var res = new(entity.SubmissionTeam)
 
-func (r *submissionRepository) SubmitAssignment(ctx context.Context, req *entity.SubmitRequest) (*entity.SubmitResponse, error) {
-    // Query SQL untuk memasukkan data baru ke tabel submissions
-    query := `
-        INSERT INTO submissions (assignment_id, student_id, link, status, submitted_at)
-        VALUES ($1, $2, $3, DEFAULT, DEFAULT)
-        RETURNING id, student_id, link, status, submitted_at
+	query := `
+		SELECT tm.team_id, ARRAY(SELECT m.user_id::TEXT FROM team_members m WHERE m.team_id = tm.team_id ORDER BY m.joined_at)
+		FROM team_members tm
+		JOIN assignments a ON a.class_id = tm.class_id
+		WHERE a.id = $1 AND tm.user_id = $2
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, assignmentId, userId).Scan(&res.Id, pq.Array(&res.MemberIds)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("assignment_id", assignmentId).Str("user_id", userId).Msg("repo::FindTeam - Failed to get team")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetActiveSubmission returns nil when the student, or their team when teamId is set, has not submitted the assignment yet.
+func (r *submissionRepository) GetActiveSubmission(ctx context.Context, assignmentId string, userId string, teamId *int) (*entity.ActiveSubmission, error) {
+	var res = new(entity.ActiveSubmission)
+
+	query := `
+		SELECT id, attempt, status
+		FROM submissions
+		WHERE assignment_id = $1 AND is_active AND ($3::INT IS NULL AND student_id = $2 OR team_id = $3)
+	`
+
+	if err := r.db.GetContext(ctx, res, query, assignmentId, userId, teamId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("assignment_id", assignmentId).Str("user_id", userId).Msg("repo::GetActiveSubmission - Failed to get active submission")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) SubmitAssignment(ctx context.Context, req *entity.SubmitRequest, files []entity.SubmissionFile) (res *entity.SubmitResponse, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::SubmitAssignment - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::SubmitAssignment - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	// previous attempts stay as history, only the new one gets graded, a team shares its attempts
+	deactivateQuery := `
+		UPDATE submissions
+		SET is_active = FALSE
+		WHERE assignment_id = $1 AND is_active AND ($3::INT IS NULL AND student_id = $2 OR team_id = $3)
+	`
+
+	if _, err = tx.ExecContext(ctx, deactivateQuery, req.AssignmentId, req.UserId, req.TeamId); err != nil {
+		log.Error().Err(err).Msg("repo::SubmitAssignment - Failed to deactivate previous submission")
+		return nil, err
+	}
+
+	// Query SQL untuk memasukkan data baru ke tabel submissions
+	query := `
+        INSERT INTO submissions (assignment_id, student_id, team_id, link, answer, status, submitted_at, attempt, is_active, late_days, penalty_percent, graded_at, autograde_status)
+        VALUES (
+            $1, $2, $6, NULLIF($3, ''), NULLIF($7, ''), DEFAULT, DEFAULT,
+            (SELECT COALESCE(MAX(attempt), 0) + 1 FROM submissions WHERE assignment_id = $1 AND ($6::INT IS NULL AND student_id = $2 OR team_id = $6)),
+            TRUE, $4, $5, NULL, CASE WHEN $8 THEN 'pending'::autograde_status END
+        )
+        RETURNING id, student_id, COALESCE(link, ''), COALESCE(answer, ''), status, attempt, late_days, penalty_percent, submitted_at, team_id, autograde_status::TEXT
     `
 
-    var response entity.SubmitResponse
+	var response entity.SubmitResponse
 
-    // Eksekusi query
-    err := r.db.QueryRowContext(ctx, query, req.AssignmentId, req.UserId, req.Link).
-        Scan(&response.Id, &response.UserId, &response.Link, &response.Status, &response.SubmittedAt)
-    if err != nil {
-        log.Error().Err(err).Msg("repo::SubmitAssignment - Failed to submit assignment")
-        return nil, err
-    }
+	// Eksekusi query
+	err = tx.QueryRowContext(ctx, query, req.AssignmentId, req.UserId, req.Link, req.LateDays, req.PenaltyPercent, req.TeamId, req.Answer, req.Autograde).
+		Scan(&response.Id, &response.UserId, &response.Link, &response.Answer, &response.Status, &response.Attempt, &response.LateDays, &response.PenaltyPercent, &response.SubmittedAt, &response.TeamId, &response.AutogradeStatus)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			log.Warn().Any("payload", req).Msg("repo::SubmitAssignment - Concurrent submission for the same assignment")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Another submission for this assignment is being processed"))
+			return nil, err
+		}
+		log.Error().Err(err).Msg("repo::SubmitAssignment - Failed to submit assignment")
+		return nil, err
+	}
 
-    return &response, nil
+	// the members at submit time are the ones graded, later team changes don't move the grade
+	for _, memberId := range req.MemberIds {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO submission_members (submission_id, student_id) VALUES ($1, $2)`, response.Id, memberId); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::SubmitAssignment - Failed to insert submission member")
+			return nil, err
+		}
+	}
+
+	fileQuery := `
+		INSERT INTO submission_files (submission_id, filename, size, mime_type, storage_key)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, submission_id, filename, size, mime_type, storage_key, created_at
+	`
+
+	response.Files = make([]entity.SubmissionFile, 0, len(files))
+	for _, file := range files {
+		var saved entity.SubmissionFile
+		err = tx.QueryRowxContext(ctx, fileQuery, response.Id, file.Filename, file.Size, file.MimeType, file.StorageKey).StructScan(&saved)
+		if err != nil {
+			log.Error().Err(err).Any("file", file).Msg("repo::SubmitAssignment - Failed to insert submission file")
+			return nil, err
+		}
+
+		response.Files = append(response.Files, saved)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::SubmitAssignment - Failed to commit transaction")
+		return nil, err
+	}
+
+	return &response, nil
 }
 
 func (r *submissionRepository) GetSubmissionDetails(ctx context.Context, req *entity.GetSubmissionDetailsRequest) (*entity.GetSubmissionDetailsResponse, error) {
@@ -75,9 +201,14 @@ func (r *submissionRepository) GetSubmissionDetails(ctx context.Context, req *en
             s.id AS submission_id,
             u.name AS name,
             u.image_url AS image_url,
-            s.link AS link,
+            COALESCE(s.link, '') AS link,
+            COALESCE(s.answer, '') AS answer,
             s.status AS status,
+            s.attempt AS attempt,
+            s.is_active AS is_active,
+            s.late_days AS late_days,
             s.grade AS grade,
+            a.max_score AS max_score,
             s.feedback AS feedback,
             s.submitted_at AS submitted_at,
             s.graded_at AS graded_at
@@ -88,7 +219,7 @@ func (r *submissionRepository) GetSubmissionDetails(ctx context.Context, req *en
         INNER JOIN 
             assignments a ON s.assignment_id = a.id
         WHERE 
-            s.id = $1 AND u.id = $2
+            s.id = $1 AND (u.id = $2 OR EXISTS (SELECT 1 FROM submission_members sm WHERE sm.submission_id = s.id AND sm.student_id = $2))
     `
 
     var response entity.GetSubmissionDetailsResponse
@@ -98,8 +229,13 @@ func (r *submissionRepository) GetSubmissionDetails(ctx context.Context, req *en
         &response.Name,
         &response.Image,
         &response.Link,
+        &response.Answer,
         &response.Status,
+        &response.Attempt,
+        &response.IsActive,
+        &response.LateDays,
         &response.Grade,
+        &response.MaxScore,
         &response.Feedback,
         &response.SubmittedAt,
         &response.GradedAt,
@@ -116,46 +252,1011 @@ func (r *submissionRepository) GetSubmissionDetails(ctx context.Context, req *en
     return &response, nil
 }
 
-func (r *submissionRepository) GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest) (*entity.GradingSubmissionResponse, error) {
-    query := `
-        UPDATE 
-            submissions
-        SET 
-            grade = $1,
-            feedback = $2,
-            status = $3,
-            graded_at = NOW()
-        WHERE 
-            id = $4
-        RETURNING 
-            id, grade, feedback, status, graded_at
-    `
+// GradingSubmission stores the rubric scores and the raw grade, the late penalty recorded at submit time is applied on top.
+// The peer score that went into the raw grade is kept next to it.
+func (r *submissionRepository) GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest, scores []entity.RubricScore) (res *entity.GradingSubmissionResponse, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GradingSubmission - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::GradingSubmission - Failed to rollback transaction")
+			}
+		}
+	}()
 
-    var response entity.GradingSubmissionResponse
+	res, err = gradeSubmission(ctx, tx, req, scores)
+	if err != nil {
+		return nil, err
+	}
 
-    err := r.db.QueryRowContext(
-        ctx,
-        query,
-        req.Grade,
-        req.Feedback,
-        req.Status,
-        req.SubmissionId,
-    ).Scan(
-        &response.Id,
-        &response.Grade,
-        &response.Feedback,
-        &response.Status,
-        &response.GradedAt,
-    )
-    if err != nil {
-        if errors.Is(err, sql.ErrNoRows) {
-            log.Error().Any("payload", req).Msg("Submission not found for grading")
-            return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Submission not found"))
-        }
-        log.Error().Err(err).Msg("Failed to grade submission")
-        return nil, err
-    }
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GradingSubmission - Failed to commit transaction")
+		return nil, err
+	}
 
-    return &response, nil
+	return res, nil
+}
+
+// BulkGradingSubmission grades every item in one transaction. Each item runs in its own savepoint,
+// so a failing item is rolled back alone and reported in its result while the others are kept.
+func (r *submissionRepository) BulkGradingSubmission(ctx context.Context, items []entity.BulkGradingItem) (res []entity.BulkGradingResult, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::BulkGradingSubmission - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::BulkGradingSubmission - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	res = make([]entity.BulkGradingResult, len(items))
+	for i, item := range items {
+		res[i].SubmissionId = item.Request.SubmissionId
+
+		if _, err = tx.ExecContext(ctx, `SAVEPOINT grading_item`); err != nil {
+			log.Error().Err(err).Msg("repo::BulkGradingSubmission - Failed to create savepoint")
+			return nil, err
+		}
+
+		graded, errItem := gradeSubmission(ctx, tx, item.Request, item.Scores)
+		if errItem != nil {
+			if _, err = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT grading_item`); err != nil {
+				log.Error().Err(err).Msg("repo::BulkGradingSubmission - Failed to rollback to savepoint")
+				return nil, err
+			}
+
+			res[i].Message, res[i].Errors = "Internal server error", nil
+			if customErr, ok := errItem.(*errmsg.CustomError); ok {
+				res[i].Message, res[i].Errors = customErr.Msg, customErr.Errors
+			}
+			continue
+		}
+
+		if _, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT grading_item`); err != nil {
+			log.Error().Err(err).Msg("repo::BulkGradingSubmission - Failed to release savepoint")
+			return nil, err
+		}
+
+		res[i].Success = true
+		res[i].Result = graded
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::BulkGradingSubmission - Failed to commit transaction")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func gradeSubmission(ctx context.Context, tx *sqlx.Tx, req *entity.GradingSubmissionRequest, scores []entity.RubricScore) (*entity.GradingSubmissionResponse, error) {
+	query := `
+		UPDATE submissions s
+		SET
+			raw_grade = $1,
+			grade = GREATEST(0, ROUND($1 * (100 - s.penalty_percent) / 100, 2)),
+			peer_score = $2,
+			feedback = NULLIF($3, ''),
+			status = 'rated',
+			graded_at = NOW()
+		FROM assignments a
+		WHERE s.id = $4 AND s.is_active AND a.id = s.assignment_id
+		RETURNING s.id, s.peer_score, s.raw_grade, s.penalty_percent, s.grade, a.max_score, COALESCE(s.feedback, ''), s.status, s.graded_at
+	`
+
+	var response entity.GradingSubmissionResponse
+
+	err := tx.QueryRowContext(ctx, query, req.RawGrade, req.PeerScore, req.Feedback, req.SubmissionId).Scan(
+		&response.Id,
+		&response.PeerScore,
+		&response.RawGrade,
+		&response.PenaltyPercent,
+		&response.Grade,
+		&response.MaxScore,
+		&response.Feedback,
+		&response.Status,
+		&response.GradedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::gradeSubmission - Submission not found for grading")
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Submission not found or replaced by a newer attempt"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::gradeSubmission - Failed to grade submission")
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM submission_rubric_scores WHERE submission_id = $1`, response.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::gradeSubmission - Failed to clear rubric scores")
+		return nil, err
+	}
+
+	scoreQuery := `
+		INSERT INTO submission_rubric_scores (submission_id, criterion_id, level_id, points, comment)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`
+
+	for _, score := range scores {
+		if _, err = tx.ExecContext(ctx, scoreQuery, response.Id, score.CriterionId, score.LevelId, score.Points, score.Comment); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::gradeSubmission - Failed to insert rubric score")
+			return nil, err
+		}
+	}
+
+	response.Scores = scores
+
+	return &response, nil
+}
+
+func (r *submissionRepository) GetGradingRubric(ctx context.Context, submissionId string) (*entity.GradingRubric, error) {
+	var res = new(entity.GradingRubric)
+
+	query := `
+		SELECT
+			a.max_score, a.peer_review_weight,
+			(SELECT ROUND(AVG(pr.total), 2) FROM peer_reviews pr WHERE pr.submission_id = s.id AND pr.status = 'completed')
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		WHERE s.id = $1
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, submissionId).Scan(&res.MaxScore, &res.PeerReviewWeight, &res.PeerScore); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Str("submission_id", submissionId).Msg("repo::GetGradingRubric - Submission not found")
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Submission not found"))
+		}
+		log.Error().Err(err).Str("submission_id", submissionId).Msg("repo::GetGradingRubric - Failed to get assignment")
+		return nil, err
+	}
+
+	levelsQuery := `
+		SELECT l.id, l.criterion_id, l.points
+		FROM submissions s
+		JOIN rubric_criteria c ON c.assignment_id = s.assignment_id
+		JOIN rubric_levels l ON l.criterion_id = c.id
+		WHERE s.id = $1
+	`
+
+	if err := r.db.SelectContext(ctx, &res.Levels, levelsQuery, submissionId); err != nil {
+		log.Error().Err(err).Str("submission_id", submissionId).Msg("repo::GetGradingRubric - Failed to get rubric levels")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetRubricScores(ctx context.Context, submissionId int) ([]entity.RubricScore, error) {
+	var res = make([]entity.RubricScore, 0)
+
+	query := `
+		SELECT
+			rs.criterion_id, c.title AS criterion_title, rs.level_id, l.title AS level_title,
+			rs.points, COALESCE(rs.comment, '') AS comment
+		FROM submission_rubric_scores rs
+		JOIN rubric_criteria c ON c.id = rs.criterion_id
+		JOIN rubric_levels l ON l.id = rs.level_id
+		WHERE rs.submission_id = $1
+		ORDER BY c.position, c.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetRubricScores - Failed to get rubric scores")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetSubmissionHistory(ctx context.Context, req *entity.GetSubmissionHistoryRequest) ([]entity.SubmissionAttempt, error) {
+	var res = make([]entity.SubmissionAttempt, 0)
+
+	query := `
+		SELECT
+			s.id, s.attempt, s.is_active, COALESCE(s.link, '') AS link, COALESCE(s.answer, '') AS answer, s.status,
+			COALESCE(sm.grade, s.grade) AS grade, COALESCE(sm.feedback, s.feedback) AS feedback,
+			s.late_days, s.penalty_percent, s.submitted_at, s.graded_at
+		FROM submissions s
+		LEFT JOIN submission_members sm ON sm.submission_id = s.id AND sm.student_id = $2
+		WHERE s.assignment_id = $1 AND (s.student_id = $2 OR sm.student_id IS NOT NULL)
+		ORDER BY s.attempt DESC
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, req.AssignmentId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetSubmissionHistory - Failed to get submission history")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetSubmissionFiles(ctx context.Context, submissionId int) ([]entity.SubmissionFile, error) {
+	var res = make([]entity.SubmissionFile, 0)
+
+	query := `
+		SELECT id, submission_id, filename, size, mime_type, storage_key, created_at
+		FROM submission_files
+		WHERE submission_id = $1
+		ORDER BY id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSubmissionFiles - Failed to get submission files")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetStorageUsage is the quota check of the storage module.
+func (r *submissionRepository) GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error) {
+	return storageRepo.GetStorageUsage(ctx, r.db, userId)
+}
+
+func (r *submissionRepository) GetPeerReviewSettings(ctx context.Context, assignmentId int) (*entity.PeerReviewSettings, error) {
+	var res = new(entity.PeerReviewSettings)
+
+	query := `
+		SELECT
+			a.id, a.creator_assignment_id, a.class_id, a.allow_late, a.peer_review_count, a.peer_review_weight, a.peer_reviews_assigned_at,
+			GREATEST(a.due_date, MAX(e.due_date)) AS due_date,
+			GREATEST(a.late_cutoff, MAX(COALESCE(e.late_cutoff, a.late_cutoff + (e.due_date - a.due_date)))) AS late_cutoff
+		FROM assignments a
+		LEFT JOIN assignment_extensions e ON e.assignment_id = a.id
+		WHERE a.id = $1
+		GROUP BY a.id
+	`
+
+	if err := r.db.GetContext(ctx, res, query, assignmentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::GetPeerReviewSettings - Assignment not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetPeerReviewSettings - Failed to get assignment")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPeerReviewAuthors returns the active submission of every student that is still enrolled in the class.
+func (r *submissionRepository) GetPeerReviewAuthors(ctx context.Context, assignmentId int) ([]entity.PeerReviewAuthor, error) {
+	var res = make([]entity.PeerReviewAuthor, 0)
+
+	query := `
+		SELECT s.id, s.student_id
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		JOIN users_classes uc ON uc.class_id = a.class_id AND uc.user_id = s.student_id
+		WHERE s.assignment_id = $1 AND s.is_active AND uc.enrollment_status IN ('active', 'completed')
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, assignmentId); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetPeerReviewAuthors - Failed to get submissions")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetPeerReviewers(ctx context.Context, classId int) ([]string, error) {
+	var res = make([]string, 0)
+
+	query := `
+		SELECT user_id
+		FROM users_classes
+		WHERE class_id = $1 AND enrollment_status IN ('active', 'completed')
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetPeerReviewers - Failed to get enrolled students")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreatePeerReviews marks the assignment as distributed and inserts the reviews, an assignment is distributed only once.
+func (r *submissionRepository) CreatePeerReviews(ctx context.Context, assignmentId int, pairs []entity.PeerReviewPair) (assignedAt time.Time, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Failed to begin transaction")
+		return assignedAt, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	query := `
+		UPDATE assignments
+		SET peer_reviews_assigned_at = NOW()
+		WHERE id = $1 AND peer_reviews_assigned_at IS NULL
+		RETURNING peer_reviews_assigned_at
+	`
+
+	if err = tx.QueryRowContext(ctx, query, assignmentId).Scan(&assignedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Peer reviews already assigned")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Peer reviews have already been assigned"))
+			return assignedAt, err
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Failed to mark assignment")
+		return assignedAt, err
+	}
+
+	insertQuery := `INSERT INTO peer_reviews (submission_id, reviewer_id) VALUES ($1, $2)`
+
+	for _, pair := range pairs {
+		if _, err = tx.ExecContext(ctx, insertQuery, pair.SubmissionId, pair.ReviewerId); err != nil {
+			log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Failed to insert peer review")
+			return assignedAt, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::CreatePeerReviews - Failed to commit transaction")
+		return assignedAt, err
+	}
+
+	return assignedAt, nil
+}
+
+func (r *submissionRepository) GetPeerReviewTasks(ctx context.Context, req *entity.GetPeerReviewTasksRequest) ([]entity.PeerReviewTask, error) {
+	var res = make([]entity.PeerReviewTask, 0)
+
+	query := `
+		SELECT
+			pr.id, pr.submission_id, pr.status, COALESCE(s.link, '') AS link, COALESCE(s.answer, '') AS answer,
+			pr.total, pr.feedback, pr.created_at, pr.completed_at
+		FROM peer_reviews pr
+		JOIN submissions s ON s.id = pr.submission_id
+		WHERE s.assignment_id = $1 AND pr.reviewer_id = $2
+		ORDER BY pr.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, req.AssignmentId, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetPeerReviewTasks - Failed to get peer reviews")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetPeerReview(ctx context.Context, peerReviewId int) (*entity.PeerReviewRef, error) {
+	var res = new(entity.PeerReviewRef)
+
+	query := `SELECT id, submission_id, reviewer_id, status FROM peer_reviews WHERE id = $1`
+
+	if err := r.db.GetContext(ctx, res, query, peerReviewId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("peer_review_id", peerReviewId).Msg("repo::GetPeerReview - Peer review not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Peer review not found"))
+		}
+		log.Error().Err(err).Int("peer_review_id", peerReviewId).Msg("repo::GetPeerReview - Failed to get peer review")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CompletePeerReview stores the review of a pending peer review owned by the reviewer, a review can't be changed once completed.
+func (r *submissionRepository) CompletePeerReview(ctx context.Context, req *entity.SubmitPeerReviewRequest, scores []entity.RubricScore) (res *entity.SubmitPeerReviewResponse, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CompletePeerReview - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::CompletePeerReview - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	query := `
+		UPDATE peer_reviews
+		SET status = 'completed', total = $1, feedback = NULLIF($2, ''), completed_at = NOW()
+		WHERE id = $3 AND reviewer_id = $4 AND status = 'pending'
+		RETURNING id, status, total, COALESCE(feedback, '') AS feedback, completed_at
+	`
+
+	var response entity.SubmitPeerReviewResponse
+
+	if err = tx.QueryRowxContext(ctx, query, req.Total, req.Feedback, req.PeerReviewId, req.UserId).StructScan(&response); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::CompletePeerReview - Peer review is not pending")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Peer review has already been completed"))
+			return nil, err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::CompletePeerReview - Failed to complete peer review")
+		return nil, err
+	}
+
+	scoreQuery := `
+		INSERT INTO peer_review_scores (peer_review_id, criterion_id, level_id, points, comment)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`
+
+	for _, score := range scores {
+		if _, err = tx.ExecContext(ctx, scoreQuery, response.Id, score.CriterionId, score.LevelId, score.Points, score.Comment); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::CompletePeerReview - Failed to insert peer review score")
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CompletePeerReview - Failed to commit transaction")
+		return nil, err
+	}
+
+	response.Scores = scores
+
+	return &response, nil
+}
+
+func (r *submissionRepository) GetPeerReviewScores(ctx context.Context, peerReviewId int) ([]entity.RubricScore, error) {
+	var res = make([]entity.RubricScore, 0)
+
+	query := `
+		SELECT
+			ps.criterion_id, c.title AS criterion_title, ps.level_id, l.title AS level_title,
+			ps.points, COALESCE(ps.comment, '') AS comment
+		FROM peer_review_scores ps
+		JOIN rubric_criteria c ON c.id = ps.criterion_id
+		JOIN rubric_levels l ON l.id = ps.level_id
+		WHERE ps.peer_review_id = $1
+		ORDER BY c.position, c.id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, peerReviewId); err != nil {
+		log.Error().Err(err).Int("peer_review_id", peerReviewId).Msg("repo::GetPeerReviewScores - Failed to get peer review scores")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetSubmissionOwners returns the author of a submission and the creator of its assignment.
+func (r *submissionRepository) GetSubmissionOwners(ctx context.Context, submissionId int) (string, string, error) {
+	var studentId, creatorId string
+
+	query := `
+		SELECT s.student_id, a.creator_assignment_id
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		WHERE s.id = $1
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, submissionId).Scan(&studentId, &creatorId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("submission_id", submissionId).Msg("repo::GetSubmissionOwners - Submission not found")
+			return "", "", errmsg.NewCustomErrors(404, errmsg.WithMessage("Submission not found"))
+		}
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSubmissionOwners - Failed to get submission")
+		return "", "", err
+	}
+
+	return studentId, creatorId, nil
+}
+
+func (r *submissionRepository) GetSubmissionPeerReviews(ctx context.Context, submissionId int) ([]entity.SubmissionPeerReview, error) {
+	var res = make([]entity.SubmissionPeerReview, 0)
+
+	query := `
+		SELECT id, status, total, feedback, completed_at
+		FROM peer_reviews
+		WHERE submission_id = $1
+		ORDER BY id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSubmissionPeerReviews - Failed to get peer reviews")
+		return nil, err
+	}
+
+	return res, nil
 }
 
+// GetSubmissionMembers returns the members of a team submission, empty for individual submissions.
+func (r *submissionRepository) GetSubmissionMembers(ctx context.Context, submissionId int) ([]entity.SubmissionMember, error) {
+	var res = make([]entity.SubmissionMember, 0)
+
+	query := `
+		SELECT
+			sm.student_id, u.name,
+			COALESCE(sm.grade, s.grade) AS grade, COALESCE(sm.feedback, s.feedback) AS feedback,
+			sm.grade IS NOT NULL OR sm.feedback IS NOT NULL AS overridden
+		FROM submission_members sm
+		JOIN submissions s ON s.id = sm.submission_id
+		JOIN users u ON u.id = sm.student_id
+		WHERE sm.submission_id = $1
+		ORDER BY u.name, sm.student_id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSubmissionMembers - Failed to get submission members")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// OverrideMemberGrade sets the grade and feedback of one team member, NULL values fall back to the team's.
+func (r *submissionRepository) OverrideMemberGrade(ctx context.Context, req *entity.OverrideMemberGradeRequest) error {
+	query := `
+		UPDATE submission_members
+		SET grade = $1, feedback = NULLIF($2, '')
+		WHERE submission_id = $3 AND student_id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, req.Grade, req.Feedback, req.SubmissionId, req.StudentId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::OverrideMemberGrade - Failed to override grade")
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Warn().Any("payload", req).Msg("repo::OverrideMemberGrade - Student is not a member of the submission")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Student is not a member of this team submission"))
+	}
+
+	return nil
+}
+
+var inboxSort = map[string]string{
+	"submitted_at": "s.submitted_at",
+	"due_date":     "a.due_date",
+	"student":      "u.name",
+	"assignment":   "a.title",
+}
+
+// GetInbox returns one page of the active submissions in the classes created by the teacher and the total matching.
+func (r *submissionRepository) GetInbox(ctx context.Context, req *entity.GetInboxRequest) ([]entity.InboxItem, int, error) {
+	var (
+		res        = make([]entity.InboxItem, 0)
+		total      int
+		conditions = "c.creator_class_id = ? AND s.is_active"
+		args       = []any{req.UserId}
+	)
+
+	if req.ClassId != 0 {
+		conditions += " AND c.id = ?"
+		args = append(args, req.ClassId)
+	}
+	if req.AssignmentId != 0 {
+		conditions += " AND a.id = ?"
+		args = append(args, req.AssignmentId)
+	}
+	if req.Status != "" {
+		conditions += " AND s.status = ?"
+		args = append(args, req.Status)
+	}
+	if req.LateOnly {
+		conditions += " AND s.late_days > 0"
+	}
+
+	from := `
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		JOIN class c ON c.id = a.class_id
+		JOIN users u ON u.id = s.student_id
+		LEFT JOIN teams t ON t.id = s.team_id
+		WHERE ` + conditions
+
+	if err := r.db.GetContext(ctx, &total, r.db.Rebind(`SELECT COUNT(*) `+from), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetInbox - Failed to count submissions")
+		return nil, 0, err
+	}
+
+	// sort and order are checked by the validator, the map keeps them out of the query text anyway
+	sort, ok := inboxSort[req.Sort]
+	if !ok {
+		sort = inboxSort["submitted_at"]
+	}
+	order := "ASC"
+	if req.Order == "desc" {
+		order = "DESC"
+	}
+
+	query := `
+		SELECT
+			s.id AS submission_id, c.id AS class_id, c.title AS class_title,
+			a.id AS assignment_id, a.title AS assignment_title, a.due_date, a.max_score,
+			s.student_id, u.name AS student_name, t.name AS team_name,
+			s.status, s.attempt, s.late_days, s.grade, s.submitted_at, s.graded_at
+		` + from + `
+		ORDER BY ` + sort + ` ` + order + `, s.id
+		LIMIT ? OFFSET ?
+	`
+
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetInbox - Failed to get submissions")
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
+func (r *submissionRepository) GetAssignmentCreator(ctx context.Context, assignmentId int) (string, error) {
+	var creatorId string
+
+	if err := r.db.GetContext(ctx, &creatorId, `SELECT creator_assignment_id FROM assignments WHERE id = $1`, assignmentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("assignment_id", assignmentId).Msg("repo::GetAssignmentCreator - Assignment not found")
+			return "", errmsg.NewCustomErrors(404, errmsg.WithMessage("Assignment not found"))
+		}
+		log.Error().Err(err).Int("assignment_id", assignmentId).Msg("repo::GetAssignmentCreator - Failed to get assignment")
+		return "", err
+	}
+
+	return creatorId, nil
+}
+
+// SaveSimilarityDocument stores the compared text of a submission and replaces its fingerprints.
+func (r *submissionRepository) SaveSimilarityDocument(ctx context.Context, doc *entity.SimilarityDocument) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	documentQuery := `
+		INSERT INTO submission_documents (submission_id, assignment_id, content, fingerprint_count, checked_at)
+		SELECT id, assignment_id, $2, $3, NOW()
+		FROM submissions
+		WHERE id = $1
+		ON CONFLICT (submission_id) DO UPDATE
+		SET content = EXCLUDED.content, fingerprint_count = EXCLUDED.fingerprint_count, checked_at = EXCLUDED.checked_at
+	`
+
+	if _, err = tx.ExecContext(ctx, documentQuery, doc.SubmissionId, doc.Content, doc.FingerprintCount); err != nil {
+		log.Error().Err(err).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to save document")
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM submission_fingerprints WHERE submission_id = $1`, doc.SubmissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to clear fingerprints")
+		return err
+	}
+
+	var (
+		hashes = make([]int64, len(doc.Fingerprints))
+		starts = make([]int64, len(doc.Fingerprints))
+		ends   = make([]int64, len(doc.Fingerprints))
+	)
+
+	for i, fp := range doc.Fingerprints {
+		hashes[i], starts[i], ends[i] = fp.Hash, int64(fp.Start), int64(fp.End)
+	}
+
+	fingerprintQuery := `
+		INSERT INTO submission_fingerprints (submission_id, assignment_id, hash, start_pos, end_pos)
+		SELECT d.submission_id, d.assignment_id, f.hash, f.start_pos, f.end_pos
+		FROM submission_documents d, UNNEST($2::BIGINT[], $3::INT[], $4::INT[]) AS f (hash, start_pos, end_pos)
+		WHERE d.submission_id = $1
+	`
+
+	if _, err = tx.ExecContext(ctx, fingerprintQuery, doc.SubmissionId, pq.Array(hashes), pq.Array(starts), pq.Array(ends)); err != nil {
+		log.Error().Err(err).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to insert fingerprints")
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Int("submission_id", doc.SubmissionId).Msg("repo::SaveSimilarityDocument - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// GetSimilarityCandidates returns the active submissions of other students or teams in the same assignment
+// that share fingerprints with the submission, earlier attempts of the same author are left out.
+func (r *submissionRepository) GetSimilarityCandidates(ctx context.Context, submissionId int) ([]entity.SimilarityCandidate, error) {
+	var res = make([]entity.SimilarityCandidate, 0)
+
+	query := `
+		SELECT o.submission_id, d.fingerprint_count, COUNT(DISTINCT o.hash) AS shared
+		FROM submission_fingerprints f
+		JOIN submissions s ON s.id = f.submission_id
+		JOIN submission_fingerprints o ON o.assignment_id = f.assignment_id AND o.hash = f.hash AND o.submission_id <> f.submission_id
+		JOIN submissions os ON os.id = o.submission_id
+			AND os.is_active
+			AND os.student_id <> s.student_id
+			AND (s.team_id IS NULL OR os.team_id IS DISTINCT FROM s.team_id)
+		JOIN submission_documents d ON d.submission_id = o.submission_id
+		WHERE f.submission_id = $1
+		GROUP BY o.submission_id, d.fingerprint_count
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetSimilarityCandidates - Failed to get candidates")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *submissionRepository) GetFingerprints(ctx context.Context, submissionId int) ([]pkg.Fingerprint, error) {
+	var res = make([]pkg.Fingerprint, 0)
+
+	query := `
+		SELECT hash, start_pos AS start, end_pos AS "end"
+		FROM submission_fingerprints
+		WHERE submission_id = $1
+		ORDER BY start_pos
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetFingerprints - Failed to get fingerprints")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SaveSimilarityMatches replaces every pair the submission is part of.
+func (r *submissionRepository) SaveSimilarityMatches(ctx context.Context, submissionId int, matches []entity.SimilarityMatch) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM similarity_matches WHERE submission_a = $1 OR submission_b = $1`, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to clear matches")
+		return err
+	}
+
+	query := `
+		INSERT INTO similarity_matches (submission_a, submission_b, assignment_id, score, shared_fingerprints, spans)
+		SELECT $1, $2, assignment_id, $3, $4, $5
+		FROM submission_documents
+		WHERE submission_id = $1
+		ON CONFLICT (submission_a, submission_b) DO UPDATE
+		SET score = EXCLUDED.score, shared_fingerprints = EXCLUDED.shared_fingerprints, spans = EXCLUDED.spans, created_at = NOW()
+	`
+
+	for _, match := range matches {
+		spans, errMarshal := json.Marshal(match.Spans)
+		if errMarshal != nil {
+			err = errMarshal
+			log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to marshal spans")
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, query, match.SubmissionA, match.SubmissionB, match.Score, match.SharedFingerprints, spans); err != nil {
+			log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to insert match")
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::SaveSimilarityMatches - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// GetSimilarityReport returns the pairs of active submissions of an assignment, most similar first, and how many
+// active submissions were checked.
+func (r *submissionRepository) GetSimilarityReport(ctx context.Context, req *entity.GetSimilarityReportRequest) ([]entity.SimilarityPairRow, int, error) {
+	var (
+		res     = make([]entity.SimilarityPairRow, 0)
+		checked int
+	)
+
+	checkedQuery := `
+		SELECT COUNT(*)
+		FROM submission_documents d
+		JOIN submissions s ON s.id = d.submission_id AND s.is_active
+		WHERE d.assignment_id = $1
+	`
+
+	if err := r.db.GetContext(ctx, &checked, checkedQuery, req.AssignmentId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetSimilarityReport - Failed to count checked submissions")
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT
+			m.submission_a, m.submission_b, m.score, m.shared_fingerprints, m.spans, m.created_at,
+			sa.student_id AS student_a, ua.name AS name_a, ta.name AS team_a, doc_a.content AS content_a,
+			sb.student_id AS student_b, ub.name AS name_b, tb.name AS team_b, doc_b.content AS content_b
+		FROM similarity_matches m
+		JOIN submissions sa ON sa.id = m.submission_a AND sa.is_active
+		JOIN submissions sb ON sb.id = m.submission_b AND sb.is_active
+		JOIN users ua ON ua.id = sa.student_id
+		JOIN users ub ON ub.id = sb.student_id
+		LEFT JOIN teams ta ON ta.id = sa.team_id
+		LEFT JOIN teams tb ON tb.id = sb.team_id
+		JOIN submission_documents doc_a ON doc_a.submission_id = m.submission_a
+		JOIN submission_documents doc_b ON doc_b.submission_id = m.submission_b
+		WHERE m.assignment_id = $1 AND m.score >= $2
+		ORDER BY m.score DESC, m.shared_fingerprints DESC, m.submission_a, m.submission_b
+		LIMIT $3
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, req.AssignmentId, req.MinScore, req.Limit); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetSimilarityReport - Failed to get matches")
+		return nil, 0, err
+	}
+
+	return res, checked, nil
+}
+
+// GetAutogradeSuite returns the settings and test cases of the assignment of a submission.
+func (r *submissionRepository) GetAutogradeSuite(ctx context.Context, submissionId int) (*entity.AutogradeSuite, error) {
+	var suite entity.AutogradeSuite
+
+	query := `
+		SELECT a.autograde_language::TEXT AS autograde_language, a.autograde_time_limit_ms, a.autograde_memory_mb
+		FROM submissions s
+		JOIN assignments a ON a.id = s.assignment_id
+		WHERE s.id = $1 AND a.autograde_language IS NOT NULL
+	`
+
+	if err := r.db.GetContext(ctx, &suite, query, submissionId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("submission_id", submissionId).Msg("repo::GetAutogradeSuite - Automated grading is not enabled")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Automated grading is not enabled for this assignment"))
+		}
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetAutogradeSuite - Failed to get settings")
+		return nil, err
+	}
+
+	testQuery := `
+		SELECT tc.id, tc.input, tc.expected_output, tc.points
+		FROM assignment_test_cases tc
+		JOIN submissions s ON s.assignment_id = tc.assignment_id
+		WHERE s.id = $1
+		ORDER BY tc.position, tc.id
+	`
+
+	suite.Tests = make([]entity.AutogradeTest, 0)
+	if err := r.db.SelectContext(ctx, &suite.Tests, testQuery, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetAutogradeSuite - Failed to get test cases")
+		return nil, err
+	}
+
+	return &suite, nil
+}
+
+// SaveAutogradeResult replaces the test results of a submission. The score becomes the grade, with the late penalty
+// applied, unless the teacher graded the submission already.
+func (r *submissionRepository) SaveAutogradeResult(ctx context.Context, res *entity.AutogradeResult) (grade *entity.AutogradeGrade, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	// prev holds the status before the update, a submission the teacher already rated keeps their grade
+	query := `
+		WITH prev AS (
+			SELECT id, status FROM submissions WHERE id = $4 FOR UPDATE
+		)
+		UPDATE submissions s
+		SET
+			autograde_status = $1,
+			autograde_score = $2,
+			autograde_output = NULLIF($3, ''),
+			autograded_at = NOW(),
+			raw_grade = CASE WHEN $2::NUMERIC IS NOT NULL AND s.status <> 'rated' THEN $2 ELSE s.raw_grade END,
+			grade = CASE WHEN $2::NUMERIC IS NOT NULL AND s.status <> 'rated' THEN GREATEST(0, ROUND($2 * (100 - s.penalty_percent) / 100, 2)) ELSE s.grade END,
+			graded_at = CASE WHEN $2::NUMERIC IS NOT NULL AND s.status <> 'rated' THEN NOW() ELSE s.graded_at END,
+			status = CASE WHEN $2::NUMERIC IS NOT NULL THEN 'rated' ELSE s.status END
+		FROM prev, assignments a
+		WHERE s.id = prev.id AND a.id = s.assignment_id
+		RETURNING $2::NUMERIC IS NOT NULL AND prev.status <> 'rated' AS graded, COALESCE(s.grade, 0) AS grade, a.max_score
+	`
+
+	var saved entity.AutogradeGrade
+	if err = tx.GetContext(ctx, &saved, query, res.Status, res.Score, res.Output, res.SubmissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to update submission")
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM submission_test_results WHERE submission_id = $1`, res.SubmissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to clear test results")
+		return nil, err
+	}
+
+	resultQuery := `
+		INSERT INTO submission_test_results (submission_id, test_case_id, passed, points, output, exit_code, timed_out, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for _, test := range res.Tests {
+		if _, err = tx.ExecContext(ctx, resultQuery, res.SubmissionId, test.TestCaseId, test.Passed, test.Points, test.Output, test.ExitCode, test.TimedOut, test.DurationMs); err != nil {
+			log.Error().Err(err).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to insert test result")
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Int("submission_id", res.SubmissionId).Msg("repo::SaveAutogradeResult - Failed to commit transaction")
+		return nil, err
+	}
+
+	if !saved.Graded {
+		return nil, nil
+	}
+
+	return &saved, nil
+}
+
+// GetAutograde returns the automated grading of a submission with every test result, nil when it was not autograded.
+func (r *submissionRepository) GetAutograde(ctx context.Context, submissionId int) (*entity.Autograde, error) {
+	var res entity.Autograde
+
+	query := `
+		SELECT
+			s.autograde_status::TEXT AS autograde_status,
+			s.autograde_score,
+			COALESCE((SELECT SUM(tc.points) FROM assignment_test_cases tc WHERE tc.assignment_id = s.assignment_id), 0) AS max_points,
+			COALESCE(s.autograde_output, '') AS autograde_output,
+			s.autograded_at
+		FROM submissions s
+		WHERE s.id = $1 AND s.autograde_status IS NOT NULL
+	`
+
+	if err := r.db.GetContext(ctx, &res, query, submissionId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetAutograde - Failed to get automated grading")
+		return nil, err
+	}
+
+	testQuery := `
+		SELECT tr.test_case_id, tc.name, tc.is_hidden, tr.passed, tr.points, tc.points AS max_points, tr.output, tr.exit_code, tr.timed_out, tr.duration_ms
+		FROM submission_test_results tr
+		JOIN assignment_test_cases tc ON tc.id = tr.test_case_id
+		WHERE tr.submission_id = $1
+		ORDER BY tc.position, tc.id
+	`
+
+	res.Tests = make([]entity.AutogradeCheck, 0)
+	if err := r.db.SelectContext(ctx, &res.Tests, testQuery, submissionId); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("repo::GetAutograde - Failed to get test results")
+		return nil, err
+	}
+
+	return &res, nil
+}
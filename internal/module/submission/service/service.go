@@ -2,34 +2,135 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"hacko-app/internal/infrastructure/config"
+	integSandbox "hacko-app/internal/integration/sandbox"
+	sandboxEntity "hacko-app/internal/integration/sandbox/entity"
+	integStorage "hacko-app/internal/integration/storage"
+	storageEntity "hacko-app/internal/integration/storage/entity"
 	"hacko-app/internal/module/submission/entity"
 	"hacko-app/internal/module/submission/ports"
+	"hacko-app/pkg"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/eventbus"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 var _ ports.SubmissionService = &submissionService{}
 
+const (
+	// similarityMinShared is the number of shared fingerprints below which a pair is not worth reporting.
+	similarityMinShared = 3
+	similarityTimeout   = 2 * time.Minute
+	// autogradeTimeout covers waiting for a sandbox slot, compiling and every test run.
+	autogradeTimeout = 10 * time.Minute
+)
+
+// autogradeExtensions is the file extension a submitted program is picked by.
+var autogradeExtensions = map[string]string{
+	integSandbox.LanguageGo:     ".go",
+	integSandbox.LanguagePython: ".py",
+}
+
+// similaritySlots bounds the similarity checks running at the same time.
+var similaritySlots = make(chan struct{}, 4)
+
 type submissionService struct {
-	repo ports.SubmissionRepository
+	repo    ports.SubmissionRepository
+	storage integStorage.Storage
+	sandbox integSandbox.Sandbox
 }
 
-func NewSubmissionService(repo ports.SubmissionRepository) *submissionService {
+func NewSubmissionService(repo ports.SubmissionRepository, storage integStorage.Storage, sandbox integSandbox.Sandbox) *submissionService {
 	return &submissionService{
-		repo: repo,
+		repo:    repo,
+		storage: storage,
+		sandbox: sandbox,
 	}
 }
 
 func (s *submissionService) SubmitAssignment(ctx context.Context, req *entity.SubmitRequest) (*entity.SubmitResponse, error) {
+	rules, err := s.repo.FindAssignment(ctx, req.AssignmentId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// a group assignment is handed in once for the whole team
+	if rules.IsGroup {
+		team, err := s.repo.FindTeam(ctx, req.AssignmentId, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		if team == nil {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Join a team to submit this group assignment"))
+		}
+
+		req.TeamId = &team.Id
+		req.MemberIds = team.MemberIds
+	}
 
-	err := s.repo.FindAssignment(ctx, req.AssignmentId)
+	now := time.Now()
+
+	active, err := s.repo.GetActiveSubmission(ctx, req.AssignmentId, req.UserId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	if active != nil {
+		if err := checkResubmission(rules, active, now); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("service::SubmitAssignment - Resubmission not allowed")
+			return nil, err
+		}
+	}
+
+	req.LateDays, req.PenaltyPercent, err = latePenalty(rules, now)
 	if err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("service::SubmitAssignment - Submission after deadline")
 		return nil, err
 	}
 
-	response, err := s.repo.SubmitAssignment(ctx, req)
+	files, err := s.putFiles(ctx, req, rules)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.Link == "" && req.Answer == "" && len(files) == 0 {
+		errs := errmsg.NewCustomErrors(400, errmsg.WithMessage("Submission is empty"))
+		errs.Add("link", "link, answer or files is required.")
+		errs.Add("answer", "link, answer or files is required.")
+		errs.Add("files", "link, answer or files is required.")
+		return nil, errs
+	}
+
+	req.Autograde = rules.AutogradeLanguage != nil
+
+	response, err := s.repo.SubmitAssignment(ctx, req, files)
+	if err != nil {
+		s.deleteFiles(ctx, files)
+		return nil, err
+	}
+
+	// the background checks read the files back from the storage, the request is over by the time they run
+	// both run past the request, the shutdown waits for them on the event bus
+	eventbus.Go("similarity", func() { s.checkSimilarity(response.Id, req.Answer, files) })
+
+	if req.Autograde {
+		eventbus.Go("autograde", func() { s.autograde(response.Id, req.Answer, files) })
+	}
+
+	if err := s.signFiles(ctx, response.Files); err != nil {
+		return nil, err
+	}
+
 	return response, nil
 }
 
@@ -39,14 +140,934 @@ func (s *submissionService) GetSubmissionDetails(ctx context.Context, req *entit
 		return nil, err
 	}
 
+	files, err := s.repo.GetSubmissionFiles(ctx, response.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.signFiles(ctx, files); err != nil {
+		return nil, err
+	}
+
+	scores, err := s.repo.GetRubricScores(ctx, response.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetSubmissionMembers(ctx, response.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	autograde, err := s.repo.GetAutograde(ctx, response.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// details are the student's view, the output of hidden tests would give their input away
+	if autograde != nil {
+		for i := range autograde.Tests {
+			if autograde.Tests[i].IsHidden {
+				autograde.Tests[i].Output = nil
+			}
+		}
+	}
+
+	response.Files = files
+	response.Scores = scores
+	response.Members = members
+	response.Autograde = autograde
+
+	return response, nil
+}
+
+func (s *submissionService) GetSubmissionHistory(ctx context.Context, req *entity.GetSubmissionHistoryRequest) ([]entity.SubmissionAttempt, error) {
+	response, err := s.repo.GetSubmissionHistory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response {
+		files, err := s.repo.GetSubmissionFiles(ctx, response[i].Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.signFiles(ctx, files); err != nil {
+			return nil, err
+		}
+
+		response[i].Files = files
+	}
+
 	return response, nil
 }
 
 func (s *submissionService) GradingSubmission(ctx context.Context, req *entity.GradingSubmissionRequest) (*entity.GradingSubmissionResponse, error) {
-	response, err := s.repo.GradingSubmission(ctx, req)
+	scores, total, err := s.prepareCreatorGrade(ctx, req.UserId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.repo.GradingSubmission(ctx, req, scores)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetSubmissionMembers(ctx, response.Id)
 	if err != nil {
 		return nil, err
 	}
 
+	response.TeacherScore = total
+	response.Members = members
+
+	eventbus.Publish(eventbus.SubmissionGradedEvent{SubmissionId: response.Id, Grade: response.Grade, MaxScore: response.MaxScore})
+
+	return response, nil
+}
+
+// BulkGradingSubmission grades many submissions at once. Items the teacher may not grade or with an invalid
+// grade fail on their own, the rest are stored together.
+func (s *submissionService) BulkGradingSubmission(ctx context.Context, req *entity.BulkGradingRequest) (*entity.BulkGradingResponse, error) {
+	var (
+		results = make([]entity.BulkGradingResult, len(req.Items))
+		items   = make([]entity.BulkGradingItem, 0, len(req.Items))
+		indexes = make([]int, 0, len(req.Items))
+		totals  = make([]float64, 0, len(req.Items))
+	)
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		results[i].SubmissionId = item.SubmissionId
+
+		scores, total, err := s.prepareCreatorGrade(ctx, req.UserId, item)
+		if err != nil {
+			customErr, ok := err.(*errmsg.CustomError)
+			if !ok {
+				return nil, err
+			}
+			results[i].Message, results[i].Errors = customErr.Msg, customErr.Errors
+			continue
+		}
+
+		items = append(items, entity.BulkGradingItem{Request: item, Scores: scores})
+		indexes = append(indexes, i)
+		totals = append(totals, total)
+	}
+
+	if len(items) > 0 {
+		graded, err := s.repo.BulkGradingSubmission(ctx, items)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, result := range graded {
+			if result.Success {
+				members, err := s.repo.GetSubmissionMembers(ctx, result.Result.Id)
+				if err != nil {
+					return nil, err
+				}
+
+				result.Result.TeacherScore = totals[j]
+				result.Result.Members = members
+
+				eventbus.Publish(eventbus.SubmissionGradedEvent{SubmissionId: result.Result.Id, Grade: result.Result.Grade, MaxScore: result.Result.MaxScore})
+			}
+			results[indexes[j]] = result
+		}
+	}
+
+	response := &entity.BulkGradingResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Graded++
+		} else {
+			response.Failed++
+		}
+	}
+
 	return response, nil
 }
+
+// prepareCreatorGrade prepares the grade once userId is known to have created the assignment of the submission.
+func (s *submissionService) prepareCreatorGrade(ctx context.Context, userId string, req *entity.GradingSubmissionRequest) ([]entity.RubricScore, float64, error) {
+	submissionId, err := strconv.Atoi(req.SubmissionId)
+	if err != nil {
+		return nil, 0, errmsg.NewCustomErrors(400, errmsg.WithErrors("submission_id", "submission_id must be a number."))
+	}
+
+	_, creatorId, err := s.repo.GetSubmissionOwners(ctx, submissionId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if creatorId != userId {
+		log.Warn().Any("payload", req).Msg("service::prepareCreatorGrade - User is not the assignment creator")
+		return nil, 0, errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the assignment creator can grade submissions"))
+	}
+
+	return s.prepareGrade(ctx, req)
+}
+
+// prepareGrade scores the rubric and sets the raw grade on the request, blended with the peer score when the
+// assignment uses peer review. It returns the scores to store and the teacher's own total.
+func (s *submissionService) prepareGrade(ctx context.Context, req *entity.GradingSubmissionRequest) ([]entity.RubricScore, float64, error) {
+	rubric, err := s.repo.GetGradingRubric(ctx, req.SubmissionId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scores, total, err := scoreRubric(rubric, req.Scores, req.Grade)
+	if err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("service::prepareGrade - Invalid grade")
+		return nil, 0, err
+	}
+
+	req.RawGrade = total
+
+	// completed peer reviews take their share of the grade, without any the teacher's grade counts fully
+	if rubric.PeerReviewWeight > 0 && rubric.PeerScore != nil {
+		weight := rubric.PeerReviewWeight / 100
+		req.RawGrade = math.Round((total*(1-weight)+*rubric.PeerScore*weight)*100) / 100
+		req.PeerScore = rubric.PeerScore
+	}
+
+	return scores, total, nil
+}
+
+func (s *submissionService) GetInbox(ctx context.Context, req *entity.GetInboxRequest) (*entity.GetInboxResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Paginate == 0 {
+		req.Paginate = 20
+	}
+
+	items, total, err := s.repo.GetInbox(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &entity.GetInboxResponse{Items: items}
+	response.Meta.CountTotalPage(req.Page, req.Paginate, total)
+
+	return response, nil
+}
+
+// OverrideMemberGrade replaces the team grade for one member, it is taken as is without the late penalty.
+func (s *submissionService) OverrideMemberGrade(ctx context.Context, req *entity.OverrideMemberGradeRequest) (*entity.SubmissionMember, error) {
+	_, creatorId, err := s.repo.GetSubmissionOwners(ctx, req.SubmissionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if creatorId != req.UserId {
+		log.Warn().Any("payload", req).Msg("service::OverrideMemberGrade - User is not the assignment creator")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the assignment creator can grade submissions"))
+	}
+
+	if req.Grade != nil {
+		rubric, err := s.repo.GetGradingRubric(ctx, strconv.Itoa(req.SubmissionId))
+		if err != nil {
+			return nil, err
+		}
+
+		if *req.Grade > rubric.MaxScore {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("grade", "grade must not exceed the max score of "+strconv.FormatFloat(rubric.MaxScore, 'f', -1, 64)+"."))
+		}
+	}
+
+	if err := s.repo.OverrideMemberGrade(ctx, req); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetSubmissionMembers(ctx, req.SubmissionId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range members {
+		if members[i].StudentId == req.StudentId {
+			return &members[i], nil
+		}
+	}
+
+	return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Student is not a member of this team submission"))
+}
+
+func (s *submissionService) AssignPeerReviews(ctx context.Context, req *entity.AssignPeerReviewsRequest) (*entity.AssignPeerReviewsResponse, error) {
+	settings, err := s.repo.GetPeerReviewSettings(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.CreatorId != req.UserId {
+		log.Warn().Any("payload", req).Msg("service::AssignPeerReviews - User is not the assignment creator")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the assignment creator can assign peer reviews"))
+	}
+
+	if settings.PeerReviewCount == 0 {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Peer review is not enabled for this assignment"))
+	}
+
+	if time.Now().Before(submissionClose(settings)) {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithMessage("Peer reviews can only be assigned after the deadline"))
+	}
+
+	return s.assignPeerReviews(ctx, settings)
+}
+
+// assignPeerReviews hands every active submission to peer_review_count enrolled students other than its author.
+// The assignment id seeds the distribution, so the same class and submissions always give the same reviewers.
+func (s *submissionService) assignPeerReviews(ctx context.Context, settings *entity.PeerReviewSettings) (*entity.AssignPeerReviewsResponse, error) {
+	submissions, err := s.repo.GetPeerReviewAuthors(ctx, settings.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers, err := s.repo.GetPeerReviewers(ctx, settings.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make([]string, 0, len(submissions))
+	for _, submission := range submissions {
+		authors = append(authors, submission.StudentId)
+	}
+
+	assigned := pkg.AssignPeerReviews(authors, reviewers, settings.PeerReviewCount, strconv.Itoa(settings.AssignmentId))
+
+	pairs := make([]entity.PeerReviewPair, 0, len(submissions)*settings.PeerReviewCount)
+	for _, submission := range submissions {
+		for _, reviewer := range assigned[submission.StudentId] {
+			pairs = append(pairs, entity.PeerReviewPair{SubmissionId: submission.SubmissionId, ReviewerId: reviewer})
+		}
+	}
+
+	assignedAt, err := s.repo.CreatePeerReviews(ctx, settings.AssignmentId, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AssignPeerReviewsResponse{
+		AssignmentId: settings.AssignmentId,
+		Submissions:  len(submissions),
+		Reviews:      len(pairs),
+		AssignedAt:   assignedAt,
+	}, nil
+}
+
+func (s *submissionService) GetPeerReviewTasks(ctx context.Context, req *entity.GetPeerReviewTasksRequest) ([]entity.PeerReviewTask, error) {
+	settings, err := s.repo.GetPeerReviewSettings(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	// reviews are handed out by the first student asking for them once submissions are closed
+	if settings.PeerReviewCount > 0 && settings.AssignedAt == nil && !time.Now().Before(submissionClose(settings)) {
+		if _, err := s.assignPeerReviews(ctx, settings); err != nil {
+			var customErr *errmsg.CustomError
+			if !errors.As(err, &customErr) || customErr.Code != 409 {
+				return nil, err
+			}
+		}
+	}
+
+	response, err := s.repo.GetPeerReviewTasks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response {
+		files, err := s.repo.GetSubmissionFiles(ctx, response[i].SubmissionId)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.signFiles(ctx, files); err != nil {
+			return nil, err
+		}
+
+		scores, err := s.repo.GetPeerReviewScores(ctx, response[i].Id)
+		if err != nil {
+			return nil, err
+		}
+
+		response[i].Files = files
+		response[i].Scores = scores
+	}
+
+	return response, nil
+}
+
+func (s *submissionService) SubmitPeerReview(ctx context.Context, req *entity.SubmitPeerReviewRequest) (*entity.SubmitPeerReviewResponse, error) {
+	review, err := s.repo.GetPeerReview(ctx, req.PeerReviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.ReviewerId != req.UserId {
+		log.Warn().Any("payload", req).Msg("service::SubmitPeerReview - User is not the reviewer")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Peer review is assigned to another student"))
+	}
+
+	rubric, err := s.repo.GetGradingRubric(ctx, strconv.Itoa(review.SubmissionId))
+	if err != nil {
+		return nil, err
+	}
+
+	scores, total, err := scoreRubric(rubric, req.Scores, req.Grade)
+	if err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("service::SubmitPeerReview - Invalid review")
+		return nil, err
+	}
+
+	req.Total = total
+
+	return s.repo.CompletePeerReview(ctx, req, scores)
+}
+
+// GetSubmissionPeerReviews shows the reviews of a submission to its author and to the teacher, reviewers stay anonymous.
+func (s *submissionService) GetSubmissionPeerReviews(ctx context.Context, req *entity.GetSubmissionPeerReviewsRequest) (*entity.GetSubmissionPeerReviewsResponse, error) {
+	studentId, creatorId, err := s.repo.GetSubmissionOwners(ctx, req.SubmissionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UserId != studentId && req.UserId != creatorId {
+		log.Warn().Any("payload", req).Msg("service::GetSubmissionPeerReviews - User has no access to the submission")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("You don't have access to this submission"))
+	}
+
+	reviews, err := s.repo.GetSubmissionPeerReviews(ctx, req.SubmissionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sum       float64
+		completed int
+	)
+
+	for i := range reviews {
+		reviews[i].Reviewer = "Reviewer " + strconv.Itoa(i+1)
+
+		scores, err := s.repo.GetPeerReviewScores(ctx, reviews[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		reviews[i].Scores = scores
+
+		if reviews[i].Status == "completed" && reviews[i].Total != nil {
+			sum += *reviews[i].Total
+			completed++
+		}
+	}
+
+	response := &entity.GetSubmissionPeerReviewsResponse{
+		SubmissionId: req.SubmissionId,
+		Reviews:      reviews,
+	}
+
+	if completed > 0 {
+		peerScore := math.Round(sum/float64(completed)*100) / 100
+		response.PeerScore = &peerScore
+	}
+
+	return response, nil
+}
+
+// checkSimilarity fingerprints a new submission and compares it with the other active submissions of its
+// assignment. It runs after the submission is stored, so failures are only logged.
+func (s *submissionService) checkSimilarity(submissionId int, answer string, files []entity.SubmissionFile) {
+	similaritySlots <- struct{}{}
+	defer func() { <-similaritySlots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), similarityTimeout)
+	defer cancel()
+
+	content := s.similarityText(ctx, answer, files)
+	if content == "" {
+		return
+	}
+
+	fingerprints := pkg.Winnow(content)
+	doc := &entity.SimilarityDocument{
+		SubmissionId:     submissionId,
+		Content:          content,
+		Fingerprints:     fingerprints,
+		FingerprintCount: pkg.DistinctHashes(fingerprints),
+	}
+
+	if err := s.repo.SaveSimilarityDocument(ctx, doc); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("service::checkSimilarity - Failed to save document")
+		return
+	}
+
+	candidates, err := s.repo.GetSimilarityCandidates(ctx, submissionId)
+	if err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("service::checkSimilarity - Failed to get candidates")
+		return
+	}
+
+	matches := make([]entity.SimilarityMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Shared < similarityMinShared {
+			continue
+		}
+
+		other, err := s.repo.GetFingerprints(ctx, candidate.SubmissionId)
+		if err != nil {
+			log.Error().Err(err).Int("submission_id", submissionId).Msg("service::checkSimilarity - Failed to get fingerprints")
+			return
+		}
+
+		matches = append(matches, similarityMatch(doc, fingerprints, candidate, other))
+	}
+
+	if err := s.repo.SaveSimilarityMatches(ctx, submissionId, matches); err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("service::checkSimilarity - Failed to save matches")
+	}
+}
+
+// similarityMatch scores a pair by the shared fingerprints over all fingerprints of both submissions.
+func similarityMatch(doc *entity.SimilarityDocument, fingerprints []pkg.Fingerprint, candidate entity.SimilarityCandidate, other []pkg.Fingerprint) entity.SimilarityMatch {
+	union := doc.FingerprintCount + candidate.FingerprintCount - candidate.Shared
+
+	match := entity.SimilarityMatch{
+		SubmissionA:        doc.SubmissionId,
+		SubmissionB:        candidate.SubmissionId,
+		Score:              math.Round(float64(candidate.Shared)/float64(max(union, 1))*10000) / 10000,
+		SharedFingerprints: candidate.Shared,
+	}
+
+	if match.SubmissionA < match.SubmissionB {
+		match.Spans = pkg.MatchSpans(fingerprints, other)
+	} else {
+		match.SubmissionA, match.SubmissionB = match.SubmissionB, match.SubmissionA
+		match.Spans = pkg.MatchSpans(other, fingerprints)
+	}
+
+	return match
+}
+
+// similarityText joins the written answer and the plain text files of a submission, code files are sniffed as plain text too.
+func (s *submissionService) similarityText(ctx context.Context, answer string, files []entity.SubmissionFile) string {
+	var b strings.Builder
+	b.WriteString(answer)
+
+	for _, file := range files {
+		if file.MimeType != integStorage.MimeText {
+			continue
+		}
+
+		content, err := s.readFile(ctx, file.StorageKey)
+		if err != nil {
+			log.Warn().Err(err).Str("filename", file.Filename).Msg("service::similarityText - Failed to read file")
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.Write(content)
+	}
+
+	// postgres text holds neither invalid utf-8 nor NUL bytes
+	return strings.ReplaceAll(strings.ToValidUTF8(b.String(), ""), "\x00", "")
+}
+
+// readFile reads a stored submission file back.
+func (s *submissionService) readFile(ctx context.Context, key string) ([]byte, error) {
+	body, _, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// autograde runs a new submission against the test cases of its assignment and stores the result. It runs after
+// the submission is stored, a run that cannot finish is recorded as failed so the teacher grades it by hand.
+func (s *submissionService) autograde(submissionId int, answer string, files []entity.SubmissionFile) {
+	ctx, cancel := context.WithTimeout(context.Background(), autogradeTimeout)
+	defer cancel()
+
+	result := &entity.AutogradeResult{SubmissionId: submissionId, Status: "failed", Tests: []entity.AutogradeTestResult{}}
+	defer func() {
+		// the run may have used up ctx, saving gets its own deadline
+		saveCtx, cancelSave := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancelSave()
+
+		grade, err := s.repo.SaveAutogradeResult(saveCtx, result)
+		if err != nil {
+			log.Error().Err(err).Int("submission_id", submissionId).Msg("service::autograde - Failed to save result")
+			return
+		}
+
+		// the teacher may have graded the submission while it ran
+		if grade != nil {
+			eventbus.Publish(eventbus.SubmissionGradedEvent{SubmissionId: submissionId, Grade: grade.Grade, MaxScore: grade.MaxScore})
+		}
+	}()
+
+	suite, err := s.repo.GetAutogradeSuite(ctx, submissionId)
+	if err != nil {
+		result.Output = "Automated grading is not available for this assignment"
+		return
+	}
+
+	source := s.autogradeSource(ctx, answer, files, suite.Language)
+	if source == "" {
+		result.Output = "No " + autogradeExtensions[suite.Language] + " file found in the submission"
+		return
+	}
+
+	run := &sandboxEntity.RunRequest{
+		Language:    suite.Language,
+		Source:      source,
+		Inputs:      make([]string, len(suite.Tests)),
+		TimeLimit:   time.Duration(suite.TimeLimitMs) * time.Millisecond,
+		MemoryLimit: int64(suite.MemoryMb) << 20,
+	}
+	for i, test := range suite.Tests {
+		run.Inputs[i] = test.Input
+	}
+
+	res, err := s.sandbox.Run(ctx, run)
+	if err != nil {
+		log.Error().Err(err).Int("submission_id", submissionId).Msg("service::autograde - Failed to run submission")
+		result.Output = "The submission could not be run, it will be graded by the teacher"
+		return
+	}
+
+	var score float64
+	result.Status, result.Score, result.Output = "completed", &score, res.CompileOutput
+
+	// a program that does not compile passes no test
+	if !res.Compiled {
+		return
+	}
+
+	for i, test := range suite.Tests {
+		out := res.Results[i]
+		check := entity.AutogradeTestResult{
+			TestCaseId: test.Id,
+			Passed:     !out.TimedOut && out.ExitCode == 0 && sameOutput(out.Stdout, test.ExpectedOutput),
+			Output:     out.Stdout,
+			ExitCode:   out.ExitCode,
+			TimedOut:   out.TimedOut,
+			DurationMs: int(out.Duration.Milliseconds()),
+		}
+
+		if out.Stderr != "" {
+			check.Output = strings.TrimRight(check.Output, "\n") + "\n" + out.Stderr
+		}
+
+		// postgres text holds neither invalid utf-8 nor NUL bytes
+		check.Output = strings.ReplaceAll(strings.ToValidUTF8(check.Output, ""), "\x00", "")
+
+		if check.Passed {
+			check.Points = test.Points
+			score += test.Points
+		}
+
+		result.Tests = append(result.Tests, check)
+	}
+}
+
+// autogradeSource returns the first uploaded file with the extension of the language, the written answer otherwise.
+func (s *submissionService) autogradeSource(ctx context.Context, answer string, files []entity.SubmissionFile, language string) string {
+	for _, file := range files {
+		if !strings.EqualFold(filepath.Ext(file.Filename), autogradeExtensions[language]) {
+			continue
+		}
+
+		content, err := s.readFile(ctx, file.StorageKey)
+		if err != nil {
+			log.Warn().Err(err).Str("filename", file.Filename).Msg("service::autogradeSource - Failed to read file")
+			continue
+		}
+
+		return string(content)
+	}
+
+	return answer
+}
+
+// sameOutput compares program output ignoring trailing whitespace on every line and trailing empty lines.
+func sameOutput(actual, expected string) bool {
+	normalize := func(output string) string {
+		lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t\r")
+		}
+		return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	}
+
+	return normalize(actual) == normalize(expected)
+}
+
+func (s *submissionService) GetSimilarityReport(ctx context.Context, req *entity.GetSimilarityReportRequest) (*entity.GetSimilarityReportResponse, error) {
+	creatorId, err := s.repo.GetAssignmentCreator(ctx, req.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if creatorId != req.UserId {
+		log.Warn().Any("payload", req).Msg("service::GetSimilarityReport - User is not the assignment creator")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the assignment creator can see the similarity report"))
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	rows, checked, err := s.repo.GetSimilarityReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &entity.GetSimilarityReportResponse{
+		Checked: checked,
+		Pairs:   make([]entity.SimilarityPair, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		var spans []pkg.SimilaritySpan
+		if err := json.Unmarshal(row.Spans, &spans); err != nil {
+			log.Error().Err(err).Int("submission_a", row.SubmissionA).Int("submission_b", row.SubmissionB).Msg("service::GetSimilarityReport - Failed to unmarshal spans")
+			return nil, err
+		}
+
+		pair := entity.SimilarityPair{
+			A:                  entity.SimilaritySubmission{SubmissionId: row.SubmissionA, StudentId: row.StudentA, StudentName: row.NameA, TeamName: row.TeamA},
+			B:                  entity.SimilaritySubmission{SubmissionId: row.SubmissionB, StudentId: row.StudentB, StudentName: row.NameB, TeamName: row.TeamB},
+			Score:              row.Score,
+			SharedFingerprints: row.SharedFingerprints,
+			Spans:              make([]entity.SimilaritySpan, 0, len(spans)),
+			CheckedAt:          row.CreatedAt,
+		}
+
+		for _, span := range spans {
+			pair.Spans = append(pair.Spans, entity.SimilaritySpan{
+				A: excerpt(row.ContentA, span.AStart, span.AEnd),
+				B: excerpt(row.ContentB, span.BStart, span.BEnd),
+			})
+		}
+
+		response.Pairs = append(response.Pairs, pair)
+	}
+
+	return response, nil
+}
+
+func excerpt(content string, start, end int) entity.SimilarityExcerpt {
+	start, end = min(max(start, 0), len(content)), min(max(end, 0), len(content))
+	return entity.SimilarityExcerpt{Start: start, End: end, Text: content[start:max(start, end)]}
+}
+
+// submissionClose is the moment no more submissions are accepted, peer reviews start from there. Settings carry the
+// latest deadline of any student, so reviews wait for the last extension.
+func submissionClose(settings *entity.PeerReviewSettings) time.Time {
+	if settings.AllowLate && settings.LateCutoff != nil {
+		return *settings.LateCutoff
+	}
+
+	return settings.DueDate
+}
+
+// scoreRubric checks that every criterion got exactly one of its own levels and sums their points.
+// Without a rubric the grade is taken as is, both ways the total may not exceed the max score.
+// Teachers and peer reviewers are scored the same way.
+func scoreRubric(rubric *entity.GradingRubric, requested []entity.RubricScoreRequest, grade *float64) ([]entity.RubricScore, float64, error) {
+	errs := errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid grade"))
+
+	if len(rubric.Levels) == 0 {
+		if grade == nil {
+			errs.Add("grade", "grade is required for assignments without a rubric.")
+			return nil, 0, errs
+		}
+		if *grade > rubric.MaxScore {
+			errs.Add("grade", "grade must not exceed the max score of "+strconv.FormatFloat(rubric.MaxScore, 'f', -1, 64)+".")
+			return nil, 0, errs
+		}
+
+		return []entity.RubricScore{}, *grade, nil
+	}
+
+	var (
+		levels   = make(map[int]entity.GradingRubricLevel, len(rubric.Levels))
+		criteria = make(map[int]bool)
+		scores   = make([]entity.RubricScore, 0, len(requested))
+		total    float64
+	)
+
+	for _, level := range rubric.Levels {
+		levels[level.Id] = level
+		criteria[level.CriterionId] = false
+	}
+
+	for _, score := range requested {
+		graded, ok := criteria[score.CriterionId]
+		if !ok {
+			errs.Add("scores", "criterion "+strconv.Itoa(score.CriterionId)+" is not part of the rubric.")
+			continue
+		}
+		if graded {
+			errs.Add("scores", "criterion "+strconv.Itoa(score.CriterionId)+" is graded more than once.")
+			continue
+		}
+
+		level, ok := levels[score.LevelId]
+		if !ok || level.CriterionId != score.CriterionId {
+			errs.Add("scores", "level "+strconv.Itoa(score.LevelId)+" does not belong to criterion "+strconv.Itoa(score.CriterionId)+".")
+			continue
+		}
+
+		criteria[score.CriterionId] = true
+		total += level.Points
+		scores = append(scores, entity.RubricScore{
+			CriterionId: score.CriterionId,
+			LevelId:     score.LevelId,
+			Points:      level.Points,
+			Comment:     score.Comment,
+		})
+	}
+
+	for id, graded := range criteria {
+		if !graded {
+			errs.Add("scores", "criterion "+strconv.Itoa(id)+" has no level selected.")
+		}
+	}
+
+	if errs.HasErrors() {
+		return nil, 0, errs
+	}
+
+	if total > rubric.MaxScore {
+		errs.Add("scores", "total of "+strconv.FormatFloat(total, 'f', -1, 64)+" exceeds the max score of "+strconv.FormatFloat(rubric.MaxScore, 'f', -1, 64)+".")
+		return nil, 0, errs
+	}
+
+	return scores, total, nil
+}
+
+// checkResubmission decides whether a student with an active submission may submit again.
+func checkResubmission(rules *entity.AssignmentSubmissionRules, active *entity.ActiveSubmission, now time.Time) error {
+	switch rules.ResubmissionPolicy {
+	case "until_graded":
+		if active.Status == "rated" {
+			return errmsg.NewCustomErrors(409, errmsg.WithMessage("Submission has already been graded"))
+		}
+	case "until_deadline":
+		if now.After(rules.DueDate) {
+			return errmsg.NewCustomErrors(409, errmsg.WithMessage("Resubmission is closed after the due date"))
+		}
+	default:
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Assignment has already been submitted"))
+	}
+
+	return nil
+}
+
+// latePenalty returns the started days past the due date and the penalty they add up to, capped at 100 percent.
+func latePenalty(rules *entity.AssignmentSubmissionRules, now time.Time) (int, float64, error) {
+	if !now.After(rules.DueDate) {
+		return 0, 0, nil
+	}
+
+	if !rules.AllowLate {
+		return 0, 0, errmsg.NewCustomErrors(400, errmsg.WithMessage("Assignment is past its due date"))
+	}
+
+	if rules.LateCutoff != nil && now.After(*rules.LateCutoff) {
+		return 0, 0, errmsg.NewCustomErrors(400, errmsg.WithMessage("Assignment no longer accepts late submissions"))
+	}
+
+	days := int(math.Ceil(now.Sub(rules.DueDate).Hours() / 24))
+
+	return days, math.Min(100, float64(days)*rules.LatePenaltyPercent), nil
+}
+
+// putFiles stores the files of a submission as they arrive, the files already stored are deleted again when one is
+// refused.
+func (s *submissionService) putFiles(ctx context.Context, req *entity.SubmitRequest, rules *entity.AssignmentSubmissionRules) ([]entity.SubmissionFile, error) {
+	files := make([]entity.SubmissionFile, 0)
+	if req.Files == nil {
+		return files, nil
+	}
+
+	usage, err := s.repo.GetStorageUsage(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := usage.Left()
+	for {
+		file, err := req.Files.NextFile()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			s.deleteFiles(ctx, files)
+			return nil, err
+		}
+
+		if len(files) == rules.MaxFiles {
+			s.deleteFiles(ctx, files)
+			errs := errmsg.NewCustomErrors(400, errmsg.WithMessage("Too many files"))
+			errs.Add("files", "at most "+strconv.Itoa(rules.MaxFiles)+" files are allowed.")
+			return nil, errs
+		}
+
+		uploaded, err := integStorage.PutUpload(ctx, s.storage, &storageEntity.PutUploadRequest{
+			Category: integStorage.CategorySubmission,
+			Key:      integStorage.Key(integStorage.VisibilityPrivate, "submissions", req.AssignmentId, req.UserId, pkg.SanitizeFilename(file.FileName(), true)),
+			Filename: file.FileName(),
+			Body:     file,
+			Types:    rules.AllowedFileTypes,
+			Quota:    quota,
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("filename", file.FileName()).Str("userId", req.UserId).Msg("service::putFiles - Failed to store uploaded file")
+			s.deleteFiles(ctx, files)
+			return nil, err
+		}
+
+		if quota >= 0 {
+			quota = max(quota-uploaded.Size, 0)
+		}
+
+		files = append(files, entity.SubmissionFile{
+			Filename:   file.FileName(),
+			Size:       uploaded.Size,
+			MimeType:   uploaded.ContentType,
+			StorageKey: uploaded.Key,
+		})
+	}
+}
+
+// signFiles fills in short lived urls, submission files are private to the student and the teacher.
+func (s *submissionService) signFiles(ctx context.Context, files []entity.SubmissionFile) error {
+	for i := range files {
+		url, err := s.storage.Presign(ctx, files[i].StorageKey, time.Duration(config.Envs.HackoStorage.UrlExpiry)*time.Second)
+		if err != nil {
+			return err
+		}
+
+		files[i].Url = url
+	}
+
+	return nil
+}
+
+func (s *submissionService) deleteFiles(ctx context.Context, files []entity.SubmissionFile) {
+	for _, file := range files {
+		if err := s.storage.Delete(ctx, file.StorageKey); err != nil {
+			log.Warn().Err(err).Str("storage_key", file.StorageKey).Msg("service::deleteFiles - Failed to delete object from storage")
+		}
+	}
+}
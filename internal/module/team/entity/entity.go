@@ -0,0 +1,87 @@
+package entity
+
+import "time"
+
+type TeamSettings struct {
+	ClassId     int  `json:"class_id" db:"class_id"`
+	SelfSelect  bool `json:"self_select" db:"self_select"`
+	MaxTeamSize *int `json:"max_team_size" db:"max_team_size"`
+}
+
+type UpdateTeamSettingsRequest struct {
+	UserId      string `validate:"required"`
+	ClassId     int    `json:"class_id" validate:"required"`
+	SelfSelect  bool   `json:"self_select"`
+	MaxTeamSize *int   `json:"max_team_size" validate:"omitempty,min=1"`
+}
+
+type TeamMember struct {
+	TeamId   int       `json:"-" db:"team_id"`
+	UserId   string    `json:"user_id" db:"user_id"`
+	Name     string    `json:"name" db:"name"`
+	Image    *string   `json:"image_url" db:"image_url"`
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+}
+
+type Team struct {
+	Id        int          `json:"id" db:"id"`
+	ClassId   int          `json:"class_id" db:"class_id"`
+	Name      string       `json:"name" db:"name"`
+	CreatedBy string       `json:"created_by" db:"created_by"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+	Members   []TeamMember `json:"members"`
+}
+
+type GetTeamsRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+}
+
+type GetTeamsResponse struct {
+	Settings TeamSettings `json:"settings"`
+	MyTeamId *int         `json:"my_team_id"`
+	Teams    []Team       `json:"teams"`
+}
+
+// CreateTeamRequest is used by the class creator, MemberIds must be enrolled students without a team.
+type CreateTeamRequest struct {
+	UserId    string   `validate:"required"`
+	ClassId   int      `json:"class_id" validate:"required"`
+	Name      string   `json:"name" validate:"required,max=255"`
+	MemberIds []string `json:"member_ids" validate:"dive,required,uuid"`
+}
+
+// UpdateTeamRequest renames a team and, when MemberIds is set, replaces all of its members.
+type UpdateTeamRequest struct {
+	UserId    string    `validate:"required"`
+	ClassId   int       `json:"class_id" validate:"required"`
+	TeamId    int       `json:"team_id" validate:"required"`
+	Name      *string   `json:"name" validate:"omitempty,min=1,max=255"`
+	MemberIds *[]string `json:"member_ids" validate:"omitempty,dive,required,uuid"`
+}
+
+type DeleteTeamRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+	TeamId  int    `json:"team_id" validate:"required"`
+}
+
+// CreateOwnTeamRequest is used by a student, who becomes the first member of the team.
+type CreateOwnTeamRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+	Name    string `json:"name" validate:"required,max=255"`
+}
+
+type JoinTeamRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+	TeamId  int    `json:"team_id" validate:"required"`
+}
+
+type LeaveTeamRequest struct {
+	UserId  string `validate:"required"`
+	ClassId int    `json:"class_id" validate:"required"`
+	TeamId  int    `json:"team_id" validate:"required"`
+}
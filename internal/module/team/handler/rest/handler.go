@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"hacko-app/internal/adapter"
+	"hacko-app/internal/middleware"
+	"hacko-app/internal/module/team/entity"
+	"hacko-app/internal/module/team/ports"
+	"hacko-app/internal/module/team/repository"
+	"hacko-app/internal/module/team/service"
+	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type teamHandler struct {
+	service ports.TeamService
+}
+
+func NewTeamHandler() *teamHandler {
+	var handler = new(teamHandler)
+
+	repo := repository.NewTeamRepository(adapter.Adapters.HackoPostgres)
+	teamService := service.NewTeamService(repo)
+
+	handler.service = teamService
+	return handler
+}
+
+func (h *teamHandler) Register(router fiber.Router) {
+	// user routes
+	router.Get("/class/:classId/teams", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.GetTeams)
+	router.Post("/class/:classId/teams", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.CreateOwnTeam)
+	router.Post("/class/:classId/teams/:teamId/members", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.JoinTeam)
+	router.Delete("/class/:classId/teams/:teamId/members", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.LeaveTeam)
+
+	// admin routes
+	router.Put("/teacher/class/:classId/teams/settings", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UpdateSettings)
+	router.Post("/teacher/class/:classId/teams", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.CreateTeam)
+	router.Put("/teacher/class/:classId/teams/:teamId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.UpdateTeam)
+	router.Delete("/teacher/class/:classId/teams/:teamId", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.DeleteTeam)
+}
+
+func (h *teamHandler) GetTeams(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTeamsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::GetTeams - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetTeams - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetTeams(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *teamHandler) CreateOwnTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateOwnTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateOwnTeam - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::CreateOwnTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateOwnTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateOwnTeam(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *teamHandler) JoinTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.JoinTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::JoinTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	teamId, err := strconv.Atoi(c.Params("teamId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::JoinTeam - Failed to parsing id team")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id team"))))
+	}
+
+	req.TeamId = teamId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::JoinTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.JoinTeam(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *teamHandler) LeaveTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.LeaveTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::LeaveTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	teamId, err := strconv.Atoi(c.Params("teamId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::LeaveTeam - Failed to parsing id team")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id team"))))
+	}
+
+	req.TeamId = teamId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::LeaveTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.LeaveTeam(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, "Successfully left the team"))
+}
+
+func (h *teamHandler) CreateTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateTeam - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::CreateTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateTeam(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *teamHandler) UpdateTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateTeam - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	teamId, err := strconv.Atoi(c.Params("teamId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateTeam - Failed to parsing id team")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id team"))))
+	}
+
+	req.TeamId = teamId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateTeam(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
+func (h *teamHandler) DeleteTeam(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteTeamRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteTeam - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	teamId, err := strconv.Atoi(c.Params("teamId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteTeam - Failed to parsing id team")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id team"))))
+	}
+
+	req.TeamId = teamId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::DeleteTeam - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteTeam(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, "Successfully deleted team"))
+}
+
+func (h *teamHandler) UpdateSettings(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateTeamSettingsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid request body"))))
+	}
+
+	req.UserId = l.GetUserId()
+
+	classId, err := strconv.Atoi(c.Params("classId"))
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Failed to parsing id class")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(errmsg.NewCustomErrors(400, errmsg.WithMessage("Failed to parse params id class"))))
+	}
+
+	req.ClassId = classId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateSettings - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateSettings(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
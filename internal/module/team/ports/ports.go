@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+	"hacko-app/internal/module/team/entity"
+)
+
+type TeamRepository interface {
+	// utils contract
+	GetClassAccess(ctx context.Context, classId int, userId string) (isCreator bool, isEnrolled bool, err error)
+	GetSettings(ctx context.Context, classId int) (*entity.TeamSettings, error)
+	GetTeam(ctx context.Context, classId int, teamId int) (*entity.Team, error)
+	GetTeamMembers(ctx context.Context, classId int) ([]entity.TeamMember, error)
+	HasActiveSubmissions(ctx context.Context, teamId int) (bool, error)
+
+	// users contract
+	GetTeams(ctx context.Context, classId int) ([]entity.Team, error)
+	AddMember(ctx context.Context, req *entity.JoinTeamRequest, maxTeamSize *int) error
+	RemoveMember(ctx context.Context, req *entity.LeaveTeamRequest) error
+
+	// admin contract
+	CreateTeam(ctx context.Context, classId int, name string, createdBy string, memberIds []string) (*entity.Team, error)
+	UpdateTeam(ctx context.Context, req *entity.UpdateTeamRequest) error
+	DeleteTeam(ctx context.Context, req *entity.DeleteTeamRequest) error
+	UpdateSettings(ctx context.Context, req *entity.UpdateTeamSettingsRequest) (*entity.TeamSettings, error)
+}
+
+type TeamService interface {
+	// users contract
+	GetTeams(ctx context.Context, req *entity.GetTeamsRequest) (*entity.GetTeamsResponse, error)
+	CreateOwnTeam(ctx context.Context, req *entity.CreateOwnTeamRequest) (*entity.Team, error)
+	JoinTeam(ctx context.Context, req *entity.JoinTeamRequest) (*entity.Team, error)
+	LeaveTeam(ctx context.Context, req *entity.LeaveTeamRequest) error
+
+	// admin contract
+	CreateTeam(ctx context.Context, req *entity.CreateTeamRequest) (*entity.Team, error)
+	UpdateTeam(ctx context.Context, req *entity.UpdateTeamRequest) (*entity.Team, error)
+	DeleteTeam(ctx context.Context, req *entity.DeleteTeamRequest) error
+	UpdateSettings(ctx context.Context, req *entity.UpdateTeamSettingsRequest) (*entity.TeamSettings, error)
+}
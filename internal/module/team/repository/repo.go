@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hacko-app/internal/module/team/entity"
+	"hacko-app/internal/module/team/ports"
+	"hacko-app/pkg/errmsg"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TeamRepository = &teamRepository{}
+
+var (
+	errTeamName    = errmsg.NewCustomErrors(409, errmsg.WithErrors("name", "a team with this name already exists in the class."))
+	errAlreadyTeam = errmsg.NewCustomErrors(409, errmsg.WithMessage("Student is already a member of a team in this class"))
+)
+
+type teamRepository struct {
+	db *sqlx.DB
+}
+
+func NewTeamRepository(db *sqlx.DB) *teamRepository {
+	return &teamRepository{
+		db: db,
+	}
+}
+
+func (r *teamRepository) GetClassAccess(ctx context.Context, classId int, userId string) (bool, bool, error) {
+	var isCreator, isEnrolled bool
+
+	query := `
+		SELECT
+			c.creator_class_id = $2,
+			EXISTS (
+				SELECT 1 FROM users_classes uc
+				WHERE uc.class_id = c.id AND uc.user_id = $2 AND uc.enrollment_status IN ('active', 'completed')
+			)
+		FROM class c
+		WHERE c.id = $1
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, classId, userId).Scan(&isCreator, &isEnrolled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Msg("repo::GetClassAccess - Class not found")
+			return false, false, errmsg.NewCustomErrors(404, errmsg.WithMessage("Class not found"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Str("user_id", userId).Msg("repo::GetClassAccess - Failed to query class")
+		return false, false, err
+	}
+
+	return isCreator, isEnrolled, nil
+}
+
+// GetSettings returns the defaults, teacher formed teams without a size limit, for classes without settings.
+func (r *teamRepository) GetSettings(ctx context.Context, classId int) (*entity.TeamSettings, error) {
+	var res = &entity.TeamSettings{ClassId: classId}
+
+	query := `SELECT class_id, self_select, max_team_size FROM team_settings WHERE class_id = $1`
+
+	if err := r.db.GetContext(ctx, res, query, classId); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetSettings - Failed to get team settings")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *teamRepository) UpdateSettings(ctx context.Context, req *entity.UpdateTeamSettingsRequest) (*entity.TeamSettings, error) {
+	var res = new(entity.TeamSettings)
+
+	query := `
+		INSERT INTO team_settings (class_id, self_select, max_team_size)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (class_id) DO UPDATE SET self_select = EXCLUDED.self_select, max_team_size = EXCLUDED.max_team_size
+		RETURNING class_id, self_select, max_team_size
+	`
+
+	if err := r.db.QueryRowxContext(ctx, query, req.ClassId, req.SelfSelect, req.MaxTeamSize).StructScan(res); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateSettings - Failed to save team settings")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *teamRepository) GetTeams(ctx context.Context, classId int) ([]entity.Team, error) {
+	var res = make([]entity.Team, 0)
+
+	query := `
+		SELECT id, class_id, name, created_by, created_at, updated_at
+		FROM teams
+		WHERE class_id = $1
+		ORDER BY name, id
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetTeams - Failed to get teams")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *teamRepository) GetTeam(ctx context.Context, classId int, teamId int) (*entity.Team, error) {
+	var res = new(entity.Team)
+
+	query := `
+		SELECT id, class_id, name, created_by, created_at, updated_at
+		FROM teams
+		WHERE id = $1 AND class_id = $2
+	`
+
+	if err := r.db.GetContext(ctx, res, query, teamId, classId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Int("class_id", classId).Int("team_id", teamId).Msg("repo::GetTeam - Team not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Team not found"))
+		}
+		log.Error().Err(err).Int("class_id", classId).Int("team_id", teamId).Msg("repo::GetTeam - Failed to get team")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetTeamMembers returns the members of every team in the class.
+func (r *teamRepository) GetTeamMembers(ctx context.Context, classId int) ([]entity.TeamMember, error) {
+	var res = make([]entity.TeamMember, 0)
+
+	query := `
+		SELECT tm.team_id, tm.user_id, u.name, u.image_url, tm.joined_at
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.class_id = $1
+		ORDER BY tm.joined_at, u.name
+	`
+
+	if err := r.db.SelectContext(ctx, &res, query, classId); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::GetTeamMembers - Failed to get team members")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// HasActiveSubmissions reports whether the team has handed in any group assignment.
+func (r *teamRepository) HasActiveSubmissions(ctx context.Context, teamId int) (bool, error) {
+	var exists bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM submissions WHERE team_id = $1 AND is_active)`
+
+	if err := r.db.QueryRowContext(ctx, query, teamId).Scan(&exists); err != nil {
+		log.Error().Err(err).Int("team_id", teamId).Msg("repo::HasActiveSubmissions - Failed to check submissions")
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *teamRepository) CreateTeam(ctx context.Context, classId int, name string, createdBy string, memberIds []string) (res *entity.Team, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::CreateTeam - Failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Int("class_id", classId).Msg("repo::CreateTeam - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	query := `
+		INSERT INTO teams (class_id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, class_id, name, created_by, created_at, updated_at
+	`
+
+	var team entity.Team
+
+	if err = tx.QueryRowxContext(ctx, query, classId, name, createdBy).StructScan(&team); err != nil {
+		if isUniqueViolation(err, "teams_class_id_name_key") {
+			log.Warn().Int("class_id", classId).Str("name", name).Msg("repo::CreateTeam - Team name already taken")
+			err = errTeamName
+			return nil, err
+		}
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::CreateTeam - Failed to insert team")
+		return nil, err
+	}
+
+	if err = insertMembers(ctx, tx, classId, team.Id, memberIds); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Int("class_id", classId).Msg("repo::CreateTeam - Failed to commit transaction")
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+func (r *teamRepository) UpdateTeam(ctx context.Context, req *entity.UpdateTeamRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateTeam - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdateTeam - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	query := `
+		UPDATE teams
+		SET name = COALESCE($1, name), updated_at = NOW()
+		WHERE id = $2 AND class_id = $3
+	`
+
+	result, err := tx.ExecContext(ctx, query, req.Name, req.TeamId, req.ClassId)
+	if err != nil {
+		if isUniqueViolation(err, "teams_class_id_name_key") {
+			log.Warn().Any("payload", req).Msg("repo::UpdateTeam - Team name already taken")
+			err = errTeamName
+			return err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateTeam - Failed to update team")
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Warn().Any("payload", req).Msg("repo::UpdateTeam - Team not found")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Team not found"))
+		return err
+	}
+
+	if req.MemberIds != nil {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = $1`, req.TeamId); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateTeam - Failed to clear team members")
+			return err
+		}
+
+		if err = insertMembers(ctx, tx, req.ClassId, req.TeamId, *req.MemberIds); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateTeam - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *teamRepository) DeleteTeam(ctx context.Context, req *entity.DeleteTeamRequest) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM teams WHERE id = $1 AND class_id = $2`, req.TeamId, req.ClassId)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			log.Warn().Any("payload", req).Msg("repo::DeleteTeam - Team has submissions")
+			return errmsg.NewCustomErrors(409, errmsg.WithMessage("Team has submissions and can't be deleted"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteTeam - Failed to delete team")
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteTeam - Team not found")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Team not found"))
+	}
+
+	return nil
+}
+
+// AddMember adds a student to a team, the team row is locked so concurrent joins can't exceed maxTeamSize.
+func (r *teamRepository) AddMember(ctx context.Context, req *entity.JoinTeamRequest, maxTeamSize *int) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddMember - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::AddMember - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	var size int
+
+	query := `
+		SELECT (SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id)
+		FROM teams t
+		WHERE t.id = $1 AND t.class_id = $2
+		FOR UPDATE
+	`
+
+	if err = tx.QueryRowContext(ctx, query, req.TeamId, req.ClassId).Scan(&size); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn().Any("payload", req).Msg("repo::AddMember - Team not found")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Team not found"))
+			return err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddMember - Failed to lock team")
+		return err
+	}
+
+	if maxTeamSize != nil && size >= *maxTeamSize {
+		log.Warn().Any("payload", req).Msg("repo::AddMember - Team is full")
+		err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Team is full"))
+		return err
+	}
+
+	if err = insertMembers(ctx, tx, req.ClassId, req.TeamId, []string{req.UserId}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddMember - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMember takes a student out of a team, a team left without members is removed as well.
+func (r *teamRepository) RemoveMember(ctx context.Context, req *entity.LeaveTeamRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RemoveMember - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::RemoveMember - Failed to rollback transaction")
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = $1 AND class_id = $2 AND user_id = $3`, req.TeamId, req.ClassId, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RemoveMember - Failed to remove team member")
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Warn().Any("payload", req).Msg("repo::RemoveMember - User is not a member of the team")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("You are not a member of this team"))
+		return err
+	}
+
+	query := `
+		DELETE FROM teams t
+		WHERE t.id = $1
+			AND NOT EXISTS (SELECT 1 FROM team_members tm WHERE tm.team_id = t.id)
+			AND NOT EXISTS (SELECT 1 FROM submissions s WHERE s.team_id = t.id)
+	`
+
+	if _, err = tx.ExecContext(ctx, query, req.TeamId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RemoveMember - Failed to remove empty team")
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::RemoveMember - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// insertMembers adds the users to a team, each of them has to be enrolled in the class and not be in another team.
+func insertMembers(ctx context.Context, tx *sqlx.Tx, classId int, teamId int, memberIds []string) error {
+	query := `
+		INSERT INTO team_members (team_id, class_id, user_id)
+		SELECT $1, uc.class_id, uc.user_id
+		FROM users_classes uc
+		WHERE uc.class_id = $2 AND uc.user_id = $3 AND uc.enrollment_status IN ('active', 'completed')
+	`
+
+	errs := errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid team members"))
+
+	for _, userId := range memberIds {
+		result, err := tx.ExecContext(ctx, query, teamId, classId, userId)
+		if err != nil {
+			if isUniqueViolation(err, "team_members_class_id_user_id_key") || isUniqueViolation(err, "team_members_pkey") {
+				log.Warn().Int("team_id", teamId).Str("user_id", userId).Msg("repo::insertMembers - Student already in a team")
+				return errAlreadyTeam
+			}
+			log.Error().Err(err).Int("team_id", teamId).Str("user_id", userId).Msg("repo::insertMembers - Failed to insert team member")
+			return err
+		}
+
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			errs.Add("member_ids", "user "+userId+" is not enrolled in the class.")
+		}
+	}
+
+	if errs.HasErrors() {
+		log.Warn().Int("team_id", teamId).Strs("member_ids", memberIds).Msg("repo::insertMembers - Members not enrolled")
+		return errs
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == constraint
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"hacko-app/internal/module/team/entity"
+	"hacko-app/internal/module/team/ports"
+	"hacko-app/pkg/errmsg"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TeamService = &teamService{}
+
+type teamService struct {
+	repo ports.TeamRepository
+}
+
+func NewTeamService(repo ports.TeamRepository) *teamService {
+	return &teamService{
+		repo: repo,
+	}
+}
+
+func (s *teamService) GetTeams(ctx context.Context, req *entity.GetTeamsRequest) (*entity.GetTeamsResponse, error) {
+	isCreator, isEnrolled, err := s.repo.GetClassAccess(ctx, req.ClassId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isCreator && !isEnrolled {
+		log.Warn().Any("payload", req).Msg("service::GetTeams - User is not part of the class")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("You are not enrolled in this class"))
+	}
+
+	settings, err := s.repo.GetSettings(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repo.GetTeams(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetTeamMembers(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &entity.GetTeamsResponse{
+		Settings: *settings,
+		Teams:    withMembers(teams, members),
+	}
+
+	for _, member := range members {
+		if member.UserId == req.UserId {
+			teamId := member.TeamId
+			response.MyTeamId = &teamId
+		}
+	}
+
+	return response, nil
+}
+
+func (s *teamService) CreateOwnTeam(ctx context.Context, req *entity.CreateOwnTeamRequest) (*entity.Team, error) {
+	if err := s.checkSelfSelect(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	team, err := s.repo.CreateTeam(ctx, req.ClassId, req.Name, req.UserId, []string{req.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getTeam(ctx, req.ClassId, team.Id)
+}
+
+func (s *teamService) JoinTeam(ctx context.Context, req *entity.JoinTeamRequest) (*entity.Team, error) {
+	if err := s.checkSelfSelect(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkUnlocked(ctx, req.TeamId); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetSettings(ctx, req.ClassId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddMember(ctx, req, settings.MaxTeamSize); err != nil {
+		return nil, err
+	}
+
+	return s.getTeam(ctx, req.ClassId, req.TeamId)
+}
+
+func (s *teamService) LeaveTeam(ctx context.Context, req *entity.LeaveTeamRequest) error {
+	if err := s.checkSelfSelect(ctx, req.ClassId, req.UserId); err != nil {
+		return err
+	}
+
+	if err := s.checkUnlocked(ctx, req.TeamId); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveMember(ctx, req)
+}
+
+// CreateTeam lets the class creator form a team, the size limit only applies to teams formed by students.
+func (s *teamService) CreateTeam(ctx context.Context, req *entity.CreateTeamRequest) (*entity.Team, error) {
+	if err := s.checkCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	team, err := s.repo.CreateTeam(ctx, req.ClassId, req.Name, req.UserId, req.MemberIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getTeam(ctx, req.ClassId, team.Id)
+}
+
+// UpdateTeam may change the members of a team that already submitted, the grades of those
+// submissions stay with the members the team had when it submitted.
+func (s *teamService) UpdateTeam(ctx context.Context, req *entity.UpdateTeamRequest) (*entity.Team, error) {
+	if err := s.checkCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTeam(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.getTeam(ctx, req.ClassId, req.TeamId)
+}
+
+func (s *teamService) DeleteTeam(ctx context.Context, req *entity.DeleteTeamRequest) error {
+	if err := s.checkCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTeam(ctx, req)
+}
+
+func (s *teamService) UpdateSettings(ctx context.Context, req *entity.UpdateTeamSettingsRequest) (*entity.TeamSettings, error) {
+	if err := s.checkCreator(ctx, req.ClassId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateSettings(ctx, req)
+}
+
+func (s *teamService) getTeam(ctx context.Context, classId int, teamId int) (*entity.Team, error) {
+	team, err := s.repo.GetTeam(ctx, classId, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetTeamMembers(ctx, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &withMembers([]entity.Team{*team}, members)[0], nil
+}
+
+func (s *teamService) checkCreator(ctx context.Context, classId int, userId string) error {
+	isCreator, _, err := s.repo.GetClassAccess(ctx, classId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !isCreator {
+		log.Warn().Int("class_id", classId).Str("user_id", userId).Msg("service::checkCreator - User is not the class creator")
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("Only the class creator can manage teams"))
+	}
+
+	return nil
+}
+
+// checkSelfSelect allows enrolled students to manage their own team when the class lets them.
+func (s *teamService) checkSelfSelect(ctx context.Context, classId int, userId string) error {
+	_, isEnrolled, err := s.repo.GetClassAccess(ctx, classId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !isEnrolled {
+		log.Warn().Int("class_id", classId).Str("user_id", userId).Msg("service::checkSelfSelect - User is not enrolled")
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("You are not enrolled in this class"))
+	}
+
+	settings, err := s.repo.GetSettings(ctx, classId)
+	if err != nil {
+		return err
+	}
+
+	if !settings.SelfSelect {
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("Teams in this class are formed by the teacher"))
+	}
+
+	return nil
+}
+
+// checkUnlocked keeps students from joining or leaving a team once it submitted a group assignment.
+func (s *teamService) checkUnlocked(ctx context.Context, teamId int) error {
+	locked, err := s.repo.HasActiveSubmissions(ctx, teamId)
+	if err != nil {
+		return err
+	}
+
+	if locked {
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Team has already submitted, ask the teacher to change its members"))
+	}
+
+	return nil
+}
+
+func withMembers(teams []entity.Team, members []entity.TeamMember) []entity.Team {
+	byTeam := make(map[int][]entity.TeamMember, len(teams))
+	for _, member := range members {
+		byTeam[member.TeamId] = append(byTeam[member.TeamId], member)
+	}
+
+	for i := range teams {
+		teams[i].Members = byTeam[teams[i].Id]
+		if teams[i].Members == nil {
+			teams[i].Members = []entity.TeamMember{}
+		}
+	}
+
+	return teams
+}
@@ -1,5 +1,11 @@
 package entity
 
+import (
+	"hacko-app/pkg/types"
+	"mime/multipart"
+	"time"
+)
+
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Name     string `json:"name" validate:"required"`
@@ -37,10 +43,34 @@ type ProfileRequest struct {
 }
 
 type ProfileResponse struct {
-	Id    string `json:"id" db:"id"`
-	Name  string `json:"name" db:"name"`
-	Email string `json:"email" db:"email"`
-	Role  string `json:"-" db:"role"`
+	Id            string              `json:"id" db:"id"`
+	Name          string              `json:"name" db:"name"`
+	Email         string              `json:"email" db:"email"`
+	Role          string              `json:"-" db:"role"`
+	ImageUrl      *string             `json:"image_url" db:"image_url"`
+	ImageVariants types.ImageVariants `json:"image_variants" db:"image_variants"`
+	Badges        []Badge             `json:"badges" db:"-"`
+}
+
+// Badge is an achievement the user earned.
+type Badge struct {
+	Id          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	Icon        string    `json:"icon" db:"icon"`
+	AwardedAt   time.Time `json:"awarded_at" db:"awarded_at"`
+}
+
+type UploadAvatarRequest struct {
+	UserId string          `validate:"required"`
+	File   *multipart.Part `form:"image" validate:"required"` // read as it arrives
+}
+
+type UpdateAvatarRequest struct {
+	UserId        string
+	ImageUrl      string
+	ImageVariants types.ImageVariants
 }
 
 type UserPayload struct {
@@ -5,6 +5,7 @@ import (
 	"hacko-app/internal/adapter"
 	"hacko-app/internal/infrastructure/config"
 	integOauth "hacko-app/internal/integration/oauth2google"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/middleware"
 	"hacko-app/internal/module/user/entity"
 	"time"
@@ -32,7 +33,8 @@ func NewUserHandler(o integOauth.Oauth2googleContract) *userHandler {
 	var handler = new(userHandler)
 
 	repo := repository.NewUserRepository(adapter.Adapters.HackoPostgres)
-	service := service.NewUserService(repo, o)
+	storage := integStorage.NewStorageIntegration()
+	service := service.NewUserService(repo, o, storage)
 
 	handler.integration = o
 
@@ -54,6 +56,7 @@ func (h *userHandler) Register(router fiber.Router) {
 	// route user service
 	router.Get("/profile", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "teacher"}), h.profile)
 	router.Get("/profile/:user_id", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.profileByUserId)
+	router.Put("/profile/avatar", middleware.AuthMiddleware, middleware.AuthRole([]string{"user", "admin", "teacher"}), h.uploadAvatar).Name(middleware.UploadRoute(integStorage.CategoryAvatar))
 }
 
 func (h *userHandler) register(c *fiber.Ctx) error {
@@ -178,6 +181,40 @@ func (h *userHandler) profile(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
 }
 
+func (h *userHandler) uploadAvatar(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UploadAvatarRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+
+	file, err := middleware.FormFile(c, "image")
+	if err != nil {
+		log.Warn().Err(err).Msg("handler::uploadAvatar - Failed to get image from form")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	req.File = file
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::uploadAvatar - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UploadAvatar(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
+
 func (h *userHandler) oauthGoogleUrl(c *fiber.Ctx) error {
 	referer := c.Get("Referer")
 	if referer == "" {
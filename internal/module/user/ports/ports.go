@@ -4,6 +4,7 @@ import (
 	"context"
 	oauthgoogleent "hacko-app/internal/integration/oauth2google/entity"
 	"hacko-app/internal/module/user/entity"
+	"hacko-app/pkg/types"
 )
 
 type UserRepository interface {
@@ -11,8 +12,11 @@ type UserRepository interface {
 	RegisterByGoogle(ctx context.Context, req *entity.RegisterByGoogleRequest) (*entity.RegisterResponse, error)
 	FindByEmail(ctx context.Context, email string) (*entity.UserResult, error)
 	FindById(ctx context.Context, id string) (*entity.ProfileResponse, error)
+	GetBadges(ctx context.Context, userId string) ([]entity.Badge, error)
 	UpdateRefreshToken(ctx context.Context, userId, refreshToken string) error
 	FindRefreshToken(ctx context.Context, refreshToken string) (*entity.UserPayload, error) 
+	UpdateAvatar(ctx context.Context, req *entity.UpdateAvatarRequest) (types.ImageVariants, error)
+	GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error)
 }
 type UserService interface {
 	Register(ctx context.Context, req *entity.RegisterRequest) (*entity.RegisterResponse, error)
@@ -21,4 +25,5 @@ type UserService interface {
 	GetOauthGoogleUrl(ctx context.Context) (string, error)
 	LoginGoogle(ctx context.Context, req *oauthgoogleent.UserInfoResponse) (*entity.LoginResponse, error)
 	RefreshTokenService(ctx context.Context, refreshToken string) (string, error)
+	UploadAvatar(ctx context.Context, req *entity.UploadAvatarRequest) (*entity.ProfileResponse, error)
 }
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	storageRepo "hacko-app/internal/module/storage/repository"
 	"hacko-app/internal/module/user/entity"
 	"hacko-app/internal/module/user/ports"
 	"hacko-app/pkg/errmsg"
+	"hacko-app/pkg/types"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -147,7 +149,9 @@ func (r *userRepository) FindById(ctx context.Context, id string) (*entity.Profi
 		u.id,
 		u.role,
 		u.name,
-		u.email
+		u.email,
+		u.image_url,
+		u.image_variants
 	FROM
 		users u
 	WHERE
@@ -168,6 +172,36 @@ func (r *userRepository) FindById(ctx context.Context, id string) (*entity.Profi
 	return res, nil
 }
 
+// GetBadges returns the achievements the user earned, latest first.
+func (r *userRepository) GetBadges(ctx context.Context, userId string) ([]entity.Badge, error) {
+	var res = make([]entity.Badge, 0)
+
+	query := `
+	SELECT
+		a.id,
+		a.code,
+		a.title,
+		a.description,
+		a.icon,
+		ua.awarded_at
+	FROM
+		user_achievements ua
+	JOIN
+		achievements a ON a.id = ua.achievement_id
+	WHERE
+		ua.user_id = ?
+	ORDER BY
+		ua.awarded_at DESC, a.id
+`
+
+	if err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), userId); err != nil {
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetBadges - Failed to get badges")
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (r *userRepository) UpdateRefreshToken(ctx context.Context, userId, refreshToken string) error {
 	query := `
 		UPDATE users
@@ -210,3 +244,37 @@ func (r *userRepository) FindRefreshToken(ctx context.Context, refreshToken stri
 
 	return userPayload, nil
 }
+
+// UpdateAvatar sets the new avatar and returns the variants it replaced so they can be removed from storage.
+func (r *userRepository) UpdateAvatar(ctx context.Context, req *entity.UpdateAvatarRequest) (types.ImageVariants, error) {
+	var old types.ImageVariants
+
+	query := `
+	UPDATE users u
+	SET
+		image_url = ?,
+		image_variants = ?,
+		updated_at = NOW()
+	FROM (SELECT id, image_variants FROM users WHERE id = ? FOR UPDATE) prev
+	WHERE u.id = prev.id
+	RETURNING prev.image_variants
+`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.ImageUrl, req.ImageVariants, req.UserId).Scan(&old)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Str("id", req.UserId).Msg("repo::UpdateAvatar - User not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+		}
+
+		log.Error().Err(err).Str("id", req.UserId).Msg("repo::UpdateAvatar - Failed to update avatar")
+		return nil, err
+	}
+
+	return old, nil
+}
+
+// GetStorageUsage is the quota check of the storage module.
+func (r *userRepository) GetStorageUsage(ctx context.Context, userId string) (*types.StorageUsage, error) {
+	return storageRepo.GetStorageUsage(ctx, r.db, userId)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	integOauth "hacko-app/internal/integration/oauth2google"
 	oauthgoogleent "hacko-app/internal/integration/oauth2google/entity"
+	integStorage "hacko-app/internal/integration/storage"
 	"hacko-app/internal/module/user/entity"
 	"hacko-app/internal/module/user/ports"
 	"hacko-app/pkg"
@@ -18,17 +19,26 @@ import (
 var _ ports.UserService = &userService{}
 
 type userService struct {
-	repo ports.UserRepository
-	o    integOauth.Oauth2googleContract
+	repo    ports.UserRepository
+	o       integOauth.Oauth2googleContract
+	storage integStorage.Storage
 }
 
-func NewUserService(repo ports.UserRepository, o integOauth.Oauth2googleContract) *userService {
+func NewUserService(repo ports.UserRepository, o integOauth.Oauth2googleContract, storage integStorage.Storage) *userService {
 	return &userService{
-		repo: repo,
-		o:    o,
+		repo:    repo,
+		o:       o,
+		storage: storage,
 	}
 }
 
+// avatarVariants are the sizes generated for every uploaded avatar, "medium" doubles as image_url.
+var avatarVariants = []pkg.ImageVariantSpec{
+	{Name: "small", Width: 64, Height: 64},
+	{Name: "medium", Width: 256, Height: 256},
+	{Name: "large", Width: 512, Height: 512},
+}
+
 func (s *userService) Register(ctx context.Context, req *entity.RegisterRequest) (*entity.RegisterResponse, error) {
 
 	hashed, err := pkg.HashPassword(req.Password)
@@ -99,6 +109,11 @@ func (s *userService) Profile(ctx context.Context, req *entity.ProfileRequest) (
 		return nil, err
 	}
 
+	user.Badges, err = s.repo.GetBadges(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
@@ -203,3 +218,45 @@ func (s *userService) RefreshTokenService(ctx context.Context, refreshToken stri
 
 	return accessToken, nil
 }
+
+func (s *userService) UploadAvatar(ctx context.Context, req *entity.UploadAvatarRequest) (*entity.ProfileResponse, error) {
+	data, _, err := integStorage.ReadUpload(integStorage.CategoryAvatar, req.File.FileName(), req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetStorageUsage(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !usage.Allows(int64(len(data))) {
+		log.Warn().Str("userId", req.UserId).Int64("used", usage.UsedBytes).Int64("quota", usage.QuotaBytes).Msg("service::UploadAvatar - Storage quota exceeded")
+		return nil, integStorage.ErrQuotaExceeded
+	}
+
+	images, err := pkg.ProcessImage(data, avatarVariants)
+	if err != nil {
+		log.Warn().Err(err).Str("userId", req.UserId).Msg("service::UploadAvatar - Failed to process image")
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "image could not be processed."))
+	}
+
+	variants, err := integStorage.PutImageVariants(ctx, s.storage, integStorage.Key(integStorage.VisibilityPublic, "avatars", req.UserId), images)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := s.repo.UpdateAvatar(ctx, &entity.UpdateAvatarRequest{
+		UserId:        req.UserId,
+		ImageUrl:      variants["medium"].Url,
+		ImageVariants: variants,
+	})
+	if err != nil {
+		integStorage.DeleteImageVariants(ctx, s.storage, variants)
+		return nil, err
+	}
+
+	integStorage.DeleteImageVariants(ctx, s.storage, old)
+
+	return s.Profile(ctx, &entity.ProfileRequest{UserId: req.UserId})
+}
@@ -2,12 +2,19 @@ package route
 
 import (
 	integration "hacko-app/internal/integration/oauth2google"
+	restAchievement "hacko-app/internal/module/achievement/handler/rest"
+	restAnalytics "hacko-app/internal/module/analytics/handler/rest"
 	restAssignment "hacko-app/internal/module/assignment/handler/rest"
+	restCertificate "hacko-app/internal/module/certificate/handler/rest"
 	restClass "hacko-app/internal/module/class/handler/rest"
+	restDashboard "hacko-app/internal/module/dashboard/handler/rest"
+	restGradebook "hacko-app/internal/module/gradebook/handler/rest"
 	restMaterials "hacko-app/internal/module/materials/handler/rest"
 	restModules "hacko-app/internal/module/modules/handler/rest"
 	restQuiz "hacko-app/internal/module/quiz/handler/rest"
+	restStorage "hacko-app/internal/module/storage/handler/rest"
 	restSubmission "hacko-app/internal/module/submission/handler/rest"
+	restTeam "hacko-app/internal/module/team/handler/rest"
 	restUser "hacko-app/internal/module/user/handler/rest"
 	"hacko-app/pkg/response"
 
@@ -28,6 +35,13 @@ func SetupRoutes(app *fiber.App) {
 	restAssignment.NewAssignmentHandler().Register(api)
 	restSubmission.NewSubmissionHandler().Register(api)
 	restQuiz.NewQuizHandler().Register(api)
+	restGradebook.NewGradebookHandler().Register(api)
+	restAnalytics.NewAnalyticsHandler().Register(api)
+	restTeam.NewTeamHandler().Register(api)
+	restDashboard.NewDashboardHandler().Register(api)
+	restCertificate.NewCertificateHandler().Register(api)
+	restAchievement.NewAchievementHandler().Register(api)
+	restStorage.NewStorageHandler().Register(app.Group("/api/storage"))
 
 	// fallback route
 	app.Use(func(c *fiber.Ctx) error {
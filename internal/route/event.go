@@ -0,0 +1,11 @@
+package route
+
+import (
+	eventAchievement "hacko-app/internal/module/achievement/handler/event"
+	"hacko-app/pkg/eventbus"
+)
+
+// SetupEvents subscribes the modules reacting to domain events, it runs before any request can raise one.
+func SetupEvents() {
+	eventAchievement.NewAchievementHandler().Subscribe(eventbus.Default)
+}
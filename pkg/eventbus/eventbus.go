@@ -0,0 +1,114 @@
+// Package eventbus delivers the domain events raised by one module to the modules reacting to them, so neither
+// has to import the other.
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event is anything published on a bus, subscribers receive it by its name.
+type Event interface {
+	Name() string
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+// handlerTimeout bounds a single handler run.
+const handlerTimeout = 30 * time.Second
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	slots    chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a bus running at most workers handlers at the same time.
+func New(workers int) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		slots:    make(chan struct{}, workers),
+	}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish hands the event to its subscribers in the background, after the change that raised it is committed.
+// Handlers get a context of their own since the one of a request is recycled once it is answered, their failures
+// are logged and never reach the publisher.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := slices.Clone(b.handlers[event.Name()])
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go b.run(event, handler)
+	}
+}
+
+// Go runs work in the background that has to finish before shutdown, Wait waits for it as for the handlers. Unlike
+// a handler the work is not bounded by the workers of the bus, it brings its own limits and deadline.
+func (b *Bus) Go(name string, work func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Any("panic", r).Str("work", name).Msg("eventbus::Go - Work panicked")
+			}
+		}()
+
+		work()
+	}()
+}
+
+// Wait blocks until every published event is handled and every work started with Go is done.
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+func (b *Bus) run(event Event, handler Handler) {
+	defer b.wg.Done()
+
+	b.slots <- struct{}{}
+	defer func() { <-b.slots }()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Any("panic", r).Str("event", event.Name()).Msg("eventbus::run - Handler panicked")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), handlerTimeout)
+	defer cancel()
+
+	if err := handler(ctx, event); err != nil {
+		log.Error().Err(err).Str("event", event.Name()).Any("payload", event).Msg("eventbus::run - Handler failed")
+	}
+}
+
+// Default is the bus of the application.
+var Default = New(8)
+
+func Subscribe(name string, handler Handler) {
+	Default.Subscribe(name, handler)
+}
+
+func Publish(event Event) {
+	Default.Publish(event)
+}
+
+func Go(name string, work func()) {
+	Default.Go(name, work)
+}
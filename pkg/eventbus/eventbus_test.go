@@ -0,0 +1,89 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	var (
+		bus     = New(2)
+		handled atomic.Int32
+	)
+
+	for range 3 {
+		bus.Subscribe(QuizSubmitted, func(ctx context.Context, event Event) error {
+			e, ok := event.(QuizSubmittedEvent)
+			assert.True(t, ok)
+			assert.Equal(t, 7, e.QuizId)
+
+			handled.Add(1)
+			return nil
+		})
+	}
+	bus.Subscribe(ModuleProgressed, func(ctx context.Context, event Event) error {
+		t.Error("handler of another event called")
+		return nil
+	})
+
+	bus.Publish(QuizSubmittedEvent{UserId: "user", QuizId: 7})
+	bus.Wait()
+
+	assert.Equal(t, int32(3), handled.Load())
+}
+
+func TestPublishFailingHandler(t *testing.T) {
+	var (
+		bus     = New(1)
+		handled atomic.Int32
+	)
+
+	bus.Subscribe(SubmissionGraded, func(ctx context.Context, event Event) error {
+		return errors.New("failed")
+	})
+	bus.Subscribe(SubmissionGraded, func(ctx context.Context, event Event) error {
+		panic("boom")
+	})
+	bus.Subscribe(SubmissionGraded, func(ctx context.Context, event Event) error {
+		handled.Add(1)
+		return nil
+	})
+
+	// failures are logged, the other handlers still run
+	assert.NotPanics(t, func() {
+		bus.Publish(SubmissionGradedEvent{SubmissionId: 1})
+		bus.Wait()
+	})
+	assert.Equal(t, int32(1), handled.Load())
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	bus := New(1)
+
+	bus.Publish(ModuleProgressedEvent{UserId: "user", ModuleId: 1})
+	bus.Wait()
+}
+
+func TestGo(t *testing.T) {
+	var (
+		bus  = New(1)
+		done atomic.Int32
+	)
+
+	// the work does not wait for the workers of the bus
+	for range 3 {
+		bus.Go("test", func() {
+			done.Add(1)
+		})
+	}
+	bus.Go("test", func() {
+		panic("boom")
+	})
+	bus.Wait()
+
+	assert.Equal(t, int32(3), done.Load())
+}
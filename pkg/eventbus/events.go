@@ -0,0 +1,35 @@
+package eventbus
+
+const (
+	ModuleProgressed = "module.progressed"
+	QuizSubmitted    = "quiz.submitted"
+	SubmissionGraded = "submission.graded"
+)
+
+// ModuleProgressedEvent is raised when a student tracks a module, or watches one of its videos far enough to move
+// the module forward or for the first time that day. Status is the progress of the module afterwards.
+type ModuleProgressedEvent struct {
+	UserId   string
+	ModuleId int
+	Status   string
+}
+
+func (ModuleProgressedEvent) Name() string { return ModuleProgressed }
+
+type QuizSubmittedEvent struct {
+	UserId   string
+	QuizId   int
+	Score    float64
+	MaxScore float64
+}
+
+func (QuizSubmittedEvent) Name() string { return QuizSubmitted }
+
+// SubmissionGradedEvent is raised when a teacher grades a submission, for a team it covers every member.
+type SubmissionGradedEvent struct {
+	SubmissionId int
+	Grade        float64
+	MaxScore     float64
+}
+
+func (SubmissionGradedEvent) Name() string { return SubmissionGraded }
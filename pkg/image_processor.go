@@ -0,0 +1,264 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoders used by image.Decode
+	"image/jpeg"
+	_ "image/png"
+	"math"
+)
+
+const (
+	imageVariantQuality = 85
+	// MaxImagePixels bounds width×height of the images ProcessImage decodes, a small file can declare a huge image
+	// and every pixel takes memory once decoded.
+	MaxImagePixels = 40_000_000
+)
+
+var (
+	ErrImageDecode   = errors.New("image: unsupported or corrupt image")
+	ErrImageTooLarge = errors.New("image: dimensions are too large")
+)
+
+// ImageVariantSpec describes one output size. When both Width and Height are set the
+// image is center cropped to that aspect ratio, with only Width it keeps its aspect ratio.
+// Images are never upscaled.
+type ImageVariantSpec struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+type ProcessedImage struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// ProcessImage decodes data unless it is larger than MaxImagePixels, applies the EXIF orientation and re-encodes every variant as JPEG.
+// Re-encoding drops all metadata of the original, EXIF included.
+func ProcessImage(data []byte, specs []ImageVariantSpec) ([]ProcessedImage, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageDecode
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxImagePixels/cfg.Height {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageDecode
+	}
+
+	img := orient(toRGBA(src), jpegOrientation(data))
+
+	result := make([]ProcessedImage, 0, len(specs))
+	for _, spec := range specs {
+		variant := resize(img, spec)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, variant, &jpeg.Options{Quality: imageVariantQuality}); err != nil {
+			return nil, err
+		}
+
+		result = append(result, ProcessedImage{
+			Name:        spec.Name,
+			Width:       variant.Bounds().Dx(),
+			Height:      variant.Bounds().Dy(),
+			ContentType: "image/jpeg",
+			Data:        buf.Bytes(),
+		})
+	}
+
+	return result, nil
+}
+
+// toRGBA flattens the image on a white background, JPEG has no alpha channel.
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+
+	return dst
+}
+
+func resize(src *image.RGBA, spec ImageVariantSpec) *image.RGBA {
+	var (
+		w, h   = src.Bounds().Dx(), src.Bounds().Dy()
+		crop   = image.Rect(0, 0, w, h)
+		cw, ch = w, h
+	)
+
+	if spec.Width > 0 && spec.Height > 0 {
+		target := float64(spec.Width) / float64(spec.Height)
+		if float64(w)/float64(h) > target {
+			cw = int(math.Round(float64(h) * target))
+		} else {
+			ch = int(math.Round(float64(w) / target))
+		}
+		x, y := (w-cw)/2, (h-ch)/2
+		crop = image.Rect(x, y, x+cw, y+ch)
+	}
+
+	scale := 1.0
+	if spec.Width > 0 && spec.Width < cw {
+		scale = float64(spec.Width) / float64(cw)
+	}
+
+	dw := max(1, int(math.Round(float64(cw)*scale)))
+	dh := max(1, int(math.Round(float64(ch)*scale)))
+
+	return boxResample(src, crop, dw, dh)
+}
+
+// boxResample averages every source pixel covered by a destination pixel.
+func boxResample(src *image.RGBA, crop image.Rectangle, dw, dh int) *image.RGBA {
+	var (
+		dst = image.NewRGBA(image.Rect(0, 0, dw, dh))
+		cw  = crop.Dx()
+		ch  = crop.Dy()
+	)
+
+	for dy := 0; dy < dh; dy++ {
+		y0 := crop.Min.Y + dy*ch/dh
+		y1 := max(y0+1, crop.Min.Y+(dy+1)*ch/dh)
+
+		for dx := 0; dx < dw; dx++ {
+			x0 := crop.Min.X + dx*cw/dw
+			x1 := max(x0+1, crop.Min.X+(dx+1)*cw/dw)
+
+			var r, g, b, a, n uint32
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// orient rotates and flips the image so it is displayed upright for the EXIF orientation (1-8).
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // orientations 5-8 swap the axes
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from the EXIF segment of a JPEG, 1 when absent.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) { // start of scan, no more metadata
+			return 1
+		}
+
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for e := 0; e < entries; e++ {
+		entry := offset + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 1
+}
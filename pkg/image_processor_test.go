@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	variants, err := ProcessImage(encodePNG(t, 400, 200), []ImageVariantSpec{
+		{Name: "square", Width: 100, Height: 100},
+		{Name: "wide", Width: 200},
+		{Name: "large", Width: 1000, Height: 1000},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, variants, 3)
+
+	assert.Equal(t, []int{100, 100}, []int{variants[0].Width, variants[0].Height})
+	assert.Equal(t, []int{200, 100}, []int{variants[1].Width, variants[1].Height})
+	assert.Equal(t, []int{200, 200}, []int{variants[2].Width, variants[2].Height}) // cropped, not upscaled
+
+	decoded, err := jpeg.Decode(bytes.NewReader(variants[0].Data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 100), decoded.Bounds())
+}
+
+func TestProcessImageInvalid(t *testing.T) {
+	_, err := ProcessImage([]byte("not an image"), []ImageVariantSpec{{Name: "small", Width: 10}})
+	assert.ErrorIs(t, err, ErrImageDecode)
+}
+
+func TestProcessImageTooLarge(t *testing.T) {
+	// a valid PNG whose header claims 100000×100000 pixels
+	data := encodePNG(t, 1, 1)
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := ProcessImage(data, []ImageVariantSpec{{Name: "small", Width: 10}})
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	// SOI, APP1 with a big endian TIFF header holding a single orientation entry of 6
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, 6, 0, 0, 0, 0}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0, byte(len(segment) + 2)}, segment...)
+
+	assert.Equal(t, 6, jpegOrientation(data))
+	assert.Equal(t, 1, jpegOrientation([]byte{0xFF, 0xD8}))
+
+	rotated := orient(image.NewRGBA(image.Rect(0, 0, 4, 2)), 6)
+	assert.Equal(t, image.Rect(0, 0, 2, 4), rotated.Bounds())
+}
@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MimePDF is the content type of the files written by WritePDF.
+const MimePDF = "application/pdf"
+
+// PDFPage is a single page drawn with the standard Helvetica fonts, coordinates are points from the bottom left.
+type PDFPage struct {
+	Width  float64
+	Height float64
+	Title  string
+	Rects  []PDFRect
+	Texts  []PDFText
+}
+
+// PDFRect is the outline of a rectangle.
+type PDFRect struct {
+	X, Y, Width, Height float64
+	LineWidth           float64
+	Color               [3]float64 // rgb from 0 to 1
+}
+
+// PDFText is a single line of text, X is its center when Center is set. Text wider than MaxWidth is shrunk to fit.
+type PDFText struct {
+	X, Y     float64
+	Size     float64
+	Bold     bool
+	Center   bool
+	MaxWidth float64
+	Color    [3]float64
+	Text     string
+}
+
+// WritePDF writes page as a one page PDF document. Characters outside of latin-1 are replaced by a question mark
+// since the standard fonts carry no other glyphs.
+func WritePDF(w io.Writer, page PDFPage) error {
+	var content strings.Builder
+
+	for _, r := range page.Rects {
+		fmt.Fprintf(&content, "%s RG %s w %s %s %s %s re S\n",
+			pdfColor(r.Color), pdfNumber(r.LineWidth), pdfNumber(r.X), pdfNumber(r.Y), pdfNumber(r.Width), pdfNumber(r.Height))
+	}
+
+	for _, t := range page.Texts {
+		var (
+			text  = pdfLatin1(t.Text)
+			font  = "F1"
+			size  = t.Size
+			width = pdfTextWidth(text, t.Bold, size)
+		)
+
+		if t.Bold {
+			font = "F2"
+		}
+		if t.MaxWidth > 0 && width > t.MaxWidth {
+			size *= t.MaxWidth / width
+			width = t.MaxWidth
+		}
+
+		x := t.X
+		if t.Center {
+			x -= width / 2
+		}
+
+		fmt.Fprintf(&content, "BT %s rg /%s %s Tf %s %s Td (%s) Tj ET\n",
+			pdfColor(t.Color), font, pdfNumber(size), pdfNumber(x), pdfNumber(t.Y), pdfEscape(text))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>",
+			pdfNumber(page.Width), pdfNumber(page.Height)),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /CreationDate (D:%s) >>", pdfEscape(pdfLatin1(page.Title)), time.Now().UTC().Format("20060102150405Z")),
+	}
+
+	var (
+		buf     bytes.Buffer
+		offsets = make([]int, len(objects))
+	)
+
+	// the binary comment tells transfer tools the file is not plain text
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// pdfLatin1 maps text to the bytes of WinAnsiEncoding, which matches latin-1 for the printable characters.
+func pdfLatin1(text string) []byte {
+	result := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+			result = append(result, byte(r))
+		case r == '\t' || r == '\n' || r == '\r':
+			result = append(result, ' ')
+		default:
+			result = append(result, '?')
+		}
+	}
+
+	return result
+}
+
+// pdfEscape escapes a literal string, bytes outside of ascii are written as octal escapes.
+func pdfEscape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func pdfNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func pdfColor(c [3]float64) string {
+	return pdfNumber(c[0]) + " " + pdfNumber(c[1]) + " " + pdfNumber(c[2])
+}
+
+// pdfTextWidth measures text in points with the metrics of the standard fonts, latin-1 letters use the average width.
+func pdfTextWidth(text []byte, bold bool, size float64) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, c := range text {
+		if c >= 0x20 && c <= 0x7e {
+			total += widths[c-0x20]
+		} else {
+			total += 556
+		}
+	}
+
+	return float64(total) * size / 1000
+}
+
+// widths of the characters from space to tilde in thousandths of the font size
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
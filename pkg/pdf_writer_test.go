@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WritePDF(&buf, PDFPage{
+		Width:  842,
+		Height: 595,
+		Title:  "Certificate",
+		Rects:  []PDFRect{{X: 20, Y: 20, Width: 802, Height: 555, LineWidth: 2}},
+		Texts: []PDFText{
+			{X: 421, Y: 300, Size: 32, Bold: true, Center: true, Text: "Budi (Ani) \\ Sánchez"},
+			{X: 421, Y: 250, Size: 18, Center: true, Text: "日本"},
+		},
+	})
+	require.NoError(t, err)
+
+	pdf := buf.String()
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.4\n")))
+	assert.Contains(t, pdf, `(Budi \(Ani\) \\ S\341nchez) Tj`)
+	assert.Contains(t, pdf, `(??) Tj`)
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("%%EOF\n")))
+
+	// every xref entry points at its object and startxref at the table
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.Len(t, start, 2)
+	xref, _ := strconv.Atoi(start[1])
+	assert.True(t, bytes.HasPrefix(buf.Bytes()[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1)
+	require.Len(t, entries, 7)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, bytes.HasPrefix(buf.Bytes()[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestWritePDFShrinksWideText(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WritePDF(&buf, PDFPage{
+		Width:  200,
+		Height: 100,
+		Texts:  []PDFText{{X: 100, Y: 50, Size: 20, Center: true, MaxWidth: 100, Text: "A class title that is far too long"}},
+	})
+	require.NoError(t, err)
+
+	font := regexp.MustCompile(`/F1 ([\d.]+) Tf ([\d.]+) `).FindStringSubmatch(buf.String())
+	require.Len(t, font, 3)
+
+	size, _ := strconv.ParseFloat(font[1], 64)
+	x, _ := strconv.ParseFloat(font[2], 64)
+	assert.Less(t, size, 20.0)
+	assert.InDelta(t, 50, x, 0.001)
+}
@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"sort"
+)
+
+// AssignPeerReviews picks n reviewers for every author, never the author itself.
+// The result only depends on the inputs and the seed: reviewers are shuffled by a hash of seed and id,
+// and every review goes to the least loaded candidate, which keeps the review counts of all reviewers even.
+func AssignPeerReviews(authors, reviewers []string, n int, seed string) map[string][]string {
+	var (
+		result = make(map[string][]string, len(authors))
+		order  = hashOrder(reviewers, seed)
+		index  = make(map[string]int, len(order))
+		load   = make(map[string]int, len(order))
+	)
+
+	for i, id := range order {
+		index[id] = i
+	}
+
+	for _, author := range hashOrder(authors, seed) {
+		// scan the ring from the author's own position so neighbours differ between authors
+		start := 0
+		if i, ok := index[author]; ok {
+			start = i + 1
+		}
+
+		candidates := make([]string, 0, len(order))
+		for k := 0; k < len(order); k++ {
+			id := order[(start+k)%len(order)]
+			if id != author {
+				candidates = append(candidates, id)
+			}
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool { return load[candidates[i]] < load[candidates[j]] })
+
+		picked := candidates[:min(n, len(candidates))]
+		for _, id := range picked {
+			load[id]++
+		}
+
+		result[author] = append([]string{}, picked...)
+	}
+
+	return result
+}
+
+// hashOrder returns the unique ids ordered by the hash of seed and id.
+func hashOrder(ids []string, seed string) []string {
+	var (
+		seen   = make(map[string]bool, len(ids))
+		hashes = make(map[string]string, len(ids))
+		unique = make([]string, 0, len(ids))
+	)
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		sum := sha256.Sum256([]byte(seed + "\x00" + id))
+		hashes[id] = string(sum[:])
+		unique = append(unique, id)
+	}
+
+	sort.Slice(unique, func(i, j int) bool {
+		if hashes[unique[i]] != hashes[unique[j]] {
+			return hashes[unique[i]] < hashes[unique[j]]
+		}
+		return unique[i] < unique[j]
+	})
+
+	return unique
+}
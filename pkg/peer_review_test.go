@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignPeerReviews(t *testing.T) {
+	var (
+		authors   = []string{"a", "b", "c", "d", "e"}
+		reviewers = []string{"a", "b", "c", "d", "e", "f", "g"}
+	)
+
+	result := AssignPeerReviews(authors, reviewers, 3, "42")
+
+	load := map[string]int{}
+	for _, author := range authors {
+		assert.Len(t, result[author], 3)
+		assert.NotContains(t, result[author], author)
+
+		seen := map[string]bool{}
+		for _, reviewer := range result[author] {
+			assert.False(t, seen[reviewer], "reviewer %s picked twice for %s", reviewer, author)
+			seen[reviewer] = true
+			load[reviewer]++
+		}
+	}
+
+	minLoad, maxLoad := len(authors), 0
+	for _, reviewer := range reviewers {
+		minLoad = min(minLoad, load[reviewer])
+		maxLoad = max(maxLoad, load[reviewer])
+	}
+	assert.LessOrEqual(t, maxLoad-minLoad, 1)
+
+	assert.Equal(t, result, AssignPeerReviews([]string{"e", "d", "c", "b", "a"}, []string{"g", "f", "e", "d", "c", "b", "a"}, 3, "42"))
+}
+
+func TestAssignPeerReviewsSmallClass(t *testing.T) {
+	result := AssignPeerReviews([]string{"a", "b"}, []string{"a", "b"}, 3, "1")
+
+	assert.Equal(t, []string{"b"}, result["a"])
+	assert.Equal(t, []string{"a"}, result["b"])
+}
+
+func TestAssignPeerReviewsBalanced(t *testing.T) {
+	var students []string
+	for i := 0; i < 30; i++ {
+		students = append(students, fmt.Sprintf("student-%d", i))
+	}
+
+	result := AssignPeerReviews(students, students, 4, "assignment-7")
+
+	load := map[string]int{}
+	for _, reviewers := range result {
+		for _, reviewer := range reviewers {
+			load[reviewer]++
+		}
+	}
+
+	for _, student := range students {
+		assert.Equal(t, 4, load[student])
+	}
+}
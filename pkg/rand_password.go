@@ -46,6 +46,21 @@ func GeneratePassword(length int) string {
 	return result
 }
 
+// GenerateCode returns a random code of uppercase letters and digits in groups of four joined by dashes.
+// Characters that are easily confused when typed, 0 O 1 I, are left out.
+func GenerateCode(length int) string {
+	const codeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	result := make([]byte, 0, length+length/4)
+	for i := 0; i < length; i++ {
+		if i > 0 && i%4 == 0 {
+			result = append(result, '-')
+		}
+		result = append(result, codeChars[randInt(0, len(codeChars))])
+	}
+	return string(result)
+}
+
 // randInt returns a random integer in the range [min, max)
 func randInt(min, max int) int {
 	if min == max {
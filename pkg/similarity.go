@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// SimilarityKGram is the length of the hashed k-grams in normalized characters, shorter matches are ignored.
+	SimilarityKGram = 30
+	// SimilarityWindow is the winnowing window, every match of at least KGram+Window-1 characters is found.
+	SimilarityWindow = 20
+
+	similarityBase = 1000003
+)
+
+// Fingerprint is a selected k-gram hash, Start and End are byte offsets of the k-gram in the original text.
+type Fingerprint struct {
+	Hash  int64 `json:"hash"`
+	Start int   `json:"start"`
+	End   int   `json:"end"`
+}
+
+// SimilaritySpan is a region of a that matches a region of b, as byte offsets in both texts.
+type SimilaritySpan struct {
+	AStart int `json:"a_start"`
+	AEnd   int `json:"a_end"`
+	BStart int `json:"b_start"`
+	BEnd   int `json:"b_end"`
+}
+
+// Winnow fingerprints text with the winnowing algorithm. Letters and digits are compared case insensitive,
+// whitespace and punctuation are skipped so reformatting a copied text does not hide it.
+func Winnow(text string) []Fingerprint {
+	var (
+		chars   = make([]rune, 0, len(text))
+		offsets = make([]int, 0, len(text)+1) // byte offset of every kept character in text
+	)
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			chars = append(chars, unicode.ToLower(r))
+			offsets = append(offsets, i)
+		}
+	}
+
+	if len(chars) < SimilarityKGram {
+		return []Fingerprint{}
+	}
+
+	// rolling hash of every k-gram, pow removes the character leaving the k-gram
+	var (
+		hashes = make([]uint64, len(chars)-SimilarityKGram+1)
+		pow    = uint64(1)
+		hash   uint64
+	)
+
+	for i := 0; i < SimilarityKGram-1; i++ {
+		pow *= similarityBase
+	}
+
+	for i, r := range chars {
+		if i >= SimilarityKGram {
+			hash -= uint64(chars[i-SimilarityKGram]) * pow
+		}
+		hash = hash*similarityBase + uint64(r)
+		if i >= SimilarityKGram-1 {
+			hashes[i-SimilarityKGram+1] = hash
+		}
+	}
+
+	kgram := func(i int) Fingerprint {
+		last := offsets[i+SimilarityKGram-1]
+		_, size := utf8.DecodeRuneInString(text[last:])
+		return Fingerprint{Hash: int64(hashes[i]), Start: offsets[i], End: last + size}
+	}
+
+	window := min(SimilarityWindow, len(hashes))
+	result := make([]Fingerprint, 0, 2*len(hashes)/(window+1)+1)
+	selected := -1
+
+	// the minimum of every window is kept, the rightmost one on ties, and recorded once
+	for start := 0; start+window <= len(hashes); start++ {
+		pick := start
+		for i := start + 1; i < start+window; i++ {
+			if hashes[i] <= hashes[pick] {
+				pick = i
+			}
+		}
+
+		if pick != selected {
+			selected = pick
+			result = append(result, kgram(pick))
+		}
+	}
+
+	return result
+}
+
+// MatchSpans merges the fingerprints a and b have in common into matching regions of both texts.
+func MatchSpans(a, b []Fingerprint) []SimilaritySpan {
+	byHash := make(map[int64][]Fingerprint, len(b))
+	for _, fp := range b {
+		byHash[fp.Hash] = append(byHash[fp.Hash], fp)
+	}
+
+	shared := make([]Fingerprint, 0)
+	for _, fp := range a {
+		if _, ok := byHash[fp.Hash]; ok {
+			shared = append(shared, fp)
+		}
+	}
+
+	sort.SliceStable(shared, func(i, j int) bool { return shared[i].Start < shared[j].Start })
+
+	spans := make([]SimilaritySpan, 0)
+	for _, fp := range shared {
+		matches := byHash[fp.Hash]
+
+		// consecutive fingerprints of a copied region overlap in both texts, extend the current span then
+		if n := len(spans); n > 0 && fp.Start <= spans[n-1].AEnd {
+			current := &spans[n-1]
+			extended := false
+			for _, match := range matches {
+				if match.Start >= current.BStart && match.Start <= current.BEnd {
+					current.AEnd = max(current.AEnd, fp.End)
+					current.BEnd = max(current.BEnd, match.End)
+					extended = true
+					break
+				}
+			}
+			if extended {
+				continue
+			}
+		}
+
+		spans = append(spans, SimilaritySpan{AStart: fp.Start, AEnd: fp.End, BStart: matches[0].Start, BEnd: matches[0].End})
+	}
+
+	return spans
+}
+
+// DistinctHashes counts the different hashes among fingerprints.
+func DistinctHashes(fingerprints []Fingerprint) int {
+	seen := make(map[int64]struct{}, len(fingerprints))
+	for _, fp := range fingerprints {
+		seen[fp.Hash] = struct{}{}
+	}
+
+	return len(seen)
+}
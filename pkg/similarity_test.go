@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const similarityCopied = "The mitochondria is the powerhouse of the cell and produces most of its chemical energy through respiration."
+
+func TestWinnowIgnoresFormatting(t *testing.T) {
+	a := Winnow(similarityCopied)
+	b := Winnow(strings.ToUpper(strings.ReplaceAll(similarityCopied, " ", "\n  ")))
+
+	assert.NotEmpty(t, a)
+	assert.Equal(t, hashes(a), hashes(b))
+}
+
+func TestWinnowShortText(t *testing.T) {
+	assert.Empty(t, Winnow("too short"))
+	assert.Empty(t, Winnow(""))
+}
+
+func TestWinnowOffsets(t *testing.T) {
+	text := "Ünïcode — " + similarityCopied
+
+	for _, fp := range Winnow(text) {
+		assert.Less(t, fp.Start, fp.End)
+		assert.LessOrEqual(t, fp.End, len(text))
+		assert.True(t, utf8.ValidString(text[fp.Start:fp.End]))
+	}
+}
+
+func TestMatchSpans(t *testing.T) {
+	var (
+		a = "My own introduction about cells. " + similarityCopied + " My own conclusion."
+		b = "A different opening written by someone else entirely! " + similarityCopied
+	)
+
+	spans := MatchSpans(Winnow(a), Winnow(b))
+
+	assert.Len(t, spans, 1)
+	assert.Contains(t, similarityCopied, a[spans[0].AStart:spans[0].AEnd])
+	assert.Contains(t, similarityCopied, b[spans[0].BStart:spans[0].BEnd])
+	assert.Greater(t, spans[0].AEnd-spans[0].AStart, len(similarityCopied)/2)
+}
+
+func TestMatchSpansUnrelated(t *testing.T) {
+	var (
+		a = Winnow("Photosynthesis converts light energy into chemical energy stored in glucose molecules.")
+		b = Winnow("The French revolution began in 1789 and reshaped the political landscape of Europe.")
+	)
+
+	assert.Empty(t, MatchSpans(a, b))
+	assert.Equal(t, len(hashes(a)), DistinctHashes(a))
+}
+
+func hashes(fingerprints []Fingerprint) []int64 {
+	result := make([]int64, len(fingerprints))
+	for i, fp := range fingerprints {
+		result[i] = fp.Hash
+	}
+	return result
+}
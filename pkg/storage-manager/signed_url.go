@@ -7,25 +7,23 @@ import (
 	"fmt"
 	"hacko-app/internal/infrastructure/config"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 )
 
 func GenerateSignedURL(filename string, expiration time.Duration) string {
-	urlToSigned := config.Envs.App.BaseURL + "/api/storage/private/" + filename
 	var (
-		key            = []byte(config.Envs.Guard.JwtPrivateKey)
 		expirationTime = time.Now().UTC().Add(expiration).Unix()
-		data           = fmt.Sprintf("%s%d", urlToSigned, expirationTime)
+		signature      = sign(filename, expirationTime)
 	)
 
-	// Create a new HMAC by defining the hash type and the key (as byte array)
-	h := hmac.New(sha256.New, key)
-	h.Write([]byte(data))
-	signature := hex.EncodeToString(h.Sum(nil))
+	// the path is escaped when the URL is built, the signature is made over the unescaped filename
+	u, _ := url.Parse(config.Envs.App.BaseURL)
+	u.Path = path.Join(u.Path, "/api/storage/private", filename)
+	u.RawPath = ""
 
 	// Add the expiration time and signature to the URL
-	u, _ := url.Parse(urlToSigned)
 	q := u.Query()
 	q.Set("expires", strconv.FormatInt(expirationTime, 10))
 	q.Set("signature", signature)
@@ -33,3 +31,23 @@ func GenerateSignedURL(filename string, expiration time.Duration) string {
 
 	return u.String()
 }
+
+// VerifySignedURL reports whether signature was made by GenerateSignedURL for the unescaped filename and expiry.
+func VerifySignedURL(filename string, expires int64, signature string) bool {
+	return hmac.Equal([]byte(sign(filename, expires)), []byte(signature))
+}
+
+// sign makes the signature of a private file. It covers the filename and not the whole URL, so neither the way
+// the path is escaped nor the host the file is served from can make the signer and the verifier disagree.
+func sign(filename string, expires int64) string {
+	var (
+		key  = []byte(config.Envs.Guard.JwtPrivateKey)
+		data = fmt.Sprintf("%s\n%d", filename, expires)
+	)
+
+	// Create a new HMAC by defining the hash type and the key (as byte array)
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,128 @@
+package pkg
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines returns a line based diff that turns a into b.
+func DiffLines(a, b string) []DiffLine {
+	var (
+		from = splitLines(a)
+		to   = splitLines(b)
+	)
+
+	// skip the common prefix and suffix so the LCS table only covers the changed part
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	var (
+		midFrom = from[prefix : len(from)-suffix]
+		midTo   = to[prefix : len(to)-suffix]
+		result  = make([]DiffLine, 0, len(from)+len(to))
+	)
+
+	for _, line := range from[:prefix] {
+		result = append(result, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of midFrom[i:] and midTo[j:]
+	lcs := make([][]int, len(midFrom)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midTo)+1)
+	}
+	for i := len(midFrom) - 1; i >= 0; i-- {
+		for j := len(midTo) - 1; j >= 0; j-- {
+			if midFrom[i] == midTo[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(midFrom) && j < len(midTo) {
+		switch {
+		case midFrom[i] == midTo[j]:
+			result = append(result, DiffLine{Op: DiffEqual, Text: midFrom[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, DiffLine{Op: DiffDelete, Text: midFrom[i]})
+			i++
+		default:
+			result = append(result, DiffLine{Op: DiffInsert, Text: midTo[j]})
+			j++
+		}
+	}
+	for ; i < len(midFrom); i++ {
+		result = append(result, DiffLine{Op: DiffDelete, Text: midFrom[i]})
+	}
+	for ; j < len(midTo); j++ {
+		result = append(result, DiffLine{Op: DiffInsert, Text: midTo[j]})
+	}
+
+	for _, line := range from[len(from)-suffix:] {
+		result = append(result, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	return result
+}
+
+// DiffSets returns the values that were added to and removed from a to get b, ignoring order.
+func DiffSets(a, b []string) (added, removed []string) {
+	var (
+		inA = make(map[string]bool, len(a))
+		inB = make(map[string]bool, len(b))
+	)
+
+	for _, v := range a {
+		inA[v] = true
+	}
+	for _, v := range b {
+		inB[v] = true
+	}
+
+	added = []string{}
+	removed = []string{}
+
+	for _, v := range b {
+		if !inA[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range a {
+		if !inB[v] {
+			removed = append(removed, v)
+		}
+	}
+
+	return added, removed
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(s, "\n")
+}
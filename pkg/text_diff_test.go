@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("a\nb\nc\nd", "a\nc\nx\nd")
+
+	assert.Equal(t, []DiffLine{
+		{Op: DiffEqual, Text: "a"},
+		{Op: DiffDelete, Text: "b"},
+		{Op: DiffEqual, Text: "c"},
+		{Op: DiffInsert, Text: "x"},
+		{Op: DiffEqual, Text: "d"},
+	}, diff)
+}
+
+func TestDiffLinesEmpty(t *testing.T) {
+	assert.Equal(t, []DiffLine{{Op: DiffInsert, Text: "new"}}, DiffLines("", "new"))
+	assert.Equal(t, []DiffLine{{Op: DiffDelete, Text: "old"}}, DiffLines("old", ""))
+	assert.Empty(t, DiffLines("", ""))
+}
+
+func TestDiffSets(t *testing.T) {
+	added, removed := DiffSets([]string{"a.pdf", "b.pdf"}, []string{"b.pdf", "c.pdf"})
+
+	assert.Equal(t, []string{"c.pdf"}, added)
+	assert.Equal(t, []string{"a.pdf"}, removed)
+}
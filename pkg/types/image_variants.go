@@ -0,0 +1,56 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type ImageVariant struct {
+	Key    string `json:"key"`
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int64  `json:"size"`
+}
+
+// ImageVariants maps a variant name (e.g. "small") to the stored image, it is kept in a JSONB column.
+type ImageVariants map[string]ImageVariant
+
+// Scan implements the sql.Scanner interface.
+func (v *ImageVariants) Scan(val interface{}) error {
+	var data []byte
+
+	switch val := val.(type) {
+	case nil:
+		*v = ImageVariants{}
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("unsupported type %T for image variants", val)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Value implements the driver.Valuer interface.
+func (v ImageVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(v)
+}
+
+// Keys returns the storage keys of every variant.
+func (v ImageVariants) Keys() []string {
+	keys := make([]string, 0, len(v))
+	for _, variant := range v {
+		keys = append(keys, variant.Key)
+	}
+
+	return keys
+}
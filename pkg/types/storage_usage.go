@@ -0,0 +1,20 @@
+package types
+
+type StorageUsage struct {
+	UsedBytes  int64 `json:"used_bytes" db:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes" db:"quota_bytes"`
+}
+
+// Allows reports whether size more bytes still fit in the quota, a quota of 0 or less is unlimited.
+func (u *StorageUsage) Allows(size int64) bool {
+	return u.QuotaBytes <= 0 || u.UsedBytes+size <= u.QuotaBytes
+}
+
+// Left is the number of bytes that still fit in the quota, -1 when it is unlimited.
+func (u *StorageUsage) Left() int64 {
+	if u.QuotaBytes <= 0 {
+		return -1
+	}
+
+	return max(u.QuotaBytes-u.UsedBytes, 0)
+}
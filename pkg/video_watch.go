@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// VideoMaxSpeed is the fastest playback rate whose progress still counts as watched.
+	VideoMaxSpeed = 2
+	// VideoHeartbeatGrace absorbs the network delay between two heartbeats.
+	VideoHeartbeatGrace = 5 * time.Second
+	// VideoMaxSegment is the longest stretch a single heartbeat can mark as watched.
+	VideoMaxSegment = 60 * time.Second
+)
+
+// WatchedSeconds is a bitmap of the seconds of a video that were watched, bit i is second i.
+type WatchedSeconds []byte
+
+// Mark records the seconds from up to but not including to as watched, growing the bitmap when needed.
+func (w WatchedSeconds) Mark(from, to int) WatchedSeconds {
+	from = max(from, 0)
+	if to <= from {
+		return w
+	}
+
+	if size := (to + 7) / 8; size > len(w) {
+		w = append(w, make([]byte, size-len(w))...)
+	}
+
+	for i := from; i < to; i++ {
+		w[i/8] |= 1 << (i % 8)
+	}
+
+	return w
+}
+
+// Count returns how many seconds were watched, only the first limit seconds count so a video that got shorter
+// never reports more than its length.
+func (w WatchedSeconds) Count(limit int) int {
+	count := 0
+	for i, b := range w {
+		if rest := limit - i*8; rest < 8 {
+			if rest > 0 {
+				count += bits.OnesCount8(b & (1<<rest - 1))
+			}
+			break
+		}
+		count += bits.OnesCount8(b)
+	}
+
+	return count
+}
+
+// HeartbeatSegment returns the seconds played between two heartbeats. Playback must have moved forward no faster
+// than VideoMaxSpeed allows in the elapsed time, a seek or a skipped heartbeat marks nothing.
+func HeartbeatSegment(previous, position float64, elapsed time.Duration) (from, to int, ok bool) {
+	played := position - previous
+	if played <= 0 {
+		return 0, 0, false
+	}
+
+	allowed := min(elapsed*VideoMaxSpeed+VideoHeartbeatGrace, VideoMaxSegment)
+	if played > allowed.Seconds() {
+		return 0, 0, false
+	}
+
+	return int(math.Floor(previous)), int(math.Floor(position)), true
+}
+
+// VideoWatch is the progress of a user on a video between two heartbeats.
+type VideoWatch struct {
+	Position float64
+	Duration float64
+	Watched  WatchedSeconds
+}
+
+// Heartbeat moves the progress to the position reported after elapsed, elapsed is nil for the first heartbeat. The
+// duration is kept from the first heartbeat and only grows, a client reporting a shorter video cannot make the
+// seconds it watched count for more.
+func (v VideoWatch) Heartbeat(position, duration float64, elapsed *time.Duration) VideoWatch {
+	next := VideoWatch{Position: position, Duration: max(v.Duration, duration), Watched: v.Watched}
+
+	if elapsed != nil {
+		if from, to, ok := HeartbeatSegment(v.Position, position, *elapsed); ok {
+			next.Watched = next.Watched.Mark(from, min(to, next.Length()))
+		}
+	}
+
+	return next
+}
+
+// Length returns the duration in whole seconds.
+func (v VideoWatch) Length() int {
+	return int(math.Ceil(v.Duration))
+}
+
+// Seconds returns how many seconds of the video were watched.
+func (v VideoWatch) Seconds() int {
+	return v.Watched.Count(v.Length())
+}
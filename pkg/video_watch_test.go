@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchedSecondsMark(t *testing.T) {
+	var w WatchedSeconds
+
+	w = w.Mark(0, 10)
+	w = w.Mark(5, 20) // overlapping seconds are counted once
+
+	assert.Equal(t, 20, w.Count(100))
+	assert.Len(t, w, 3)
+
+	w = w.Mark(30, 30)
+	assert.Equal(t, 20, w.Count(100))
+}
+
+func TestWatchedSecondsCountLimit(t *testing.T) {
+	w := WatchedSeconds(nil).Mark(0, 24)
+
+	assert.Equal(t, 24, w.Count(24))
+	assert.Equal(t, 13, w.Count(13))
+	assert.Equal(t, 0, w.Count(0))
+}
+
+func TestHeartbeatSegment(t *testing.T) {
+	from, to, ok := HeartbeatSegment(10.4, 25.9, 15*time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 10, from)
+	assert.Equal(t, 25, to)
+
+	// double speed still counts
+	_, _, ok = HeartbeatSegment(0, 30, 15*time.Second)
+	assert.True(t, ok)
+}
+
+func TestHeartbeatSegmentRejected(t *testing.T) {
+	cases := []struct {
+		name               string
+		previous, position float64
+		elapsed            time.Duration
+	}{
+		{"seek forward", 10, 300, 15 * time.Second},
+		{"seek backward", 100, 20, 15 * time.Second},
+		{"paused", 42, 42, 15 * time.Second},
+		{"longer than a segment", 0, 90, 10 * time.Minute},
+	}
+
+	for _, c := range cases {
+		_, _, ok := HeartbeatSegment(c.previous, c.position, c.elapsed)
+		assert.False(t, ok, c.name)
+	}
+}
+
+func TestVideoWatchHeartbeat(t *testing.T) {
+	elapsed := 15 * time.Second
+
+	v := VideoWatch{}.Heartbeat(0, 100, nil)
+	assert.Equal(t, 100.0, v.Duration)
+	assert.Equal(t, 0, v.Seconds())
+
+	v = v.Heartbeat(15, 100, &elapsed)
+	assert.Equal(t, 15, v.Seconds())
+	assert.Equal(t, 15.0, v.Position)
+}
+
+func TestVideoWatchHeartbeatKeepsDuration(t *testing.T) {
+	elapsed := 15 * time.Second
+
+	v := VideoWatch{}.Heartbeat(0, 600, nil)
+	v = v.Heartbeat(15, 600, &elapsed)
+
+	// a shorter duration is ignored, the watched seconds still count against the stored one
+	v = v.Heartbeat(20, 20, &elapsed)
+	assert.Equal(t, 600.0, v.Duration)
+	assert.Equal(t, 20, v.Seconds())
+
+	// a longer one replaces it
+	v = v.Heartbeat(30, 900.5, &elapsed)
+	assert.Equal(t, 901, v.Length())
+	assert.Equal(t, 30, v.Seconds())
+}
@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		grade = 87.5
+	)
+
+	err := WriteXLSX(&buf, "Grades: 2024/1", [][]any{
+		{"Name", "Grade"},
+		{"Budi & Ani", &grade},
+		{"Citra", nil},
+	})
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Grades 20241"`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="A2" t="inlineStr"><is><t xml:space="preserve">Budi &amp; Ani</t></is></c>`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="B2"><v>87.5</v></c>`)
+	assert.NotContains(t, files["xl/worksheets/sheet1.xml"], `r="B3"`)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", ColumnName(0))
+	assert.Equal(t, "Z", ColumnName(25))
+	assert.Equal(t, "AA", ColumnName(26))
+	assert.Equal(t, "AZ", ColumnName(51))
+	assert.Equal(t, "BA", ColumnName(52))
+}